@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"icp-aws-cli/cmd/icp-aws-cli/audit/commands"
+
+	"github.com/spf13/cobra"
+)
+
+func InitCommands() *cobra.Command {
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Commands to inspect the local audit log",
+		Long:  "Allows searching the local log of the mutating operations performed with this CLI.",
+	}
+
+	// Initialize subcommands
+	commands.InitSearchCommand(auditCmd)
+
+	return auditCmd
+}
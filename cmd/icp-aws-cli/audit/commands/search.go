@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/output"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// searchOptions are the criteria an audit entry must match; empty criteria are ignored.
+type searchOptions struct {
+	since    string
+	identity string
+	resource string
+	command  string
+	failed   bool
+	outcomes bool
+}
+
+func InitSearchCommand(auditCmd *cobra.Command) {
+	var opts searchOptions
+
+	var searchCmd = &cobra.Command{
+		Use:   "search",
+		Short: "Searches the audit log",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return searchEntries(opts)
+		},
+	}
+
+	searchCmd.Flags().StringVarP(&opts.since, "since", "s", "", "Only entries newer than a duration (e.g. 24h) or a date (YYYY-MM-DD)")
+	searchCmd.Flags().StringVarP(&opts.identity, "identity", "u", "", "Only entries whose caller identity contains this text")
+	searchCmd.Flags().StringVarP(&opts.resource, "resource", "r", "", "Only entries with a target containing this text")
+	searchCmd.Flags().StringVarP(&opts.command, "command", "c", "", "Only entries whose command line contains this text")
+	searchCmd.Flags().BoolVarP(&opts.failed, "failed", "f", false, "Only entries with at least one failed operation")
+	searchCmd.Flags().BoolVar(&opts.outcomes, "outcomes", false, "Show one row per resource with its outcome")
+
+	auditCmd.AddCommand(searchCmd)
+}
+
+func searchEntries(opts searchOptions) error {
+	since, err := parseSince(opts.since)
+	if err != nil {
+		return err
+	}
+
+	entries, err := audit.Read()
+	if err != nil {
+		return err
+	}
+
+	records := output.NewResult("Timestamp", "Identity", "Region", "Command", "Targets", "Succeeded", "Failed")
+	if opts.outcomes {
+		records = output.NewResult("Timestamp", "Identity", "Resource", "Status", "Error")
+	}
+
+	for _, entry := range entries {
+		if !matches(entry, opts, since) {
+			continue
+		}
+		if opts.outcomes {
+			for _, outcome := range entry.Outcomes {
+				records.Add(entry.Timestamp.Local(), entry.Identity, outcome.Resource, outcome.Status, outcome.Error)
+			}
+			continue
+		}
+		records.Add(entry.Timestamp.Local(), entry.Identity, entry.Region, entry.Command, entry.Targets,
+			countOutcomes(entry, audit.StatusSucceeded), countOutcomes(entry, audit.StatusFailed))
+	}
+
+	return output.Print(records)
+}
+
+func matches(entry audit.Entry, opts searchOptions, since time.Time) bool {
+	if entry.Timestamp.Before(since) {
+		return false
+	}
+	if opts.identity != "" && !strings.Contains(entry.Identity, opts.identity) {
+		return false
+	}
+	if opts.command != "" && !strings.Contains(entry.Command, opts.command) {
+		return false
+	}
+	if opts.failed && countOutcomes(entry, audit.StatusFailed) == 0 {
+		return false
+	}
+	if opts.resource != "" {
+		for _, target := range entry.Targets {
+			if strings.Contains(target, opts.resource) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func countOutcomes(entry audit.Entry, status string) int {
+	count := 0
+	for _, outcome := range entry.Outcomes {
+		if outcome.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// parseSince accepts a duration relative to now or a date; an empty value matches every entry.
+func parseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since value %q: expected a duration (e.g. 24h) or a date (YYYY-MM-DD)", value)
+	}
+	return date, nil
+}
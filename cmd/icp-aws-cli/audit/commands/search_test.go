@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"bytes"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatches(t *testing.T) {
+	now := time.Now()
+	entry := audit.Entry{
+		Timestamp: now.Add(-2 * time.Hour),
+		Identity:  "arn:aws:sts::123456789012:assumed-role/admin/alice",
+		Command:   "icp-aws-cli ec2 terminate --pattern --yes",
+		Targets:   []string{"ec2 instance i-1", "ec2 instance i-2"},
+		Outcomes: []audit.Outcome{
+			{Resource: "ec2 instance i-1", Status: audit.StatusSucceeded},
+			{Resource: "ec2 instance i-2", Status: audit.StatusFailed, Error: "UnauthorizedOperation"},
+		},
+	}
+	succeeded := entry
+	succeeded.Outcomes = entry.Outcomes[:1]
+
+	tests := []struct {
+		name  string
+		entry audit.Entry
+		opts  searchOptions
+		since time.Time
+		want  bool
+	}{
+		{name: "no criteria", entry: entry, want: true},
+		{name: "since before", entry: entry, since: now.Add(-3 * time.Hour), want: true},
+		{name: "since after", entry: entry, since: now.Add(-time.Hour), want: false},
+		{name: "identity", entry: entry, opts: searchOptions{identity: "admin/alice"}, want: true},
+		{name: "other identity", entry: entry, opts: searchOptions{identity: "bob"}, want: false},
+		{name: "command", entry: entry, opts: searchOptions{command: "terminate"}, want: true},
+		{name: "other command", entry: entry, opts: searchOptions{command: "s3 delete"}, want: false},
+		{name: "resource", entry: entry, opts: searchOptions{resource: "i-2"}, want: true},
+		{name: "other resource", entry: entry, opts: searchOptions{resource: "i-3"}, want: false},
+		{name: "failed", entry: entry, opts: searchOptions{failed: true}, want: true},
+		{name: "failed without failures", entry: succeeded, opts: searchOptions{failed: true}, want: false},
+		{name: "every criterion", entry: entry, opts: searchOptions{identity: "alice", command: "ec2", resource: "i-1", failed: true}, since: now.Add(-3 * time.Hour), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matches(tt.entry, tt.opts, tt.since); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	if got, err := parseSince(""); err != nil || !got.IsZero() {
+		t.Errorf("parseSince(\"\") = %v, %v, want the zero time", got, err)
+	}
+	if got, err := parseSince("24h"); err != nil || time.Since(got) < 24*time.Hour || time.Since(got) > 25*time.Hour {
+		t.Errorf("parseSince(24h) = %v, %v, want a day ago", got, err)
+	}
+	want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	if got, err := parseSince("2026-03-01"); err != nil || !got.Equal(want) {
+		t.Errorf("parseSince(2026-03-01) = %v, %v, want %v", got, err, want)
+	}
+	if _, err := parseSince("yesterday"); err == nil {
+		t.Errorf("parseSince(yesterday) error = nil, want an error")
+	}
+}
+
+func TestSearchEntries(t *testing.T) {
+	t.Setenv(audit.EnvPath, filepath.Join(t.TempDir(), "audit.jsonl"))
+	audit.Record("ec2 instance i-1", nil)
+	if err := audit.Flush(audit.Entry{Command: "icp-aws-cli ec2 stop", Region: "eu-west-1"}); err != nil {
+		t.Fatal(err)
+	}
+	audit.Record("s3 bucket web-logs", os.ErrPermission)
+	if err := audit.Flush(audit.Entry{Command: "icp-aws-cli s3 delete", Region: "eu-west-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatCSV)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+	if err := searchEntries(searchOptions{failed: true, outcomes: true}); err != nil {
+		t.Fatalf("searchEntries() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || lines[0] != "Timestamp,Identity,Resource,Status,Error" || !strings.HasSuffix(lines[1], ",,s3 bucket web-logs,failed,permission denied") {
+		t.Errorf("output = %q, want only the failed bucket", buf.String())
+	}
+}
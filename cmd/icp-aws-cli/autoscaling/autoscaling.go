@@ -2,22 +2,28 @@ package autoscaling
 
 import (
 	"icp-aws-cli/cmd/icp-aws-cli/autoscaling/commands"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/ui"
 
-	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/spf13/cobra"
 )
 
-func InitCommands(asClient *autoscaling.Client) *cobra.Command {
+func InitCommands(clients *awsclient.AWSClientCollection) *cobra.Command {
 	var autoscalingCmd = &cobra.Command{
 		Use:   "autoscaling",
 		Short: "Commands to interact with Amazon AutoScaling",
 		Long:  "Allows listing and managing AutoScaling groups in Amazon AutoScaling.",
 	}
 	// Initialize subcommands
-	commands.InitListGroupsCommand(asClient, autoscalingCmd)
-	commands.InitCreateGroupCommand(asClient, autoscalingCmd)
-	commands.InitDeleteGroupsCommand(asClient, autoscalingCmd)
-	commands.InitUpdateGroupCommand(asClient, autoscalingCmd)
+	commands.InitListGroupsCommand(clients, autoscalingCmd)
+	commands.InitCreateGroupCommand(clients, autoscalingCmd)
+	commands.InitDeleteGroupsCommand(clients, autoscalingCmd)
+	commands.InitUpdateGroupCommand(clients, autoscalingCmd)
 
 	return autoscalingCmd
 }
+
+// Views returns the resources of the service browsed by the ui command.
+func Views(clients *awsclient.AWSClientCollection) []ui.View {
+	return []ui.View{commands.GroupsView(clients)}
+}
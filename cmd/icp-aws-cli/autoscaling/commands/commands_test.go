@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/spf13/cobra"
+)
+
+// run executes an autoscaling subcommand against the fakes and returns what it
+// printed as text.
+func run(t *testing.T, clients *fake.Clients, args ...string) (string, error) {
+	t.Helper()
+	autoscalingCmd := &cobra.Command{Use: "autoscaling", SilenceUsage: true, SilenceErrors: true}
+	collection := clients.Collection()
+	InitCreateGroupCommand(collection, autoscalingCmd)
+	InitDeleteGroupsCommand(collection, autoscalingCmd)
+	InitGetInstancesCommand(collection, autoscalingCmd)
+	InitListGroupsCommand(collection, autoscalingCmd)
+	InitUpdateGroupCommand(collection, autoscalingCmd)
+
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+	autoscalingCmd.SetArgs(args)
+	err := autoscalingCmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func group(name string, instances ...string) types.AutoScalingGroup {
+	g := types.AutoScalingGroup{
+		AutoScalingGroupName: aws.String(name),
+		MinSize:              aws.Int32(1),
+		MaxSize:              aws.Int32(3),
+		DesiredCapacity:      aws.Int32(2),
+	}
+	for _, id := range instances {
+		g.Instances = append(g.Instances, types.Instance{
+			InstanceId:       aws.String(id),
+			InstanceType:     aws.String("t3.micro"),
+			AvailabilityZone: aws.String("us-east-1a"),
+			LifecycleState:   types.LifecycleStateInService,
+			HealthStatus:     aws.String("Healthy"),
+		})
+	}
+	return g
+}
+
+func newFakeAutoScaling(groups ...types.AutoScalingGroup) *fake.Clients {
+	clients := fake.New()
+	clients.AutoScaling.DescribeAutoScalingGroupsFunc = func(_ context.Context, input *autoscaling.DescribeAutoScalingGroupsInput) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+		if len(input.AutoScalingGroupNames) == 0 {
+			return &autoscaling.DescribeAutoScalingGroupsOutput{AutoScalingGroups: groups}, nil
+		}
+		named := []types.AutoScalingGroup{}
+		for _, group := range groups {
+			for _, name := range input.AutoScalingGroupNames {
+				if aws.ToString(group.AutoScalingGroupName) == name {
+					named = append(named, group)
+				}
+			}
+		}
+		return &autoscaling.DescribeAutoScalingGroupsOutput{AutoScalingGroups: named}, nil
+	}
+	return clients
+}
+
+// deletedGroups returns the group names passed to DeleteAutoScalingGroup.
+func deletedGroups(clients *fake.Clients) []string {
+	var names []string
+	for _, call := range clients.AutoScaling.Calls() {
+		if input, ok := call.Input.(*autoscaling.DeleteAutoScalingGroupInput); ok {
+			names = append(names, aws.ToString(input.AutoScalingGroupName))
+		}
+	}
+	return names
+}
+
+func TestListCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantInput *autoscaling.DescribeAutoScalingGroupsInput
+		wantErr   string
+	}{
+		{
+			name:      "all",
+			args:      []string{"list", "--all"},
+			wantInput: &autoscaling.DescribeAutoScalingGroupsInput{},
+		},
+		{
+			name:      "group name",
+			args:      []string{"list", "-g", "web"},
+			wantInput: &autoscaling.DescribeAutoScalingGroupsInput{AutoScalingGroupNames: []string{"web"}},
+		},
+		{
+			// The name glob is evaluated client-side, the exact tag server-side
+			name: "pattern and tag",
+			args: []string{"list", "-p", "we*", "-k", "Env", "-v", "prod"},
+			wantInput: &autoscaling.DescribeAutoScalingGroupsInput{Filters: []types.Filter{
+				{Name: aws.String("tag:Env"), Values: []string{"prod"}},
+			}},
+		},
+		{name: "no filter", args: []string{"list"}, wantErr: "at least one filter must be specified"},
+		{name: "all with filter", args: []string{"list", "--all", "-g", "web"}, wantErr: "cannot be combined"},
+		{name: "tag key without value", args: []string{"list", "-k", "Env"}, wantErr: "must be specified together"},
+		{name: "unknown key", args: []string{"list", "-f", "zone=us-east-1a"}, wantErr: "zone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeAutoScaling(group("web", "i-1", "i-2"))
+			got, err := run(t, clients, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if calls := clients.AutoScaling.Calls(); len(calls) != 0 {
+					t.Errorf("calls = %v, want none", clients.AutoScaling.Operations())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if want := "web\t2\t1\t3\t2\n"; got != want {
+				t.Errorf("output = %q, want %q", got, want)
+			}
+			if calls := clients.AutoScaling.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, tt.wantInput) {
+				t.Errorf("calls = %+v, want %+v", calls, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestGetInstancesCommand(t *testing.T) {
+	clients := newFakeAutoScaling(group("web", "i-1"))
+	got, err := run(t, clients, "get-instances", "-g", "web")
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if want := "i-1\tt3.micro\tus-east-1a\tInService\tHealthy\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	if _, err := run(t, newFakeAutoScaling(), "get-instances", "-g", "web"); err == nil || !strings.Contains(err.Error(), "no AutoScaling group found") {
+		t.Errorf("error = %v, want no group found", err)
+	}
+	if _, err := run(t, newFakeAutoScaling(), "get-instances"); err == nil {
+		t.Error("error = nil, want a missing group name")
+	}
+}
+
+func TestDeleteCommand(t *testing.T) {
+	confirm.SetAssumeYes(true)
+	t.Cleanup(func() { confirm.SetAssumeYes(false) })
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "group name", args: []string{"delete", "-g", "web"}, want: []string{"web"}},
+		{name: "all", args: []string{"delete", "--all"}, want: []string{"web", "api"}},
+		{name: "pattern", args: []string{"delete", "-p", "*"}, want: []string{"web", "api"}},
+		{name: "no filter", args: []string{"delete"}, wantErr: true},
+		{name: "filter", args: []string{"delete", "-f", "name~^a"}, want: []string{"api"}},
+		{name: "no match", args: []string{"delete", "-p", "db-*"}, wantErr: true},
+		{name: "all with filter", args: []string{"delete", "--all", "-g", "web"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeAutoScaling(group("web"), group("api"))
+			_, err := run(t, clients, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := deletedGroups(clients); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("deleted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateAndUpdateCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantInput interface{}
+		wantErr   bool
+	}{
+		{
+			name: "create",
+			args: []string{"create", "-g", "web", "-l", "web-lc", "-m", "1", "-x", "4", "-d", "2", "-t", "Env=prod"},
+			wantInput: &autoscaling.CreateAutoScalingGroupInput{
+				AutoScalingGroupName:    aws.String("web"),
+				LaunchConfigurationName: aws.String("web-lc"),
+				MinSize:                 aws.Int32(1),
+				MaxSize:                 aws.Int32(4),
+				DesiredCapacity:         aws.Int32(2),
+				Tags:                    []types.Tag{{Key: aws.String("Env"), Value: aws.String("prod")}},
+			},
+		},
+		{name: "create with invalid tag", args: []string{"create", "-g", "web", "-t", "Env"}, wantErr: true},
+		{
+			name: "update",
+			args: []string{"update", "-g", "web", "-m", "2", "-x", "6", "-d", "3"},
+			wantInput: &autoscaling.UpdateAutoScalingGroupInput{
+				AutoScalingGroupName: aws.String("web"),
+				MinSize:              aws.Int32(2),
+				MaxSize:              aws.Int32(6),
+				DesiredCapacity:      aws.Int32(3),
+			},
+		},
+		{name: "update without group", args: []string{"update", "-m", "2"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeAutoScaling()
+			_, err := run(t, clients, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			calls := clients.AutoScaling.Calls()
+			if tt.wantErr {
+				if len(calls) != 0 {
+					t.Errorf("calls = %v, want none", clients.AutoScaling.Operations())
+				}
+				return
+			}
+			if len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, tt.wantInput) {
+				t.Errorf("calls = %+v, want %+v", calls, tt.wantInput)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package commands
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/completion"
+
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+)
+
+// completeGroupNames suggests AutoScaling group names
+func completeGroupNames(clients *awsclient.AWSClientCollection) completion.Func {
+	return completion.Resources("autoscaling-groups", func(ctx context.Context) ([]string, error) {
+		groups, err := describeAllGroups(ctx, clients.AutoScaling, &autoscaling.DescribeAutoScalingGroupsInput{})
+		if err != nil {
+			return nil, err
+		}
+		return groupNames(groups), nil
+	})
+}
@@ -3,6 +3,9 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/dryrun"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
@@ -10,7 +13,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitCreateGroupCommand(asClient *autoscaling.Client, autoscalingCmd *cobra.Command) {
+func InitCreateGroupCommand(clients *awsclient.AWSClientCollection, autoscalingCmd *cobra.Command) {
 	var groupName, launchConfigurationName string
 	var minSize, maxSize, desiredCapacity int32
 	var tags []string
@@ -19,7 +22,7 @@ func InitCreateGroupCommand(asClient *autoscaling.Client, autoscalingCmd *cobra.
 		Use:   "create",
 		Short: "Creates an AutoScaling group",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return createGroup(asClient, groupName, launchConfigurationName, minSize, maxSize, desiredCapacity, tags)
+			return createGroup(cmd.Context(), clients.AutoScaling, groupName, launchConfigurationName, minSize, maxSize, desiredCapacity, tags)
 		},
 	}
 
@@ -33,7 +36,7 @@ func InitCreateGroupCommand(asClient *autoscaling.Client, autoscalingCmd *cobra.
 	autoscalingCmd.AddCommand(createGroupCmd)
 }
 
-func createGroup(asClient *autoscaling.Client, groupName, launchConfigurationName string, minSize, maxSize, desiredCapacity int32, tags []string) error {
+func createGroup(ctx context.Context, asClient awsclient.AutoScalingAPI, groupName, launchConfigurationName string, minSize, maxSize, desiredCapacity int32, tags []string) error {
 	tagList := []types.Tag{}
 	for _, tag := range tags {
 		parts := strings.SplitN(tag, "=", 2)
@@ -55,7 +58,12 @@ func createGroup(asClient *autoscaling.Client, groupName, launchConfigurationNam
 		Tags:                    tagList,
 	}
 
-	_, err := asClient.CreateAutoScalingGroup(context.TODO(), input)
+	if dryrun.Skip("Would create AutoScaling group %s", groupName) {
+		return nil
+	}
+
+	_, err := asClient.CreateAutoScalingGroup(ctx, input)
+	audit.Record(groupName, err)
 	if err != nil {
 		return fmt.Errorf("could not create AutoScaling group: %w", err)
 	}
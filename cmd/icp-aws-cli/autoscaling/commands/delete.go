@@ -3,119 +3,79 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/bulk"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/filter"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
-	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
 	"github.com/spf13/cobra"
 )
 
-func InitDeleteGroupsCommand(asClient *autoscaling.Client, autoscalingCmd *cobra.Command) {
-	var groupName string
-	var pattern string
-	var tagKey string
-	var tagValue string
-	var allGroups bool
+func InitDeleteGroupsCommand(clients *awsclient.AWSClientCollection, autoscalingCmd *cobra.Command) {
+	var selector *filter.Selector
 
 	var deleteGroupsCmd = &cobra.Command{
 		Use:   "delete",
 		Short: "Deletes AutoScaling groups",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if allGroups && (groupName != "" || pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("the --all flag cannot be combined with other filters")
+			expression, err := selector.Expression()
+			if err != nil {
+				return err
 			}
-
-			if allGroups {
-				return deleteAllGroups(asClient)
-			}
-
-			if groupName != "" && (pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("group name cannot be combined with other filters")
-			}
-
-			if groupName != "" {
-				return deleteGroupsByName(asClient, groupName)
-			}
-
-			if pattern == "" && tagKey == "" && tagValue == "" {
-				return fmt.Errorf("at least one filter must be specified")
-			}
-
-			return deleteGroupsWithFilters(asClient, pattern, tagKey, tagValue)
+			return deleteGroupsWithFilters(cmd.Context(), clients.AutoScaling, expression)
 		},
 	}
 
-	deleteGroupsCmd.Flags().StringVarP(&groupName, "group-name", "g", "", "AutoScaling group name to filter groups")
-	deleteGroupsCmd.Flags().StringVarP(&pattern, "pattern", "p", "", "Pattern to filter groups by name")
-	deleteGroupsCmd.Flags().StringVarP(&tagKey, "tag-key", "k", "", "Tag key to filter groups")
-	deleteGroupsCmd.Flags().StringVarP(&tagValue, "tag-value", "v", "", "Tag value to filter groups")
-	deleteGroupsCmd.Flags().BoolVarP(&allGroups, "all", "a", false, "Delete all groups")
+	selector = newGroupSelector(deleteGroupsCmd)
+	deleteGroupsCmd.RegisterFlagCompletionFunc("group-name", completeGroupNames(clients))
 
 	autoscalingCmd.AddCommand(deleteGroupsCmd)
 }
 
-func deleteAllGroups(asClient *autoscaling.Client) error {
-	result, err := asClient.DescribeAutoScalingGroups(context.TODO(), &autoscaling.DescribeAutoScalingGroupsInput{})
+func deleteGroupsWithFilters(ctx context.Context, asClient awsclient.AutoScalingAPI, expression filter.Expression) error {
+	input, remaining, err := groupsInput(expression)
 	if err != nil {
-		return fmt.Errorf("could not list AutoScaling groups: %w", err)
+		return err
 	}
 
-	for _, group := range result.AutoScalingGroups {
-		if err := deleteGroup(asClient, *group.AutoScalingGroupName); err != nil {
-			return err
-		}
+	groups, err := describeAllGroups(ctx, asClient, input)
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func deleteGroupsByName(asClient *autoscaling.Client, groupName string) error {
-	return deleteGroup(asClient, groupName)
-}
-
-func deleteGroupsWithFilters(asClient *autoscaling.Client, pattern, tagKey, tagValue string) error {
-	filters := []types.Filter{}
-
-	if pattern != "" {
-		filters = append(filters, types.Filter{
-			Name:   aws.String("tag:Name"),
-			Values: []string{pattern},
-		})
+	groups = matchGroups(groups, remaining)
+	if len(groups) == 0 {
+		return fmt.Errorf("no AutoScaling groups found with the specified filters")
 	}
 
-	if tagKey != "" {
-		if tagValue == "" {
-			return fmt.Errorf("tag value must be specified when tag key is provided")
-		}
-		filters = append(filters, types.Filter{
-			Name:   aws.String(fmt.Sprintf("tag:%s", tagKey)),
-			Values: []string{tagValue},
-		})
-	}
+	return deleteGroups(ctx, asClient, groupNames(groups))
+}
 
-	input := &autoscaling.DescribeAutoScalingGroupsInput{
-		Filters: filters,
+// deleteGroups asks for confirmation and force-deletes the given groups, terminating their instances
+func deleteGroups(ctx context.Context, asClient awsclient.AutoScalingAPI, groupNames []string) error {
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "force-delete", Kind: "AutoScaling group", Targets: groupNames}); err != nil {
+		return err
 	}
 
-	result, err := asClient.DescribeAutoScalingGroups(context.TODO(), input)
-	if err != nil {
-		return fmt.Errorf("could not list AutoScaling groups: %w", err)
-	}
+	return bulk.Run(ctx, "deleted", "AutoScaling group", groupNames, func(ctx context.Context, groupName string) error {
+		return deleteGroup(ctx, asClient, groupName)
+	})
+}
 
-	for _, group := range result.AutoScalingGroups {
-		if err := deleteGroup(asClient, *group.AutoScalingGroupName); err != nil {
-			return err
-		}
+func deleteGroup(ctx context.Context, asClient awsclient.AutoScalingAPI, groupName string) error {
+	if dryrun.Skip("Would delete AutoScaling group %s", groupName) {
+		return nil
 	}
 
-	return nil
-}
-
-func deleteGroup(asClient *autoscaling.Client, groupName string) error {
-	_, err := asClient.DeleteAutoScalingGroup(context.TODO(), &autoscaling.DeleteAutoScalingGroupInput{
+	_, err := asClient.DeleteAutoScalingGroup(ctx, &autoscaling.DeleteAutoScalingGroupInput{
 		AutoScalingGroupName: aws.String(groupName),
 		ForceDelete:          aws.Bool(true),
 	})
+	audit.Record(groupName, err)
 	if err != nil {
 		return fmt.Errorf("could not delete AutoScaling group %s: %w", groupName, err)
 	}
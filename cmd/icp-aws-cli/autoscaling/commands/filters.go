@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"icp-aws-cli/pkg/filter"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/spf13/cobra"
+)
+
+// newGroupSelector registers the selection flags shared by the group commands
+func newGroupSelector(cmd *cobra.Command) *filter.Selector {
+	selector := filter.NewSelector(cmd, "groups")
+	selector.Shorthand(cmd, "group-name", "g", "AutoScaling group name to filter groups (same as --filter name=NAME)", filter.Is("name"))
+	selector.Shorthand(cmd, "pattern", "p", "Name glob to filter groups, e.g. web-* (same as --filter name=PATTERN)", filter.Is("name"))
+	selector.TagFlags(cmd)
+	return selector
+}
+
+// groupsInput translates the exact name and tag conditions of an expression into a
+// DescribeAutoScalingGroups input, returning the conditions left to evaluate client-side
+func groupsInput(expression filter.Expression) (*autoscaling.DescribeAutoScalingGroupsInput, filter.Expression, error) {
+	input := &autoscaling.DescribeAutoScalingGroupsInput{}
+	remaining := filter.Expression{}
+	for _, condition := range expression {
+		_, isTag := condition.IsTag()
+		switch {
+		case condition.Key == "name" && condition.Exact() && input.AutoScalingGroupNames == nil:
+			input.AutoScalingGroupNames = condition.Values
+		case isTag && condition.Exact():
+			input.Filters = append(input.Filters, types.Filter{Name: aws.String(condition.Key), Values: condition.Values})
+		default:
+			remaining = append(remaining, condition)
+		}
+	}
+
+	if err := remaining.CheckKeys("name", "min", "max", "desired", "health-check"); err != nil {
+		return nil, nil, err
+	}
+	return input, remaining, nil
+}
+
+// matchGroups returns the groups satisfying the client-side conditions
+func matchGroups(groups []types.AutoScalingGroup, expression filter.Expression) []types.AutoScalingGroup {
+	if len(expression) == 0 {
+		return groups
+	}
+	matched := []types.AutoScalingGroup{}
+	for _, group := range groups {
+		if expression.Match(groupFields(group)) {
+			matched = append(matched, group)
+		}
+	}
+	return matched
+}
+
+// groupFields resolves the filter keys of a group
+func groupFields(group types.AutoScalingGroup) filter.Fields {
+	return func(key string) (string, bool) {
+		if tagKey, ok := filter.TagKey(key); ok {
+			for _, tag := range group.Tags {
+				if aws.ToString(tag.Key) == tagKey {
+					return aws.ToString(tag.Value), true
+				}
+			}
+			return "", false
+		}
+
+		switch key {
+		case "name":
+			return aws.ToString(group.AutoScalingGroupName), true
+		case "min":
+			return strconv.Itoa(int(aws.ToInt32(group.MinSize))), true
+		case "max":
+			return strconv.Itoa(int(aws.ToInt32(group.MaxSize))), true
+		case "desired":
+			return strconv.Itoa(int(aws.ToInt32(group.DesiredCapacity))), true
+		case "health-check":
+			return aws.ToString(group.HealthCheckType), group.HealthCheckType != nil
+		}
+		return "", false
+	}
+}
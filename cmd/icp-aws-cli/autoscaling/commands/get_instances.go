@@ -3,22 +3,31 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/spf13/cobra"
 )
 
-func InitGetInstancesCommand(asClient *autoscaling.Client, autoscalingCmd *cobra.Command) {
+func InitGetInstancesCommand(clients *awsclient.AWSClientCollection, autoscalingCmd *cobra.Command) {
 	var groupName string
 
 	var getInstancesCmd = &cobra.Command{
-		Use:   "get-instances",
-		Short: "Gets the instance IDs of all instances in an AutoScaling group",
+		Use:         "get-instances",
+		Short:       "Gets the instance IDs of all instances in an AutoScaling group",
+		Annotations: fanout.ReadOnly(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if groupName == "" {
 				return fmt.Errorf("group name must be specified")
 			}
-			return getInstances(asClient, groupName)
+			result, err := getInstances(cmd.Context(), clients.AutoScaling, groupName)
+			if err != nil {
+				return err
+			}
+			return output.Print(result)
 		},
 	}
 
@@ -26,22 +35,22 @@ func InitGetInstancesCommand(asClient *autoscaling.Client, autoscalingCmd *cobra
 	autoscalingCmd.AddCommand(getInstancesCmd)
 }
 
-func getInstances(asClient *autoscaling.Client, groupName string) error {
-	result, err := asClient.DescribeAutoScalingGroups(context.TODO(), &autoscaling.DescribeAutoScalingGroupsInput{
+func getInstances(ctx context.Context, asClient awsclient.AutoScalingAPI, groupName string) (*output.Result, error) {
+	result, err := asClient.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{
 		AutoScalingGroupNames: []string{groupName},
 	})
 	if err != nil {
-		return fmt.Errorf("could not describe AutoScaling group: %w", err)
+		return nil, fmt.Errorf("could not describe AutoScaling group: %w", err)
 	}
 
 	if len(result.AutoScalingGroups) == 0 {
-		return fmt.Errorf("no AutoScaling group found with name %s", groupName)
+		return nil, fmt.Errorf("no AutoScaling group found with name %s", groupName)
 	}
 
 	group := result.AutoScalingGroups[0]
+	records := output.NewResult("InstanceId", "InstanceType", "AvailabilityZone", "LifecycleState", "HealthStatus")
 	for _, instance := range group.Instances {
-		fmt.Printf("Instance ID: %s\n", *instance.InstanceId)
+		records.Add(*instance.InstanceId, aws.ToString(instance.InstanceType), aws.ToString(instance.AvailabilityZone), string(instance.LifecycleState), aws.ToString(instance.HealthStatus))
 	}
-
-	return nil
+	return records, nil
 }
@@ -3,6 +3,11 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/filter"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
@@ -10,114 +15,65 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitListGroupsCommand(asClient *autoscaling.Client, autoscalingCmd *cobra.Command) {
-	var groupName string
-	var pattern string
-	var tagKey string
-	var tagValue string
-	var allGroups bool
+func InitListGroupsCommand(clients *awsclient.AWSClientCollection, autoscalingCmd *cobra.Command) {
+	var selector *filter.Selector
+	var regions []string
+	var pagingOpts paging.Options
 
 	var listGroupsCmd = &cobra.Command{
-		Use:   "list",
-		Short: "Lists AutoScaling groups",
+		Use:         "list",
+		Short:       "Lists AutoScaling groups",
+		Annotations: fanout.ReadOnly(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if allGroups && (groupName != "" || pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("the --all flag cannot be combined with other filters")
+			expression, err := selector.Expression()
+			if err != nil {
+				return err
 			}
-
-			if allGroups {
-				return listAllGroups(asClient)
-			}
-
-			if groupName != "" && (pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("group name cannot be combined with other filters")
-			}
-
-			if groupName != "" {
-				return listGroupsByName(asClient, groupName)
-			}
-
-			if pattern == "" && tagKey == "" && tagValue == "" {
-				return fmt.Errorf("at least one filter must be specified")
+			input, remaining, err := groupsInput(expression)
+			if err != nil {
+				return err
 			}
-
-			return listGroupsWithFilters(asClient, pattern, tagKey, tagValue)
+			return fanout.Run(cmd.Context(), clients, regions, pagingOpts, func(ctx context.Context, clients *awsclient.AWSClientCollection) (*output.Result, string, error) {
+				return listGroups(ctx, clients.AutoScaling, *input, remaining, pagingOpts)
+			})
 		},
 	}
 
-	listGroupsCmd.Flags().StringVarP(&groupName, "group-name", "g", "", "AutoScaling group name to filter groups")
-	listGroupsCmd.Flags().StringVarP(&pattern, "pattern", "p", "", "Pattern to filter groups by name")
-	listGroupsCmd.Flags().StringVarP(&tagKey, "tag-key", "k", "", "Tag key to filter groups")
-	listGroupsCmd.Flags().StringVarP(&tagValue, "tag-value", "v", "", "Tag value to filter groups")
-	listGroupsCmd.Flags().BoolVarP(&allGroups, "all", "a", false, "List all groups")
+	selector = newGroupSelector(listGroupsCmd)
+	fanout.AddRegionsFlag(listGroupsCmd, &regions)
+	paging.AddFlags(listGroupsCmd, &pagingOpts, paging.Range{Min: 1, Max: 100})
+	listGroupsCmd.RegisterFlagCompletionFunc("group-name", completeGroupNames(clients))
 
 	autoscalingCmd.AddCommand(listGroupsCmd)
 }
 
-func listAllGroups(asClient *autoscaling.Client) error {
-	result, err := asClient.DescribeAutoScalingGroups(context.TODO(), &autoscaling.DescribeAutoScalingGroupsInput{})
+// listGroups returns the groups matching the input and the client-side conditions.
+// The input is taken by value as it is shared by the regions of a fan-out.
+func listGroups(ctx context.Context, asClient awsclient.AutoScalingAPI, input autoscaling.DescribeAutoScalingGroupsInput, remaining filter.Expression, pagingOpts paging.Options) (*output.Result, string, error) {
+	collector, err := paging.NewCollector[types.AutoScalingGroup](pagingOpts)
 	if err != nil {
-		return fmt.Errorf("could not list AutoScaling groups: %w", err)
+		return nil, "", err
 	}
 
-	for _, group := range result.AutoScalingGroups {
-		printGroup(group)
-	}
-
-	return nil
-}
-
-func listGroupsByName(asClient *autoscaling.Client, groupName string) error {
-	result, err := asClient.DescribeAutoScalingGroups(context.TODO(), &autoscaling.DescribeAutoScalingGroupsInput{
-		AutoScalingGroupNames: []string{groupName},
+	input.NextToken = collector.StartToken()
+	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(asClient, &input, func(o *autoscaling.DescribeAutoScalingGroupsPaginatorOptions) {
+		o.Limit = pagingOpts.PageSize
 	})
-	if err != nil {
-		return fmt.Errorf("could not list AutoScaling groups: %w", err)
-	}
-
-	for _, group := range result.AutoScalingGroups {
-		printGroup(group)
-	}
-
-	return nil
-}
-
-func listGroupsWithFilters(asClient *autoscaling.Client, pattern, tagKey, tagValue string) error {
-	filters := []types.Filter{}
-
-	if pattern != "" {
-		filters = append(filters, types.Filter{
-			Name:   aws.String("tag:Name"),
-			Values: []string{pattern},
-		})
-	}
-
-	if tagKey != "" {
-		if tagValue == "" {
-			return fmt.Errorf("tag value must be specified when tag key is provided")
+	for paginator.HasMorePages() && !collector.Done() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("could not list AutoScaling groups: %w", err)
 		}
-		filters = append(filters, types.Filter{
-			Name:   aws.String(fmt.Sprintf("tag:%s", tagKey)),
-			Values: []string{tagValue},
-		})
+		collector.Add(matchGroups(page.AutoScalingGroups, remaining), page.NextToken)
 	}
 
-	input := &autoscaling.DescribeAutoScalingGroupsInput{
-		Filters: filters,
-	}
-
-	result, err := asClient.DescribeAutoScalingGroups(context.TODO(), input)
-	if err != nil {
-		return fmt.Errorf("could not list AutoScaling groups: %w", err)
-	}
-
-	for _, group := range result.AutoScalingGroups {
-		printGroup(group)
-	}
-
-	return nil
+	return groupRecords(collector.Items()), collector.NextToken(), nil
 }
 
-func printGroup(group types.AutoScalingGroup) {
-	fmt.Printf("Group: %s, Current number of instances: %d, MinSize: %d, MaxSize: %d, DesiredCapacity: %d\n", *group.AutoScalingGroupName, len(group.Instances), group.MinSize, group.MaxSize, group.DesiredCapacity)
+func groupRecords(groups []types.AutoScalingGroup) *output.Result {
+	records := output.NewResult("AutoScalingGroupName", "Instances", "MinSize", "MaxSize", "DesiredCapacity")
+	for _, group := range groups {
+		records.Add(*group.AutoScalingGroupName, len(group.Instances), aws.ToInt32(group.MinSize), aws.ToInt32(group.MaxSize), aws.ToInt32(group.DesiredCapacity))
+	}
+	return records
 }
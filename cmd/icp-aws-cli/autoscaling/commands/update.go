@@ -3,13 +3,16 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/dryrun"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/spf13/cobra"
 )
 
-func InitUpdateGroupCommand(asClient *autoscaling.Client, autoscalingCmd *cobra.Command) {
+func InitUpdateGroupCommand(clients *awsclient.AWSClientCollection, autoscalingCmd *cobra.Command) {
 	var groupName string
 	var minSize int32
 	var maxSize int32
@@ -23,7 +26,7 @@ func InitUpdateGroupCommand(asClient *autoscaling.Client, autoscalingCmd *cobra.
 				return fmt.Errorf("group name must be specified")
 			}
 
-			return updateGroup(asClient, groupName, minSize, maxSize, desiredCapacity)
+			return updateGroup(cmd.Context(), clients.AutoScaling, groupName, minSize, maxSize, desiredCapacity)
 		},
 	}
 
@@ -32,16 +35,23 @@ func InitUpdateGroupCommand(asClient *autoscaling.Client, autoscalingCmd *cobra.
 	updateGroupCmd.Flags().Int32VarP(&maxSize, "max-size", "x", 0, "Maximum size of the group")
 	updateGroupCmd.Flags().Int32VarP(&desiredCapacity, "desired-capacity", "d", 0, "Desired capacity of the group")
 
+	updateGroupCmd.RegisterFlagCompletionFunc("group-name", completeGroupNames(clients))
+
 	autoscalingCmd.AddCommand(updateGroupCmd)
 }
 
-func updateGroup(asClient *autoscaling.Client, groupName string, minSize, maxSize, desiredCapacity int32) error {
-	_, err := asClient.UpdateAutoScalingGroup(context.TODO(), &autoscaling.UpdateAutoScalingGroupInput{
+func updateGroup(ctx context.Context, asClient awsclient.AutoScalingAPI, groupName string, minSize, maxSize, desiredCapacity int32) error {
+	if dryrun.Skip("Would update AutoScaling group %s (min %d, max %d, desired %d)", groupName, minSize, maxSize, desiredCapacity) {
+		return nil
+	}
+
+	_, err := asClient.UpdateAutoScalingGroup(ctx, &autoscaling.UpdateAutoScalingGroupInput{
 		AutoScalingGroupName: aws.String(groupName),
 		MinSize:              aws.Int32(minSize),
 		MaxSize:              aws.Int32(maxSize),
 		DesiredCapacity:      aws.Int32(desiredCapacity),
 	})
+	audit.Record(groupName, err)
 	if err != nil {
 		return fmt.Errorf("could not update AutoScaling group %s: %w", groupName, err)
 	}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+// describeAllGroups returns every AutoScaling group matching the input, following all pages
+func describeAllGroups(ctx context.Context, asClient awsclient.AutoScalingAPI, input *autoscaling.DescribeAutoScalingGroupsInput) ([]types.AutoScalingGroup, error) {
+	groups := []types.AutoScalingGroup{}
+	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(asClient, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not list AutoScaling groups: %w", err)
+		}
+		groups = append(groups, page.AutoScalingGroups...)
+	}
+	return groups, nil
+}
+
+// groupNames returns the name of every group
+func groupNames(groups []types.AutoScalingGroup) []string {
+	names := make([]string, len(groups))
+	for i, group := range groups {
+		names[i] = *group.AutoScalingGroupName
+	}
+	return names
+}
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
+	"icp-aws-cli/pkg/ui"
+
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+)
+
+// GroupsView browses the groups and their instances in the ui command.
+func GroupsView(clients *awsclient.AWSClientCollection) ui.View {
+	return ui.View{
+		Name: "Groups",
+		ID:   "AutoScalingGroupName",
+		List: func(ctx context.Context) (*output.Result, error) {
+			result, _, err := listGroups(ctx, clients.AutoScaling, autoscaling.DescribeAutoScalingGroupsInput{}, nil, paging.Options{})
+			return result, err
+		},
+		Detail: func(ctx context.Context, id string) (*output.Result, error) {
+			return getInstances(ctx, clients.AutoScaling, id)
+		},
+		Actions: []ui.Action{
+			{Key: 'd', Name: "delete", Args: func(id string) []string {
+				return []string{"autoscaling", "delete", "--group-name", id}
+			}},
+		},
+	}
+}
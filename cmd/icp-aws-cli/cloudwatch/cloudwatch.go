@@ -6,30 +6,35 @@ import (
 	"icp-aws-cli/cmd/icp-aws-cli/cloudwatch/commands/logs/loggroups"
 	"icp-aws-cli/cmd/icp-aws-cli/cloudwatch/commands/logs/streams"
 	"icp-aws-cli/cmd/icp-aws-cli/cloudwatch/commands/metrics"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/ui"
 
-	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
-	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/spf13/cobra"
 )
 
-func InitCommands(cwClient *cloudwatch.Client, cwLogsClient *cloudwatchlogs.Client) *cobra.Command {
+func InitCommands(clients *awsclient.AWSClientCollection) *cobra.Command {
 	var cloudWatchCmd = &cobra.Command{
 		Use:   "cloudwatch",
 		Short: "Commands for interacting with AWS CloudWatch",
 	}
 
 	// Initialize subcommands
-	alarms.InitCreateAlarmCommand(cwClient, cloudWatchCmd)
-	alarms.InitListAlarmsCommand(cwClient, cloudWatchCmd)
-	alarms.InitDeleteAlarmCommand(cwClient, cloudWatchCmd)
-	loggroups.InitCreateLogGroupCommand(cwLogsClient, cloudWatchCmd)
-	loggroups.InitListLogGroupsCommand(cwLogsClient, cloudWatchCmd)
-	loggroups.InitDeleteLogGroupCommand(cwLogsClient, cloudWatchCmd)
-	events.InitGetLogEventsCommand(cwLogsClient, cloudWatchCmd)
-	streams.InitListLogStreamsCommand(cwLogsClient, cloudWatchCmd)
-	metrics.InitCreateMetricCommand(cwClient, cloudWatchCmd)
-	metrics.InitListMetricsCommand(cwClient, cloudWatchCmd)
-	metrics.InitDeleteMetricCommand(cwClient, cloudWatchCmd)
+	alarms.InitCreateAlarmCommand(clients, cloudWatchCmd)
+	alarms.InitListAlarmsCommand(clients, cloudWatchCmd)
+	alarms.InitDeleteAlarmCommand(clients, cloudWatchCmd)
+	loggroups.InitCreateLogGroupCommand(clients, cloudWatchCmd)
+	loggroups.InitListLogGroupsCommand(clients, cloudWatchCmd)
+	loggroups.InitDeleteLogGroupCommand(clients, cloudWatchCmd)
+	events.InitGetLogEventsCommand(clients, cloudWatchCmd)
+	streams.InitListLogStreamsCommand(clients, cloudWatchCmd)
+	metrics.InitCreateMetricCommand(clients, cloudWatchCmd)
+	metrics.InitListMetricsCommand(clients, cloudWatchCmd)
+	metrics.InitDeleteMetricCommand(clients, cloudWatchCmd)
 
 	return cloudWatchCmd
 }
+
+// Views returns the resources of the service browsed by the ui command.
+func Views(clients *awsclient.AWSClientCollection) []ui.View {
+	return []ui.View{alarms.AlarmsView(clients), loggroups.LogGroupsView(clients)}
+}
@@ -0,0 +1,204 @@
+package alarms
+
+import (
+	"bytes"
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/spf13/cobra"
+)
+
+// run executes an alarm subcommand against the fakes and returns what it
+// printed as text.
+func run(t *testing.T, clients *fake.Clients, args ...string) (string, error) {
+	t.Helper()
+	cloudWatchCmd := &cobra.Command{Use: "cloudwatch", SilenceUsage: true, SilenceErrors: true}
+	collection := clients.Collection()
+	InitCreateAlarmCommand(collection, cloudWatchCmd)
+	InitDeleteAlarmCommand(collection, cloudWatchCmd)
+	InitListAlarmsCommand(collection, cloudWatchCmd)
+
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+	cloudWatchCmd.SetArgs(args)
+	err := cloudWatchCmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+// newFakeCloudWatch serves three alarms; only web-cpu is tagged Team=web.
+func newFakeCloudWatch() *fake.Clients {
+	clients := fake.New()
+	clients.CloudWatch.DescribeAlarmsFunc = func(_ context.Context, input *cloudwatch.DescribeAlarmsInput) (*cloudwatch.DescribeAlarmsOutput, error) {
+		alarm := func(name, state string) types.MetricAlarm {
+			return types.MetricAlarm{
+				AlarmName:  aws.String(name),
+				AlarmArn:   aws.String("arn:aws:cloudwatch:us-east-1:123456789012:alarm:" + name),
+				StateValue: types.StateValue(state),
+				MetricName: aws.String("CPUUtilization"),
+				Threshold:  aws.Float64(80),
+			}
+		}
+		output := &cloudwatch.DescribeAlarmsOutput{}
+		for _, a := range []types.MetricAlarm{alarm("web-cpu", "ALARM"), alarm("api-cpu", "OK"), alarm("web-disk", "OK")} {
+			name := aws.ToString(a.AlarmName)
+			if input.AlarmNames != nil && !slices.Contains(input.AlarmNames, name) ||
+				!strings.HasPrefix(name, aws.ToString(input.AlarmNamePrefix)) ||
+				input.StateValue != "" && input.StateValue != a.StateValue {
+				continue
+			}
+			output.MetricAlarms = append(output.MetricAlarms, a)
+		}
+		return output, nil
+	}
+	clients.CloudWatch.ListTagsForResourceFunc = func(_ context.Context, input *cloudwatch.ListTagsForResourceInput) (*cloudwatch.ListTagsForResourceOutput, error) {
+		if strings.HasSuffix(aws.ToString(input.ResourceARN), ":web-cpu") {
+			return &cloudwatch.ListTagsForResourceOutput{Tags: []types.Tag{{Key: aws.String("Team"), Value: aws.String("web")}}}, nil
+		}
+		return &cloudwatch.ListTagsForResourceOutput{}, nil
+	}
+	return clients
+}
+
+// deletedAlarms returns the alarm names passed to DeleteAlarms.
+func deletedAlarms(clients *fake.Clients) []string {
+	var names []string
+	for _, call := range clients.CloudWatch.Calls() {
+		if input, ok := call.Input.(*cloudwatch.DeleteAlarmsInput); ok {
+			names = append(names, input.AlarmNames...)
+		}
+	}
+	return names
+}
+
+func TestListAlarmsCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		want      string
+		wantInput *cloudwatch.DescribeAlarmsInput
+		wantErr   string
+	}{
+		{
+			name:      "all",
+			args:      []string{"list-alarms", "--all"},
+			want:      "web-cpu\tALARM\tCPUUtilization\t80\napi-cpu\tOK\tCPUUtilization\t80\nweb-disk\tOK\tCPUUtilization\t80\n",
+			wantInput: &cloudwatch.DescribeAlarmsInput{},
+		},
+		{
+			name:      "prefix and pattern",
+			args:      []string{"list-alarms", "-x", "web-", "-p", "*cpu"},
+			want:      "web-cpu\tALARM\tCPUUtilization\t80\n",
+			wantInput: &cloudwatch.DescribeAlarmsInput{AlarmNamePrefix: aws.String("web-")},
+		},
+		{
+			name:      "tag",
+			args:      []string{"list-alarms", "-k", "Team", "-v", "web"},
+			want:      "web-cpu\tALARM\tCPUUtilization\t80\n",
+			wantInput: &cloudwatch.DescribeAlarmsInput{},
+		},
+		{name: "no filter", args: []string{"list-alarms"}, wantErr: "at least one filter must be specified"},
+		{
+			name:      "state and regex",
+			args:      []string{"list-alarms", "-f", "state=OK", "-f", "name~disk$"},
+			want:      "web-disk\tOK\tCPUUtilization\t80\n",
+			wantInput: &cloudwatch.DescribeAlarmsInput{StateValue: types.StateValueOk},
+		},
+		{name: "all with filter", args: []string{"list-alarms", "--all", "-x", "web-"}, wantErr: "cannot be combined"},
+		{name: "invalid regex", args: []string{"list-alarms", "-f", "name~("}, wantErr: "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeCloudWatch()
+			got, err := run(t, clients, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if calls := clients.CloudWatch.Calls(); !reflect.DeepEqual(calls[0].Input, tt.wantInput) {
+				t.Errorf("input = %+v, want %+v", calls[0].Input, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestDeleteAlarmsCommand(t *testing.T) {
+	confirm.SetAssumeYes(true)
+	t.Cleanup(func() { confirm.SetAssumeYes(false) })
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "alarm name", args: []string{"delete-alarms", "-n", "web-cpu"}, want: []string{"web-cpu"}},
+		{name: "pattern", args: []string{"delete-alarms", "-p", "web-*"}, want: []string{"web-cpu", "web-disk"}},
+		{name: "tag", args: []string{"delete-alarms", "-k", "Team", "-v", "web"}, want: []string{"web-cpu"}},
+		{name: "no filter", args: []string{"delete-alarms"}, wantErr: true},
+		{name: "all with filter", args: []string{"delete-alarms", "--all", "-p", "web"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeCloudWatch()
+			_, err := run(t, clients, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := deletedAlarms(clients); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("deleted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateAlarmCommand(t *testing.T) {
+	clients := newFakeCloudWatch()
+	_, err := run(t, clients, "create-alarm", "-n", "web-cpu", "-m", "CPUUtilization", "-s", "AWS/EC2",
+		"-c", "GreaterThanThreshold", "-t", "80", "-e", "3", "-g", "Team=web")
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	want := &cloudwatch.PutMetricAlarmInput{
+		AlarmName:          aws.String("web-cpu"),
+		MetricName:         aws.String("CPUUtilization"),
+		Namespace:          aws.String("AWS/EC2"),
+		ComparisonOperator: types.ComparisonOperatorGreaterThanThreshold,
+		Threshold:          aws.Float64(80),
+		EvaluationPeriods:  aws.Int32(3),
+		Tags:               []types.Tag{{Key: aws.String("Team"), Value: aws.String("web")}},
+	}
+	if calls := clients.CloudWatch.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, want) {
+		t.Errorf("calls = %+v, want %+v", calls, want)
+	}
+
+	clients = newFakeCloudWatch()
+	if _, err := run(t, clients, "create-alarm", "-n", "web-cpu", "-g", "Team"); err == nil {
+		t.Error("error = nil, want an invalid tag")
+	}
+	if ops := clients.CloudWatch.Operations(); len(ops) != 0 {
+		t.Errorf("calls = %v, want none", ops)
+	}
+}
@@ -0,0 +1,20 @@
+package alarms
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/completion"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+)
+
+// completeAlarmNames suggests alarm names
+func completeAlarmNames(clients *awsclient.AWSClientCollection) completion.Func {
+	return completion.Resources("cloudwatch-alarms", func(ctx context.Context) ([]string, error) {
+		alarms, err := describeAllAlarms(ctx, clients.CloudWatch, &cloudwatch.DescribeAlarmsInput{})
+		if err != nil {
+			return nil, err
+		}
+		return alarmNames(alarms), nil
+	})
+}
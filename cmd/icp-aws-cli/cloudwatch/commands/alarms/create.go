@@ -3,6 +3,9 @@ package alarms
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/dryrun"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
@@ -10,7 +13,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitCreateAlarmCommand(cwClient *cloudwatch.Client, cloudWatchCmd *cobra.Command) {
+func InitCreateAlarmCommand(clients *awsclient.AWSClientCollection, cloudWatchCmd *cobra.Command) {
 	var alarmName, metricName, namespace, comparisonOperator string
 	var threshold float64
 	var evaluationPeriods int32
@@ -20,7 +23,7 @@ func InitCreateAlarmCommand(cwClient *cloudwatch.Client, cloudWatchCmd *cobra.Co
 		Use:   "create-alarm",
 		Short: "Creates a CloudWatch alarm",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return createAlarm(cwClient, alarmName, metricName, namespace, comparisonOperator, threshold, evaluationPeriods, tags)
+			return createAlarm(cmd.Context(), clients.CloudWatch, alarmName, metricName, namespace, comparisonOperator, threshold, evaluationPeriods, tags)
 		},
 	}
 
@@ -35,7 +38,7 @@ func InitCreateAlarmCommand(cwClient *cloudwatch.Client, cloudWatchCmd *cobra.Co
 	cloudWatchCmd.AddCommand(createAlarmCmd)
 }
 
-func createAlarm(cwClient *cloudwatch.Client, alarmName, metricName, namespace, comparisonOperator string, threshold float64, evaluationPeriods int32, tags []string) error {
+func createAlarm(ctx context.Context, cwClient awsclient.CloudWatchAPI, alarmName, metricName, namespace, comparisonOperator string, threshold float64, evaluationPeriods int32, tags []string) error {
 	tagList := []types.Tag{}
 	for _, tag := range tags {
 		parts := strings.SplitN(tag, "=", 2)
@@ -58,7 +61,12 @@ func createAlarm(cwClient *cloudwatch.Client, alarmName, metricName, namespace,
 		Tags:               tagList,
 	}
 
-	_, err := cwClient.PutMetricAlarm(context.TODO(), input)
+	if dryrun.Skip("Would create alarm %s", alarmName) {
+		return nil
+	}
+
+	_, err := cwClient.PutMetricAlarm(ctx, input)
+	audit.Record(alarmName, err)
 	if err != nil {
 		return fmt.Errorf("could not create alarm: %w", err)
 	}
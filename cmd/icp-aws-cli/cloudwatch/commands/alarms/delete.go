@@ -3,150 +3,78 @@ package alarms
 import (
 	"context"
 	"fmt"
-	"icp-aws-cli/pkg/utils"
-	"regexp"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/bulk"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/filter"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
-	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/spf13/cobra"
 )
 
-func InitDeleteAlarmCommand(cwClient *cloudwatch.Client, cloudWatchCmd *cobra.Command) {
-	var alarmName string
-	var prefix string
-	var pattern string
-	var tagKey string
-	var tagValue string
-	var allAlarms bool
+func InitDeleteAlarmCommand(clients *awsclient.AWSClientCollection, cloudWatchCmd *cobra.Command) {
+	var selector *filter.Selector
 
 	var deleteAlarmCmd = &cobra.Command{
 		Use:   "delete-alarms",
 		Short: "Deletes CloudWatch alarms",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if allAlarms && (alarmName != "" || prefix != "" || pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("the --all flag cannot be combined with other filters")
-			}
-
-			if allAlarms {
-				if !utils.ConfirmAction() {
-					return fmt.Errorf("action cancelled by user")
-				}
-				return deleteAllAlarms(cwClient)
-			}
-
-			if alarmName != "" && (prefix != "" || pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("alarm name cannot be combined with other filters")
-			}
-
-			if alarmName != "" {
-				return deleteAlarmByName(cwClient, alarmName)
-			}
-
-			if prefix == "" && pattern == "" && tagKey == "" && tagValue == "" {
-				return fmt.Errorf("at least one filter must be specified")
+			expression, err := selector.Expression()
+			if err != nil {
+				return err
 			}
-
-			return deleteAlarmsWithFilters(cwClient, prefix, pattern, tagKey, tagValue)
+			return deleteAlarmsWithFilters(cmd.Context(), clients.CloudWatch, expression)
 		},
 	}
 
-	deleteAlarmCmd.Flags().StringVarP(&alarmName, "alarm-name", "n", "", "Alarm name to filter alarms")
-	deleteAlarmCmd.Flags().StringVarP(&prefix, "prefix", "x", "", "Prefix to filter alarms by name")
-	deleteAlarmCmd.Flags().StringVarP(&pattern, "pattern", "p", "", "Pattern to filter alarms by name")
-	deleteAlarmCmd.Flags().StringVarP(&tagKey, "tag-key", "k", "", "Tag key to filter alarms")
-	deleteAlarmCmd.Flags().StringVarP(&tagValue, "tag-value", "v", "", "Tag value to filter alarms")
-	deleteAlarmCmd.Flags().BoolVarP(&allAlarms, "all", "a", false, "Delete all alarms")
+	selector = newAlarmSelector(deleteAlarmCmd)
+	deleteAlarmCmd.RegisterFlagCompletionFunc("alarm-name", completeAlarmNames(clients))
 
 	cloudWatchCmd.AddCommand(deleteAlarmCmd)
 }
 
-func deleteAllAlarms(cwClient *cloudwatch.Client) error {
-	result, err := cwClient.DescribeAlarms(context.TODO(), &cloudwatch.DescribeAlarmsInput{})
+func deleteAlarmsWithFilters(ctx context.Context, cwClient awsclient.CloudWatchAPI, expression filter.Expression) error {
+	input, remaining, err := alarmsInput(expression)
 	if err != nil {
-		return fmt.Errorf("could not list alarms: %w", err)
+		return err
 	}
 
-	for _, alarm := range result.MetricAlarms {
-		_, err := cwClient.DeleteAlarms(context.TODO(), &cloudwatch.DeleteAlarmsInput{
-			AlarmNames: []string{*alarm.AlarmName},
-		})
-		if err != nil {
-			return fmt.Errorf("could not delete alarm %s: %w", *alarm.AlarmName, err)
-		}
-		fmt.Printf("Deleted alarm %s\n", *alarm.AlarmName)
+	alarms, err := describeAllAlarms(ctx, cwClient, input)
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func deleteAlarmByName(cwClient *cloudwatch.Client, alarmName string) error {
-	_, err := cwClient.DeleteAlarms(context.TODO(), &cloudwatch.DeleteAlarmsInput{
-		AlarmNames: []string{alarmName},
-	})
+	alarms, err = filterAlarms(ctx, cwClient, alarms, remaining)
 	if err != nil {
-		return fmt.Errorf("could not delete alarm %s: %w", alarmName, err)
+		return err
 	}
-
-	fmt.Printf("Deleted alarm %s\n", alarmName)
-	return nil
-}
-
-func deleteAlarmsWithFilters(cwClient *cloudwatch.Client, prefix, pattern, tagKey, tagValue string) error {
-	input := &cloudwatch.DescribeAlarmsInput{}
-
-	if prefix != "" {
-		input.AlarmNamePrefix = aws.String(prefix)
+	if len(alarms) == 0 {
+		return fmt.Errorf("no alarms found with the specified filters")
 	}
 
-	result, err := cwClient.DescribeAlarms(context.TODO(), input)
-	if err != nil {
-		return fmt.Errorf("could not list alarms: %w", err)
-	}
+	return deleteAlarms(ctx, cwClient, alarmNames(alarms))
+}
 
-	var alarms []types.MetricAlarm
-	if pattern != "" {
-		re, err := regexp.Compile(pattern)
-		if err != nil {
-			return fmt.Errorf("invalid pattern: %w", err)
-		}
-		for _, alarm := range result.MetricAlarms {
-			if re.MatchString(*alarm.AlarmName) {
-				alarms = append(alarms, alarm)
-			}
-		}
-	} else {
-		alarms = result.MetricAlarms
+// deleteAlarms asks for confirmation and deletes the given alarms one by one
+func deleteAlarms(ctx context.Context, cwClient awsclient.CloudWatchAPI, names []string) error {
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "delete", Kind: "alarm", Targets: names}); err != nil {
+		return err
 	}
 
-	if tagKey != "" && tagValue != "" {
-		var filteredAlarms []types.MetricAlarm
-		for _, alarm := range alarms {
-			tags, err := cwClient.ListTagsForResource(context.TODO(), &cloudwatch.ListTagsForResourceInput{
-				ResourceARN: alarm.AlarmArn,
-			})
-			if err != nil {
-				return fmt.Errorf("could not list tags for alarm %s: %w", *alarm.AlarmName, err)
-			}
-			for _, tag := range tags.Tags {
-				if *tag.Key == tagKey && *tag.Value == tagValue {
-					filteredAlarms = append(filteredAlarms, alarm)
-					break
-				}
-			}
+	return bulk.Run(ctx, "deleted", "alarm", names, func(ctx context.Context, name string) error {
+		if dryrun.Skip("Would delete alarm %s", name) {
+			return nil
 		}
-		alarms = filteredAlarms
-	}
-
-	for _, alarm := range alarms {
-		_, err := cwClient.DeleteAlarms(context.TODO(), &cloudwatch.DeleteAlarmsInput{
-			AlarmNames: []string{*alarm.AlarmName},
+		_, err := cwClient.DeleteAlarms(ctx, &cloudwatch.DeleteAlarmsInput{
+			AlarmNames: []string{name},
 		})
+		audit.Record(name, err)
 		if err != nil {
-			return fmt.Errorf("could not delete alarm %s: %w", *alarm.AlarmName, err)
+			return fmt.Errorf("could not delete alarm %s: %w", name, err)
 		}
-		fmt.Printf("Deleted alarm %s\n", *alarm.AlarmName)
-	}
-
-	return nil
+		fmt.Printf("Deleted alarm %s\n", name)
+		return nil
+	})
 }
@@ -0,0 +1,103 @@
+package alarms
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/filter"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/spf13/cobra"
+)
+
+// newAlarmSelector registers the selection flags shared by the alarm commands
+func newAlarmSelector(cmd *cobra.Command) *filter.Selector {
+	selector := filter.NewSelector(cmd, "alarms")
+	selector.Shorthand(cmd, "alarm-name", "n", "Alarm name to filter alarms (same as --filter name=NAME)", filter.Is("name"))
+	selector.Shorthand(cmd, "prefix", "x", "Prefix to filter alarms by name (same as --filter name=PREFIX*)", func(prefix string) string {
+		return "name=" + prefix + "*"
+	})
+	selector.Shorthand(cmd, "pattern", "p", "Name glob to filter alarms, e.g. prod-*-cpu (same as --filter name=PATTERN)", filter.Is("name"))
+	selector.TagFlags(cmd)
+	return selector
+}
+
+// alarmsInput translates the name and state conditions CloudWatch supports into a
+// DescribeAlarms input, returning the conditions left to evaluate client-side
+func alarmsInput(expression filter.Expression) (*cloudwatch.DescribeAlarmsInput, filter.Expression, error) {
+	input := &cloudwatch.DescribeAlarmsInput{}
+	remaining := filter.Expression{}
+	for _, condition := range expression {
+		// AlarmNames and AlarmNamePrefix cannot be combined
+		byName := input.AlarmNames != nil || input.AlarmNamePrefix != nil
+		prefix, isPrefix := condition.Prefix()
+		switch {
+		case condition.Key == "name" && condition.Exact() && !byName:
+			input.AlarmNames = condition.Values
+		case condition.Key == "name" && isPrefix && !byName:
+			input.AlarmNamePrefix = aws.String(prefix)
+		case condition.Key == "state" && condition.Exact() && len(condition.Values) == 1 && input.StateValue == "":
+			input.StateValue = types.StateValue(condition.Values[0])
+		default:
+			remaining = append(remaining, condition)
+		}
+	}
+
+	if err := remaining.CheckKeys("name", "state", "metric", "namespace"); err != nil {
+		return nil, nil, err
+	}
+	return input, remaining, nil
+}
+
+// filterAlarms keeps the alarms satisfying the client-side conditions. Tags are
+// only looked up, one call per alarm, when a condition needs them.
+func filterAlarms(ctx context.Context, cwClient awsclient.CloudWatchAPI, alarms []types.MetricAlarm, expression filter.Expression) ([]types.MetricAlarm, error) {
+	if len(expression) == 0 {
+		return alarms, nil
+	}
+
+	matched := []types.MetricAlarm{}
+	for _, alarm := range alarms {
+		tags := map[string]string{}
+		if expression.HasTags() {
+			output, err := cwClient.ListTagsForResource(ctx, &cloudwatch.ListTagsForResourceInput{
+				ResourceARN: alarm.AlarmArn,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("could not list tags for alarm %s: %w", *alarm.AlarmName, err)
+			}
+			for _, tag := range output.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+		}
+
+		if expression.Match(alarmFields(alarm, tags)) {
+			matched = append(matched, alarm)
+		}
+	}
+	return matched, nil
+}
+
+// alarmFields resolves the filter keys of an alarm
+func alarmFields(alarm types.MetricAlarm, tags map[string]string) filter.Fields {
+	return func(key string) (string, bool) {
+		if tagKey, ok := filter.TagKey(key); ok {
+			value, ok := tags[tagKey]
+			return value, ok
+		}
+
+		switch key {
+		case "name":
+			return aws.ToString(alarm.AlarmName), true
+		case "state":
+			return string(alarm.StateValue), true
+		case "metric":
+			return aws.ToString(alarm.MetricName), alarm.MetricName != nil
+		case "namespace":
+			return aws.ToString(alarm.Namespace), alarm.Namespace != nil
+		}
+		return "", false
+	}
+}
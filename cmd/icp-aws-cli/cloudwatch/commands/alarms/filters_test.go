@@ -0,0 +1,66 @@
+package alarms
+
+import (
+	"icp-aws-cli/pkg/filter"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+)
+
+func TestAlarmsInput(t *testing.T) {
+	tests := []struct {
+		args          []string
+		want          *cloudwatch.DescribeAlarmsInput
+		wantRemaining []string
+		wantErr       bool
+	}{
+		{
+			args: []string{"name=web-5xx,api-5xx", "state=ALARM"},
+			want: &cloudwatch.DescribeAlarmsInput{AlarmNames: []string{"web-5xx", "api-5xx"}, StateValue: "ALARM"},
+		},
+		{
+			args: []string{"name=prod-*"},
+			want: &cloudwatch.DescribeAlarmsInput{AlarmNamePrefix: aws.String("prod-")},
+		},
+		{
+			// Names and a prefix cannot be combined, nor can two states
+			args:          []string{"name=prod-*", "name=prod-cpu", "state=OK", "state=ALARM"},
+			want:          &cloudwatch.DescribeAlarmsInput{AlarmNamePrefix: aws.String("prod-"), StateValue: "OK"},
+			wantRemaining: []string{"name=prod-cpu", "state=ALARM"},
+		},
+		{
+			args:          []string{"name=prod-*-cpu", "state=OK,ALARM", "tag:Team=web", "metric~CPU"},
+			want:          &cloudwatch.DescribeAlarmsInput{},
+			wantRemaining: []string{"name=prod-*-cpu", "state=OK,ALARM", "tag:Team=web", "metric~CPU"},
+		},
+		{args: []string{"dimension=InstanceId"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			expression, err := filter.Parse(tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			input, remaining, err := alarmsInput(expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("alarmsInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(input, tt.want) {
+				t.Errorf("input = %+v, want %+v", input, tt.want)
+			}
+			var gotRemaining []string
+			for _, condition := range remaining {
+				gotRemaining = append(gotRemaining, condition.String())
+			}
+			if !reflect.DeepEqual(gotRemaining, tt.wantRemaining) {
+				t.Errorf("remaining = %q, want %q", gotRemaining, tt.wantRemaining)
+			}
+		})
+	}
+}
@@ -3,7 +3,11 @@ package alarms
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/filter"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
@@ -11,133 +15,69 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitListAlarmsCommand(cwClient *cloudwatch.Client, cloudWatchCmd *cobra.Command) {
-	var alarmName string
-	var prefix string
-	var pattern string
-	var tagKey string
-	var tagValue string
-	var allAlarms bool
+func InitListAlarmsCommand(clients *awsclient.AWSClientCollection, cloudWatchCmd *cobra.Command) {
+	var selector *filter.Selector
+	var regions []string
+	var pagingOpts paging.Options
 
 	var listAlarmsCmd = &cobra.Command{
-		Use:   "list-alarms",
-		Short: "Lists CloudWatch alarms",
+		Use:         "list-alarms",
+		Short:       "Lists CloudWatch alarms",
+		Annotations: fanout.ReadOnly(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if allAlarms && (alarmName != "" || prefix != "" || pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("the --all flag cannot be combined with other filters")
-			}
-
-			if allAlarms {
-				return listAllAlarms(cwClient)
-			}
-
-			if alarmName != "" && (prefix != "" || pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("alarm name cannot be combined with other filters")
-			}
-
-			if alarmName != "" {
-				return listAlarmsByName(cwClient, alarmName)
+			expression, err := selector.Expression()
+			if err != nil {
+				return err
 			}
-
-			if prefix == "" && pattern == "" && tagKey == "" && tagValue == "" {
-				return fmt.Errorf("at least one filter must be specified")
+			input, remaining, err := alarmsInput(expression)
+			if err != nil {
+				return err
 			}
-
-			return listAlarmsWithFilters(cwClient, prefix, pattern, tagKey, tagValue)
+			return fanout.Run(cmd.Context(), clients, regions, pagingOpts, func(ctx context.Context, clients *awsclient.AWSClientCollection) (*output.Result, string, error) {
+				return listAlarms(ctx, clients.CloudWatch, *input, remaining, pagingOpts)
+			})
 		},
 	}
 
-	listAlarmsCmd.Flags().StringVarP(&alarmName, "alarm-name", "n", "", "Alarm name to filter alarms")
-	listAlarmsCmd.Flags().StringVarP(&prefix, "prefix", "x", "", "Prefix to filter alarms by name")
-	listAlarmsCmd.Flags().StringVarP(&pattern, "pattern", "p", "", "Pattern to filter alarms by name")
-	listAlarmsCmd.Flags().StringVarP(&tagKey, "tag-key", "k", "", "Tag key to filter alarms")
-	listAlarmsCmd.Flags().StringVarP(&tagValue, "tag-value", "v", "", "Tag value to filter alarms")
-	listAlarmsCmd.Flags().BoolVarP(&allAlarms, "all", "a", false, "List all alarms")
+	selector = newAlarmSelector(listAlarmsCmd)
+	fanout.AddRegionsFlag(listAlarmsCmd, &regions)
+	paging.AddFlags(listAlarmsCmd, &pagingOpts, paging.Range{Min: 1, Max: 100})
+	listAlarmsCmd.RegisterFlagCompletionFunc("alarm-name", completeAlarmNames(clients))
 
 	cloudWatchCmd.AddCommand(listAlarmsCmd)
 }
 
-func listAllAlarms(cwClient *cloudwatch.Client) error {
-	result, err := cwClient.DescribeAlarms(context.TODO(), &cloudwatch.DescribeAlarmsInput{})
+// listAlarms returns the alarms matching the input, applying the client-side conditions page by page.
+// The input is taken by value as it is shared by the regions of a fan-out.
+func listAlarms(ctx context.Context, cwClient awsclient.CloudWatchAPI, input cloudwatch.DescribeAlarmsInput, remaining filter.Expression, pagingOpts paging.Options) (*output.Result, string, error) {
+	collector, err := paging.NewCollector[types.MetricAlarm](pagingOpts)
 	if err != nil {
-		return fmt.Errorf("could not list alarms: %w", err)
-	}
-
-	for _, alarm := range result.MetricAlarms {
-		printAlarm(alarm)
+		return nil, "", err
 	}
 
-	return nil
-}
-
-func listAlarmsByName(cwClient *cloudwatch.Client, alarmName string) error {
-	result, err := cwClient.DescribeAlarms(context.TODO(), &cloudwatch.DescribeAlarmsInput{
-		AlarmNames: []string{alarmName},
+	input.NextToken = collector.StartToken()
+	paginator := cloudwatch.NewDescribeAlarmsPaginator(cwClient, &input, func(o *cloudwatch.DescribeAlarmsPaginatorOptions) {
+		o.Limit = pagingOpts.PageSize
 	})
-	if err != nil {
-		return fmt.Errorf("could not list alarms: %w", err)
-	}
-
-	for _, alarm := range result.MetricAlarms {
-		printAlarm(alarm)
-	}
-
-	return nil
-}
-
-func listAlarmsWithFilters(cwClient *cloudwatch.Client, prefix, pattern, tagKey, tagValue string) error {
-	input := &cloudwatch.DescribeAlarmsInput{}
-
-	if prefix != "" {
-		input.AlarmNamePrefix = aws.String(prefix)
-	}
-
-	result, err := cwClient.DescribeAlarms(context.TODO(), input)
-	if err != nil {
-		return fmt.Errorf("could not list alarms: %w", err)
-	}
-
-	var alarms []types.MetricAlarm
-	if pattern != "" {
-		re, err := regexp.Compile(pattern)
+	for paginator.HasMorePages() && !collector.Done() {
+		page, err := paginator.NextPage(ctx)
 		if err != nil {
-			return fmt.Errorf("invalid pattern: %w", err)
+			return nil, "", fmt.Errorf("could not list alarms: %w", err)
 		}
-		for _, alarm := range result.MetricAlarms {
-			if re.MatchString(*alarm.AlarmName) {
-				alarms = append(alarms, alarm)
-			}
+		alarms, err := filterAlarms(ctx, cwClient, page.MetricAlarms, remaining)
+		if err != nil {
+			return nil, "", err
 		}
-	} else {
-		alarms = result.MetricAlarms
+		collector.Add(alarms, page.NextToken)
 	}
 
-	if tagKey != "" && tagValue != "" {
-		var filteredAlarms []types.MetricAlarm
-		for _, alarm := range alarms {
-			tags, err := cwClient.ListTagsForResource(context.TODO(), &cloudwatch.ListTagsForResourceInput{
-				ResourceARN: alarm.AlarmArn,
-			})
-			if err != nil {
-				return fmt.Errorf("could not list tags for alarm %s: %w", *alarm.AlarmName, err)
-			}
-			for _, tag := range tags.Tags {
-				if *tag.Key == tagKey && *tag.Value == tagValue {
-					filteredAlarms = append(filteredAlarms, alarm)
-					break
-				}
-			}
-		}
-		alarms = filteredAlarms
-	}
+	return alarmRecords(collector.Items()), collector.NextToken(), nil
+}
 
+func alarmRecords(alarms []types.MetricAlarm) *output.Result {
+	records := output.NewResult("AlarmName", "State", "MetricName", "Threshold")
 	for _, alarm := range alarms {
-		printAlarm(alarm)
+		records.Add(*alarm.AlarmName, string(alarm.StateValue), aws.ToString(alarm.MetricName), aws.ToFloat64(alarm.Threshold))
 	}
-
-	return nil
-}
-
-func printAlarm(alarm types.MetricAlarm) {
-	fmt.Printf("Alarm Name: %s, State: %s, Metric: %s, Threshold: %f\n", *alarm.AlarmName, alarm.StateValue, *alarm.MetricName, *alarm.Threshold)
+	return records
 }
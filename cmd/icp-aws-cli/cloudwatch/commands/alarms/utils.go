@@ -0,0 +1,33 @@
+package alarms
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// describeAllAlarms returns every metric alarm matching the input, following all pages
+func describeAllAlarms(ctx context.Context, cwClient awsclient.CloudWatchAPI, input *cloudwatch.DescribeAlarmsInput) ([]types.MetricAlarm, error) {
+	alarms := []types.MetricAlarm{}
+	paginator := cloudwatch.NewDescribeAlarmsPaginator(cwClient, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not list alarms: %w", err)
+		}
+		alarms = append(alarms, page.MetricAlarms...)
+	}
+	return alarms, nil
+}
+
+// alarmNames returns the name of every alarm
+func alarmNames(items []types.MetricAlarm) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = *item.AlarmName
+	}
+	return names
+}
@@ -0,0 +1,28 @@
+package alarms
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
+	"icp-aws-cli/pkg/ui"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+)
+
+// AlarmsView browses the alarms in the ui command.
+func AlarmsView(clients *awsclient.AWSClientCollection) ui.View {
+	return ui.View{
+		Name: "Alarms",
+		ID:   "AlarmName",
+		List: func(ctx context.Context) (*output.Result, error) {
+			result, _, err := listAlarms(ctx, clients.CloudWatch, cloudwatch.DescribeAlarmsInput{}, nil, paging.Options{})
+			return result, err
+		},
+		Actions: []ui.Action{
+			{Key: 'd', Name: "delete", Args: func(id string) []string {
+				return []string{"cloudwatch", "delete-alarms", "--alarm-name", id}
+			}},
+		},
+	}
+}
@@ -3,6 +3,10 @@ package events
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/cmd/icp-aws-cli/cloudwatch/commands/logs/loggroups"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
@@ -10,30 +14,33 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitGetLogEventsCommand(cwLogsClient *cloudwatchlogs.Client, cloudWatchCmd *cobra.Command) {
+func InitGetLogEventsCommand(clients *awsclient.AWSClientCollection, cloudWatchCmd *cobra.Command) {
 	var logGroupName string
 	var logStreamName string
 	var limit int32
 
 	var getLogEventsCmd = &cobra.Command{
-		Use:   "get-log-events",
-		Short: "Gets the last log events from a CloudWatch log stream (10 by default)",
+		Use:         "get-log-events",
+		Short:       "Gets the last log events from a CloudWatch log stream (10 by default)",
+		Annotations: fanout.ReadOnly(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if logGroupName == "" || logStreamName == "" {
 				return fmt.Errorf("log group name and log stream name must be specified")
 			}
-			return getLogEvents(cwLogsClient, logGroupName, logStreamName, limit)
+			return getLogEvents(cmd.Context(), clients.CloudWatchLogs, logGroupName, logStreamName, limit)
 		},
 	}
 
 	getLogEventsCmd.Flags().StringVarP(&logGroupName, "log-group-name", "g", "", "Name of the log group")
 	getLogEventsCmd.Flags().StringVarP(&logStreamName, "log-stream-name", "s", "", "Name of the log stream")
 	getLogEventsCmd.Flags().Int32VarP(&limit, "limit", "l", 10, "Number of log events to get")
+	getLogEventsCmd.RegisterFlagCompletionFunc("log-group-name", loggroups.CompleteLogGroupNames(clients))
+
 	cloudWatchCmd.AddCommand(getLogEventsCmd)
 }
 
-func getLogEvents(cwLogsClient *cloudwatchlogs.Client, logGroupName, logStreamName string, limit int32) error {
-	result, err := cwLogsClient.GetLogEvents(context.TODO(), &cloudwatchlogs.GetLogEventsInput{
+func getLogEvents(ctx context.Context, cwLogsClient awsclient.CloudWatchLogsAPI, logGroupName, logStreamName string, limit int32) error {
+	result, err := cwLogsClient.GetLogEvents(ctx, &cloudwatchlogs.GetLogEventsInput{
 		LogGroupName:  &logGroupName,
 		LogStreamName: &logStreamName,
 		Limit:         &limit,
@@ -42,14 +49,14 @@ func getLogEvents(cwLogsClient *cloudwatchlogs.Client, logGroupName, logStreamNa
 		return fmt.Errorf("could not get log events: %w", err)
 	}
 
-	for _, event := range result.Events {
-		printLogEvent(event)
-	}
-
-	return nil
+	return printLogEvents(result.Events)
 }
 
-func printLogEvent(event types.OutputLogEvent) {
-	timestamp := time.Unix(0, *event.Timestamp*int64(time.Millisecond)).Format("2006-01-02 15:04:05")
-	fmt.Printf("[%s] %s\n", timestamp, *event.Message)
+func printLogEvents(events []types.OutputLogEvent) error {
+	records := output.NewResult("Timestamp", "Message")
+	for _, event := range events {
+		timestamp := time.Unix(0, *event.Timestamp*int64(time.Millisecond))
+		records.Add(timestamp, *event.Message)
+	}
+	return output.Print(records)
 }
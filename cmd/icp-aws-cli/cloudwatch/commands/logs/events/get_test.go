@@ -0,0 +1,54 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/spf13/cobra"
+)
+
+func TestGetLogEventsCommand(t *testing.T) {
+	const timestamp = 1767323045000
+	clients := fake.New()
+	clients.CloudWatchLogs.GetLogEventsFunc = func(context.Context, *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+		return &cloudwatchlogs.GetLogEventsOutput{Events: []types.OutputLogEvent{
+			{Timestamp: aws.Int64(timestamp), Message: aws.String("GET / 200")},
+		}}, nil
+	}
+
+	cloudWatchCmd := &cobra.Command{Use: "cloudwatch", SilenceUsage: true, SilenceErrors: true}
+	InitGetLogEventsCommand(clients.Collection(), cloudWatchCmd)
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+
+	cloudWatchCmd.SetArgs([]string{"get-log-events", "-g", "/app/web", "-s", "web/1"})
+	if err := cloudWatchCmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if want := output.FormatValue(time.UnixMilli(timestamp)) + "\tGET / 200\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+	wantInput := &cloudwatchlogs.GetLogEventsInput{LogGroupName: aws.String("/app/web"), LogStreamName: aws.String("web/1"), Limit: aws.Int32(10)}
+	if calls := clients.CloudWatchLogs.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, wantInput) {
+		t.Errorf("calls = %+v, want %+v", calls, wantInput)
+	}
+
+	cloudWatchCmd.SetArgs([]string{"get-log-events", "-g", "/app/web", "-s", ""})
+	if err := cloudWatchCmd.ExecuteContext(context.Background()); err == nil {
+		t.Error("error = nil, want a missing log stream name")
+	}
+}
@@ -0,0 +1,190 @@
+package loggroups
+
+import (
+	"bytes"
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/spf13/cobra"
+)
+
+const created = 1767323045000 // 2026-01-02T03:04:05Z
+
+// run executes a log group subcommand against the fakes and returns what it
+// printed as text.
+func run(t *testing.T, clients *fake.Clients, args ...string) (string, error) {
+	t.Helper()
+	cloudWatchCmd := &cobra.Command{Use: "cloudwatch", SilenceUsage: true, SilenceErrors: true}
+	collection := clients.Collection()
+	InitCreateLogGroupCommand(collection, cloudWatchCmd)
+	InitDeleteLogGroupCommand(collection, cloudWatchCmd)
+	InitListLogGroupsCommand(collection, cloudWatchCmd)
+
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+	cloudWatchCmd.SetArgs(args)
+	err := cloudWatchCmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+// newFakeLogs serves two log groups; only /app/web is tagged Team=web.
+func newFakeLogs() *fake.Clients {
+	clients := fake.New()
+	clients.CloudWatchLogs.DescribeLogGroupsFunc = func(_ context.Context, input *cloudwatchlogs.DescribeLogGroupsInput) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+		group := func(name string) types.LogGroup {
+			return types.LogGroup{
+				LogGroupName:    aws.String(name),
+				Arn:             aws.String("arn:aws:logs:us-east-1:123456789012:log-group:" + name),
+				CreationTime:    aws.Int64(created),
+				RetentionInDays: aws.Int32(14),
+				StoredBytes:     aws.Int64(2048),
+			}
+		}
+		output := &cloudwatchlogs.DescribeLogGroupsOutput{}
+		for _, name := range []string{"/app/web", "/app/api"} {
+			if strings.HasPrefix(name, aws.ToString(input.LogGroupNamePrefix)) {
+				output.LogGroups = append(output.LogGroups, group(name))
+			}
+		}
+		return output, nil
+	}
+	webTags := map[string]string{"Team": "web"}
+	clients.CloudWatchLogs.ListTagsForResourceFunc = func(_ context.Context, input *cloudwatchlogs.ListTagsForResourceInput) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+		if strings.HasSuffix(aws.ToString(input.ResourceArn), ":/app/web") {
+			return &cloudwatchlogs.ListTagsForResourceOutput{Tags: webTags}, nil
+		}
+		return &cloudwatchlogs.ListTagsForResourceOutput{}, nil
+	}
+	clients.CloudWatchLogs.ListTagsLogGroupFunc = func(_ context.Context, input *cloudwatchlogs.ListTagsLogGroupInput) (*cloudwatchlogs.ListTagsLogGroupOutput, error) {
+		if aws.ToString(input.LogGroupName) == "/app/web" {
+			return &cloudwatchlogs.ListTagsLogGroupOutput{Tags: webTags}, nil
+		}
+		return &cloudwatchlogs.ListTagsLogGroupOutput{}, nil
+	}
+	return clients
+}
+
+func TestListLogGroupsCommand(t *testing.T) {
+	row := func(name string) string {
+		return name + "\t" + output.FormatValue(time.UnixMilli(created)) + "\t14\t2048\n"
+	}
+	tests := []struct {
+		name      string
+		args      []string
+		want      string
+		wantInput *cloudwatchlogs.DescribeLogGroupsInput
+		wantErr   bool
+	}{
+		{
+			name:      "all",
+			args:      []string{"list-log-groups", "--all"},
+			want:      row("/app/web") + row("/app/api"),
+			wantInput: &cloudwatchlogs.DescribeLogGroupsInput{},
+		},
+		{
+			name:      "name",
+			args:      []string{"list-log-groups", "-n", "/app/web"},
+			want:      row("/app/web"),
+			wantInput: &cloudwatchlogs.DescribeLogGroupsInput{LogGroupNamePrefix: aws.String("/app/web")},
+		},
+		{
+			name:      "pattern",
+			args:      []string{"list-log-groups", "-p", "*/api"},
+			want:      row("/app/api"),
+			wantInput: &cloudwatchlogs.DescribeLogGroupsInput{},
+		},
+		{
+			name:      "tag",
+			args:      []string{"list-log-groups", "-k", "Team", "-v", "web"},
+			want:      row("/app/web"),
+			wantInput: &cloudwatchlogs.DescribeLogGroupsInput{},
+		},
+		{name: "no filter", args: []string{"list-log-groups"}, wantErr: true},
+		{name: "all with filter", args: []string{"list-log-groups", "--all", "-p", "web"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeLogs()
+			got, err := run(t, clients, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if calls := clients.CloudWatchLogs.Calls(); !reflect.DeepEqual(calls[0].Input, tt.wantInput) {
+				t.Errorf("input = %+v, want %+v", calls[0].Input, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestDeleteLogGroupsCommand(t *testing.T) {
+	confirm.SetAssumeYes(true)
+	t.Cleanup(func() { confirm.SetAssumeYes(false) })
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "name", args: []string{"delete-loggroups", "-n", "/app/web"}, want: []string{"/app/web"}},
+		{name: "prefix", args: []string{"delete-loggroups", "-x", "/app/"}, want: []string{"/app/web", "/app/api"}},
+		{name: "tag", args: []string{"delete-loggroups", "-k", "Team", "-v", "web"}, want: []string{"/app/web"}},
+		{name: "no filter", args: []string{"delete-loggroups"}, wantErr: true},
+		{name: "no match", args: []string{"delete-loggroups", "-n", "/app/web", "-p", "*/api"}, wantErr: true},
+		{name: "all with filter", args: []string{"delete-loggroups", "--all", "-p", "*/api"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeLogs()
+			_, err := run(t, clients, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var deleted []string
+			for _, call := range clients.CloudWatchLogs.Calls() {
+				if input, ok := call.Input.(*cloudwatchlogs.DeleteLogGroupInput); ok {
+					deleted = append(deleted, aws.ToString(input.LogGroupName))
+				}
+			}
+			if !reflect.DeepEqual(deleted, tt.want) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateLogGroupCommand(t *testing.T) {
+	clients := newFakeLogs()
+	if _, err := run(t, clients, "create-log-group", "-n", "/app/worker"); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	want := &cloudwatchlogs.CreateLogGroupInput{LogGroupName: aws.String("/app/worker")}
+	if calls := clients.CloudWatchLogs.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, want) {
+		t.Errorf("calls = %+v, want %+v", calls, want)
+	}
+
+	if _, err := run(t, newFakeLogs(), "create-log-group"); err == nil {
+		t.Error("error = nil, want a missing log group name")
+	}
+}
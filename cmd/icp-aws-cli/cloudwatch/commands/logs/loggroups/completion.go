@@ -0,0 +1,20 @@
+package loggroups
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/completion"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+// CompleteLogGroupNames suggests log group names. It is shared with the log stream and event commands.
+func CompleteLogGroupNames(clients *awsclient.AWSClientCollection) completion.Func {
+	return completion.Resources("cloudwatch-log-groups", func(ctx context.Context) ([]string, error) {
+		logGroups, err := describeAllLogGroups(ctx, clients.CloudWatchLogs, &cloudwatchlogs.DescribeLogGroupsInput{})
+		if err != nil {
+			return nil, err
+		}
+		return logGroupNames(logGroups), nil
+	})
+}
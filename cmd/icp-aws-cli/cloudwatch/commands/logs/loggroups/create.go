@@ -3,12 +3,15 @@ package loggroups
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/dryrun"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/spf13/cobra"
 )
 
-func InitCreateLogGroupCommand(cwLogsClient *cloudwatchlogs.Client, cloudWatchCmd *cobra.Command) {
+func InitCreateLogGroupCommand(clients *awsclient.AWSClientCollection, cloudWatchCmd *cobra.Command) {
 	var logGroupName string
 
 	var createLogGroupCmd = &cobra.Command{
@@ -18,7 +21,7 @@ func InitCreateLogGroupCommand(cwLogsClient *cloudwatchlogs.Client, cloudWatchCm
 			if logGroupName == "" {
 				return fmt.Errorf("log group name must be specified")
 			}
-			return createLogGroup(cwLogsClient, logGroupName)
+			return createLogGroup(cmd.Context(), clients.CloudWatchLogs, logGroupName)
 		},
 	}
 
@@ -26,10 +29,15 @@ func InitCreateLogGroupCommand(cwLogsClient *cloudwatchlogs.Client, cloudWatchCm
 	cloudWatchCmd.AddCommand(createLogGroupCmd)
 }
 
-func createLogGroup(cwLogsClient *cloudwatchlogs.Client, logGroupName string) error {
-	_, err := cwLogsClient.CreateLogGroup(context.TODO(), &cloudwatchlogs.CreateLogGroupInput{
+func createLogGroup(ctx context.Context, cwLogsClient awsclient.CloudWatchLogsAPI, logGroupName string) error {
+	if dryrun.Skip("Would create log group %s", logGroupName) {
+		return nil
+	}
+
+	_, err := cwLogsClient.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
 		LogGroupName: &logGroupName,
 	})
+	audit.Record(logGroupName, err)
 	if err != nil {
 		return fmt.Errorf("could not create log group %s: %w", logGroupName, err)
 	}
@@ -3,149 +3,79 @@ package loggroups
 import (
 	"context"
 	"fmt"
-	"icp-aws-cli/pkg/utils"
-	"regexp"
-
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/bulk"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/filter"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
-	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/spf13/cobra"
 )
 
-func InitDeleteLogGroupCommand(cwLogsClient *cloudwatchlogs.Client, cloudWatchCmd *cobra.Command) {
-	var logGroupName string
-	var prefix string
-	var pattern string
-	var tagKey string
-	var tagValue string
-	var allLogGroups bool
+func InitDeleteLogGroupCommand(clients *awsclient.AWSClientCollection, cloudWatchCmd *cobra.Command) {
+	var selector *filter.Selector
 
 	var deleteLogGroupCmd = &cobra.Command{
 		Use:   "delete-loggroups",
 		Short: "Deletes CloudWatch log groups",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if allLogGroups && (logGroupName != "" || prefix != "" || pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("the --all flag cannot be combined with other filters")
-			}
-
-			if allLogGroups {
-				if !utils.ConfirmAction() {
-					return fmt.Errorf("action cancelled by user")
-				}
-				return deleteAllLogGroups(cwLogsClient)
-			}
-
-			if logGroupName != "" && (prefix != "" || pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("log group name cannot be combined with other filters")
-			}
-
-			if logGroupName != "" {
-				return deleteLogGroupByName(cwLogsClient, logGroupName)
-			}
-
-			if prefix == "" && pattern == "" && tagKey == "" && tagValue == "" {
-				return fmt.Errorf("at least one filter must be specified")
+			expression, err := selector.Expression()
+			if err != nil {
+				return err
 			}
-
-			return deleteLogGroupsWithFilters(cwLogsClient, prefix, pattern, tagKey, tagValue)
+			return deleteLogGroupsWithFilters(cmd.Context(), clients.CloudWatchLogs, expression)
 		},
 	}
 
-	deleteLogGroupCmd.Flags().StringVarP(&logGroupName, "log-group-name", "n", "", "Log group name to filter log groups")
-	deleteLogGroupCmd.Flags().StringVarP(&prefix, "prefix", "x", "", "Prefix to filter log groups by name")
-	deleteLogGroupCmd.Flags().StringVarP(&pattern, "pattern", "p", "", "Pattern to filter log groups by name")
-	deleteLogGroupCmd.Flags().StringVarP(&tagKey, "tag-key", "k", "", "Tag key to filter log groups")
-	deleteLogGroupCmd.Flags().StringVarP(&tagValue, "tag-value", "v", "", "Tag value to filter log groups")
-	deleteLogGroupCmd.Flags().BoolVarP(&allLogGroups, "all", "a", false, "Delete all log groups")
+	selector = newLogGroupSelector(deleteLogGroupCmd)
+	deleteLogGroupCmd.RegisterFlagCompletionFunc("log-group-name", CompleteLogGroupNames(clients))
 
 	cloudWatchCmd.AddCommand(deleteLogGroupCmd)
 }
 
-func deleteAllLogGroups(cwLogsClient *cloudwatchlogs.Client) error {
-	result, err := cwLogsClient.DescribeLogGroups(context.TODO(), &cloudwatchlogs.DescribeLogGroupsInput{})
+func deleteLogGroupsWithFilters(ctx context.Context, cwLogsClient awsclient.CloudWatchLogsAPI, expression filter.Expression) error {
+	input, remaining, err := logGroupsInput(expression)
 	if err != nil {
-		return fmt.Errorf("could not list log groups: %w", err)
+		return err
 	}
 
-	for _, logGroup := range result.LogGroups {
-		_, err := cwLogsClient.DeleteLogGroup(context.TODO(), &cloudwatchlogs.DeleteLogGroupInput{
-			LogGroupName: logGroup.LogGroupName,
-		})
-		if err != nil {
-			return fmt.Errorf("could not delete log group %s: %w", *logGroup.LogGroupName, err)
-		}
-		fmt.Printf("Deleted log group %s\n", *logGroup.LogGroupName)
+	logGroups, err := describeAllLogGroups(ctx, cwLogsClient, input)
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func deleteLogGroupByName(cwLogsClient *cloudwatchlogs.Client, logGroupName string) error {
-	_, err := cwLogsClient.DeleteLogGroup(context.TODO(), &cloudwatchlogs.DeleteLogGroupInput{
-		LogGroupName: &logGroupName,
-	})
+	logGroups, err = filterLogGroups(ctx, cwLogsClient, logGroups, remaining)
 	if err != nil {
-		return fmt.Errorf("could not delete log group %s: %w", logGroupName, err)
+		return err
 	}
-
-	fmt.Printf("Deleted log group %s\n", logGroupName)
-	return nil
-}
-
-func deleteLogGroupsWithFilters(cwLogsClient *cloudwatchlogs.Client, prefix, pattern, tagKey, tagValue string) error {
-	input := &cloudwatchlogs.DescribeLogGroupsInput{}
-
-	if prefix != "" {
-		input.LogGroupNamePrefix = &prefix
+	if len(logGroups) == 0 {
+		return fmt.Errorf("no log groups found with the specified filters")
 	}
 
-	result, err := cwLogsClient.DescribeLogGroups(context.TODO(), input)
-	if err != nil {
-		return fmt.Errorf("could not list log groups: %w", err)
-	}
+	return deleteLogGroups(ctx, cwLogsClient, logGroupNames(logGroups))
+}
 
-	var logGroups []types.LogGroup
-	if pattern != "" {
-		re, err := regexp.Compile(pattern)
-		if err != nil {
-			return fmt.Errorf("invalid pattern: %w", err)
-		}
-		for _, logGroup := range result.LogGroups {
-			if re.MatchString(*logGroup.LogGroupName) {
-				logGroups = append(logGroups, logGroup)
-			}
-		}
-	} else {
-		logGroups = result.LogGroups
+// deleteLogGroups asks for confirmation and deletes the given log groups one by one
+func deleteLogGroups(ctx context.Context, cwLogsClient awsclient.CloudWatchLogsAPI, names []string) error {
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "delete", Kind: "log group", Targets: names}); err != nil {
+		return err
 	}
 
-	if tagKey != "" && tagValue != "" {
-		var filteredLogGroups []types.LogGroup
-		for _, logGroup := range logGroups {
-			tags, err := cwLogsClient.ListTagsLogGroup(context.TODO(), &cloudwatchlogs.ListTagsLogGroupInput{
-				LogGroupName: logGroup.LogGroupName,
-			})
-			if err != nil {
-				return fmt.Errorf("could not list tags for log group %s: %w", *logGroup.LogGroupName, err)
-			}
-			for key, value := range tags.Tags {
-				if key == tagKey && value == tagValue {
-					filteredLogGroups = append(filteredLogGroups, logGroup)
-					break
-				}
-			}
+	return bulk.Run(ctx, "deleted", "log group", names, func(ctx context.Context, name string) error {
+		if dryrun.Skip("Would delete log group %s", name) {
+			return nil
 		}
-		logGroups = filteredLogGroups
-	}
-
-	for _, logGroup := range logGroups {
-		_, err := cwLogsClient.DeleteLogGroup(context.TODO(), &cloudwatchlogs.DeleteLogGroupInput{
-			LogGroupName: logGroup.LogGroupName,
+		_, err := cwLogsClient.DeleteLogGroup(ctx, &cloudwatchlogs.DeleteLogGroupInput{
+			LogGroupName: aws.String(name),
 		})
+		audit.Record(name, err)
 		if err != nil {
-			return fmt.Errorf("could not delete log group %s: %w", *logGroup.LogGroupName, err)
+			return fmt.Errorf("could not delete log group %s: %w", name, err)
 		}
-		fmt.Printf("Deleted log group %s\n", *logGroup.LogGroupName)
-	}
-
-	return nil
+		fmt.Printf("Deleted log group %s\n", name)
+		return nil
+	})
 }
@@ -0,0 +1,103 @@
+package loggroups
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/filter"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/spf13/cobra"
+)
+
+// newLogGroupSelector registers the selection flags shared by the log group commands
+func newLogGroupSelector(cmd *cobra.Command) *filter.Selector {
+	selector := filter.NewSelector(cmd, "log groups")
+	selector.Shorthand(cmd, "log-group-name", "n", "Log group name to filter log groups (same as --filter name=NAME)", filter.Is("name"))
+	selector.Shorthand(cmd, "prefix", "x", "Prefix to filter log groups by name (same as --filter name=PREFIX*)", func(prefix string) string {
+		return "name=" + prefix + "*"
+	})
+	selector.Shorthand(cmd, "pattern", "p", "Name glob to filter log groups, e.g. /aws/lambda/* (same as --filter name=PATTERN)", filter.Is("name"))
+	selector.TagFlags(cmd)
+	return selector
+}
+
+// logGroupsInput translates a name condition into the prefix DescribeLogGroups
+// supports, returning the conditions left to evaluate client-side
+func logGroupsInput(expression filter.Expression) (*cloudwatchlogs.DescribeLogGroupsInput, filter.Expression, error) {
+	input := &cloudwatchlogs.DescribeLogGroupsInput{}
+	remaining := filter.Expression{}
+	for _, condition := range expression {
+		if condition.Key == "name" && input.LogGroupNamePrefix == nil {
+			if prefix, ok := condition.Prefix(); ok {
+				input.LogGroupNamePrefix = aws.String(prefix)
+				continue
+			}
+			// An exact name narrows the listing to its prefix but must still match exactly
+			if condition.Exact() && len(condition.Values) == 1 {
+				input.LogGroupNamePrefix = aws.String(condition.Values[0])
+			}
+		}
+		remaining = append(remaining, condition)
+	}
+
+	if err := remaining.CheckKeys("name", "retention", "class"); err != nil {
+		return nil, nil, err
+	}
+	return input, remaining, nil
+}
+
+// filterLogGroups keeps the log groups satisfying the client-side conditions. Tags
+// are only looked up, one call per log group, when a condition needs them.
+func filterLogGroups(ctx context.Context, cwLogsClient awsclient.CloudWatchLogsAPI, logGroups []types.LogGroup, expression filter.Expression) ([]types.LogGroup, error) {
+	if len(expression) == 0 {
+		return logGroups, nil
+	}
+
+	matched := []types.LogGroup{}
+	for _, logGroup := range logGroups {
+		tags := map[string]string{}
+		if expression.HasTags() {
+			// The tagging API expects the log group ARN without the trailing ":*" returned by DescribeLogGroups
+			output, err := cwLogsClient.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{
+				ResourceArn: aws.String(strings.TrimSuffix(aws.ToString(logGroup.Arn), ":*")),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("could not list tags for log group %s: %w", *logGroup.LogGroupName, err)
+			}
+			tags = output.Tags
+		}
+
+		if expression.Match(logGroupFields(logGroup, tags)) {
+			matched = append(matched, logGroup)
+		}
+	}
+	return matched, nil
+}
+
+// logGroupFields resolves the filter keys of a log group
+func logGroupFields(logGroup types.LogGroup, tags map[string]string) filter.Fields {
+	return func(key string) (string, bool) {
+		if tagKey, ok := filter.TagKey(key); ok {
+			value, ok := tags[tagKey]
+			return value, ok
+		}
+
+		switch key {
+		case "name":
+			return aws.ToString(logGroup.LogGroupName), true
+		case "retention":
+			if logGroup.RetentionInDays == nil {
+				return "", false
+			}
+			return strconv.Itoa(int(*logGroup.RetentionInDays)), true
+		case "class":
+			return string(logGroup.LogGroupClass), logGroup.LogGroupClass != ""
+		}
+		return "", false
+	}
+}
@@ -3,134 +3,84 @@ package loggroups
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/filter"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/spf13/cobra"
 )
 
-func InitListLogGroupsCommand(cwClient *cloudwatchlogs.Client, cloudWatchCmd *cobra.Command) {
-	var logGroupName string
-	var pattern string
-	var tagKey string
-	var tagValue string
-	var allLogs bool
+func InitListLogGroupsCommand(clients *awsclient.AWSClientCollection, cloudWatchCmd *cobra.Command) {
+	var selector *filter.Selector
+	var pagingOpts paging.Options
 
 	var listLogsCmd = &cobra.Command{
-		Use:   "list-log-groups",
-		Short: "Lists CloudWatch log groups",
+		Use:         "list-log-groups",
+		Short:       "Lists CloudWatch log groups",
+		Annotations: fanout.ReadOnly(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if allLogs && (logGroupName != "" || pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("the --all flag cannot be combined with other filters")
-			}
-
-			if allLogs {
-				return listAllLogs(cwClient)
-			}
-
-			if logGroupName != "" && (pattern != "" || tagKey != "" || tagValue != "") {
-				return fmt.Errorf("log group name cannot be combined with other filters")
-			}
-
-			if logGroupName != "" {
-				return listLogsByName(cwClient, logGroupName)
+			expression, err := selector.Expression()
+			if err != nil {
+				return err
 			}
-
-			if pattern == "" && tagKey == "" && tagValue == "" {
-				return fmt.Errorf("at least one filter must be specified")
+			result, token, err := listLogGroups(cmd.Context(), clients.CloudWatchLogs, expression, pagingOpts)
+			if err != nil {
+				return err
 			}
-
-			return listLogsWithFilters(cwClient, pattern, tagKey, tagValue)
+			paging.PrintNextToken(token)
+			return output.Print(result)
 		},
 	}
 
-	listLogsCmd.Flags().StringVarP(&logGroupName, "log-group-name", "n", "", "Log group name to filter logs")
-	listLogsCmd.Flags().StringVarP(&pattern, "pattern", "p", "", "Pattern to filter logs by name")
-	listLogsCmd.Flags().StringVarP(&tagKey, "tag-key", "k", "", "Tag key to filter logs")
-	listLogsCmd.Flags().StringVarP(&tagValue, "tag-value", "v", "", "Tag value to filter logs")
-	listLogsCmd.Flags().BoolVarP(&allLogs, "all", "a", false, "List all logs")
+	selector = newLogGroupSelector(listLogsCmd)
+	paging.AddFlags(listLogsCmd, &pagingOpts, paging.Range{Min: 1, Max: 50})
+	listLogsCmd.RegisterFlagCompletionFunc("log-group-name", CompleteLogGroupNames(clients))
 
 	cloudWatchCmd.AddCommand(listLogsCmd)
 }
 
-func listAllLogs(cwClient *cloudwatchlogs.Client) error {
-	result, err := cwClient.DescribeLogGroups(context.TODO(), &cloudwatchlogs.DescribeLogGroupsInput{})
-	if err != nil {
-		return fmt.Errorf("could not list log groups: %w", err)
-	}
-
-	for _, logGroup := range result.LogGroups {
-		printLogGroup(logGroup)
-	}
-
-	return nil
-}
-
-func listLogsByName(cwClient *cloudwatchlogs.Client, logGroupName string) error {
-	result, err := cwClient.DescribeLogGroups(context.TODO(), &cloudwatchlogs.DescribeLogGroupsInput{
-		LogGroupNamePrefix: &logGroupName,
-	})
+// listLogGroups returns the log groups matching the expression, applying the client-side conditions page by page
+func listLogGroups(ctx context.Context, cwClient awsclient.CloudWatchLogsAPI, expression filter.Expression, pagingOpts paging.Options) (*output.Result, string, error) {
+	input, remaining, err := logGroupsInput(expression)
 	if err != nil {
-		return fmt.Errorf("could not list log groups: %w", err)
-	}
-
-	for _, logGroup := range result.LogGroups {
-		printLogGroup(logGroup)
+		return nil, "", err
 	}
 
-	return nil
-}
-
-func listLogsWithFilters(cwClient *cloudwatchlogs.Client, pattern, tagKey, tagValue string) error {
-	result, err := cwClient.DescribeLogGroups(context.TODO(), &cloudwatchlogs.DescribeLogGroupsInput{})
+	collector, err := paging.NewCollector[types.LogGroup](pagingOpts)
 	if err != nil {
-		return fmt.Errorf("could not list log groups: %w", err)
+		return nil, "", err
 	}
 
-	var logGroups []types.LogGroup
-	if pattern != "" {
-		re, err := regexp.Compile(pattern)
+	input.NextToken = collector.StartToken()
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(cwClient, input, func(o *cloudwatchlogs.DescribeLogGroupsPaginatorOptions) {
+		o.Limit = pagingOpts.PageSize
+	})
+	for paginator.HasMorePages() && !collector.Done() {
+		page, err := paginator.NextPage(ctx)
 		if err != nil {
-			return fmt.Errorf("invalid pattern: %w", err)
+			return nil, "", fmt.Errorf("could not list log groups: %w", err)
 		}
-		for _, logGroup := range result.LogGroups {
-			if re.MatchString(*logGroup.LogGroupName) {
-				logGroups = append(logGroups, logGroup)
-			}
+		logGroups, err := filterLogGroups(ctx, cwClient, page.LogGroups, remaining)
+		if err != nil {
+			return nil, "", err
 		}
-	} else {
-		logGroups = result.LogGroups
+		collector.Add(logGroups, page.NextToken)
 	}
 
-	if tagKey != "" && tagValue != "" {
-		var filteredLogGroups []types.LogGroup
-		for _, logGroup := range logGroups {
-			tags, err := cwClient.ListTagsForResource(context.TODO(), &cloudwatchlogs.ListTagsForResourceInput{
-				ResourceArn: logGroup.Arn,
-			})
-			if err != nil {
-				return fmt.Errorf("could not list tags for log group %s: %w", *logGroup.LogGroupName, err)
-			}
-			for key, value := range tags.Tags {
-				if key == tagKey && value == tagValue {
-					filteredLogGroups = append(filteredLogGroups, logGroup)
-					break
-				}
-			}
-		}
-		logGroups = filteredLogGroups
-	}
+	return logGroupRecords(collector.Items()), collector.NextToken(), nil
+}
 
+func logGroupRecords(logGroups []types.LogGroup) *output.Result {
+	records := output.NewResult("LogGroupName", "CreationTime", "RetentionInDays", "StoredBytes")
 	for _, logGroup := range logGroups {
-		printLogGroup(logGroup)
+		creationTime := time.Unix(0, *logGroup.CreationTime*int64(time.Millisecond))
+		records.Add(*logGroup.LogGroupName, creationTime, aws.ToInt32(logGroup.RetentionInDays), aws.ToInt64(logGroup.StoredBytes))
 	}
-
-	return nil
-}
-
-func printLogGroup(logGroup types.LogGroup) {
-	creationTime := time.Unix(0, *logGroup.CreationTime*int64(time.Millisecond)).Format("2006-01-02 15:04:05")
-	fmt.Printf("Log Group Name: %s, Creation Time: %s\n", *logGroup.LogGroupName, creationTime)
+	return records
 }
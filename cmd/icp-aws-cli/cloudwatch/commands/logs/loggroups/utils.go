@@ -0,0 +1,33 @@
+package loggroups
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// describeAllLogGroups returns every log group matching the input, following all pages
+func describeAllLogGroups(ctx context.Context, cwLogsClient awsclient.CloudWatchLogsAPI, input *cloudwatchlogs.DescribeLogGroupsInput) ([]types.LogGroup, error) {
+	logGroups := []types.LogGroup{}
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(cwLogsClient, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not list log groups: %w", err)
+		}
+		logGroups = append(logGroups, page.LogGroups...)
+	}
+	return logGroups, nil
+}
+
+// logGroupNames returns the name of every log group
+func logGroupNames(items []types.LogGroup) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = *item.LogGroupName
+	}
+	return names
+}
@@ -0,0 +1,26 @@
+package loggroups
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
+	"icp-aws-cli/pkg/ui"
+)
+
+// LogGroupsView browses the log groups in the ui command.
+func LogGroupsView(clients *awsclient.AWSClientCollection) ui.View {
+	return ui.View{
+		Name: "Log groups",
+		ID:   "LogGroupName",
+		List: func(ctx context.Context) (*output.Result, error) {
+			result, _, err := listLogGroups(ctx, clients.CloudWatchLogs, nil, paging.Options{})
+			return result, err
+		},
+		Actions: []ui.Action{
+			{Key: 'd', Name: "delete", Args: func(id string) []string {
+				return []string{"cloudwatch", "delete-loggroups", "--log-group-name", id}
+			}},
+		},
+	}
+}
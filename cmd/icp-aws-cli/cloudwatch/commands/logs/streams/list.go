@@ -3,6 +3,10 @@ package streams
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/cmd/icp-aws-cli/cloudwatch/commands/logs/loggroups"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
@@ -10,28 +14,31 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitListLogStreamsCommand(cwLogsClient *cloudwatchlogs.Client, cloudWatchCmd *cobra.Command) {
+func InitListLogStreamsCommand(clients *awsclient.AWSClientCollection, cloudWatchCmd *cobra.Command) {
 	var logGroupName string
 	var limit int32
 
 	var listLogStreamsCmd = &cobra.Command{
-		Use:   "list-log-streams",
-		Short: "Lists the last CloudWatch log streams in a log group (10 by default)",
+		Use:         "list-log-streams",
+		Short:       "Lists the last CloudWatch log streams in a log group (10 by default)",
+		Annotations: fanout.ReadOnly(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if logGroupName == "" {
 				return fmt.Errorf("log group name must be specified")
 			}
-			return listLogStreams(cwLogsClient, logGroupName, limit)
+			return listLogStreams(cmd.Context(), clients.CloudWatchLogs, logGroupName, limit)
 		},
 	}
 
 	listLogStreamsCmd.Flags().StringVarP(&logGroupName, "log-group-name", "n", "", "Name of the log group")
 	listLogStreamsCmd.Flags().Int32VarP(&limit, "limit", "l", 10, "Number of log streams to list")
+	listLogStreamsCmd.RegisterFlagCompletionFunc("log-group-name", loggroups.CompleteLogGroupNames(clients))
+
 	cloudWatchCmd.AddCommand(listLogStreamsCmd)
 }
 
-func listLogStreams(cwLogsClient *cloudwatchlogs.Client, logGroupName string, limit int32) error {
-	result, err := cwLogsClient.DescribeLogStreams(context.TODO(), &cloudwatchlogs.DescribeLogStreamsInput{
+func listLogStreams(ctx context.Context, cwLogsClient awsclient.CloudWatchLogsAPI, logGroupName string, limit int32) error {
+	result, err := cwLogsClient.DescribeLogStreams(ctx, &cloudwatchlogs.DescribeLogStreamsInput{
 		LogGroupName: &logGroupName,
 		Limit:        &limit,
 	})
@@ -39,14 +46,18 @@ func listLogStreams(cwLogsClient *cloudwatchlogs.Client, logGroupName string, li
 		return fmt.Errorf("could not list log streams: %w", err)
 	}
 
-	for _, logStream := range result.LogStreams {
-		printLogStream(logStream)
-	}
-
-	return nil
+	return printLogStreams(result.LogStreams)
 }
 
-func printLogStream(logStream types.LogStream) {
-	creationTime := time.Unix(0, *logStream.CreationTime*int64(time.Millisecond)).Format("2006-01-02 15:04:05")
-	fmt.Printf("Log Stream Name: %s, Creation Time: %s, Last Event Time: %s\n", *logStream.LogStreamName, creationTime, time.Unix(0, *logStream.LastEventTimestamp*int64(time.Millisecond)).Format("2006-01-02 15:04:05"))
+func printLogStreams(logStreams []types.LogStream) error {
+	records := output.NewResult("LogStreamName", "CreationTime", "LastEventTime")
+	for _, logStream := range logStreams {
+		creationTime := time.Unix(0, *logStream.CreationTime*int64(time.Millisecond))
+		var lastEventTime time.Time
+		if logStream.LastEventTimestamp != nil {
+			lastEventTime = time.Unix(0, *logStream.LastEventTimestamp*int64(time.Millisecond))
+		}
+		records.Add(*logStream.LogStreamName, creationTime, lastEventTime)
+	}
+	return output.Print(records)
 }
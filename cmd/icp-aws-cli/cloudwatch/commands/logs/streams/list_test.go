@@ -0,0 +1,53 @@
+package streams
+
+import (
+	"bytes"
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/spf13/cobra"
+)
+
+func TestListLogStreamsCommand(t *testing.T) {
+	const created = 1767323045000
+	clients := fake.New()
+	clients.CloudWatchLogs.DescribeLogStreamsFunc = func(context.Context, *cloudwatchlogs.DescribeLogStreamsInput) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+		return &cloudwatchlogs.DescribeLogStreamsOutput{LogStreams: []types.LogStream{
+			{LogStreamName: aws.String("web/1"), CreationTime: aws.Int64(created), LastEventTimestamp: aws.Int64(created + 1000)},
+			{LogStreamName: aws.String("web/2"), CreationTime: aws.Int64(created)},
+		}}, nil
+	}
+
+	cloudWatchCmd := &cobra.Command{Use: "cloudwatch", SilenceUsage: true, SilenceErrors: true}
+	InitListLogStreamsCommand(clients.Collection(), cloudWatchCmd)
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+
+	cloudWatchCmd.SetArgs([]string{"list-log-streams", "-n", "/app/web", "-l", "2"})
+	if err := cloudWatchCmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	createdAt := output.FormatValue(time.UnixMilli(created))
+	want := "web/1\t" + createdAt + "\t" + output.FormatValue(time.UnixMilli(created+1000)) + "\n" +
+		"web/2\t" + createdAt + "\t\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	wantInput := &cloudwatchlogs.DescribeLogStreamsInput{LogGroupName: aws.String("/app/web"), Limit: aws.Int32(2)}
+	if calls := clients.CloudWatchLogs.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, wantInput) {
+		t.Errorf("calls = %+v, want %+v", calls, wantInput)
+	}
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/spf13/cobra"
+)
+
+// run executes a metric subcommand against the fakes and returns what it
+// printed as text.
+func run(t *testing.T, clients *fake.Clients, args ...string) (string, error) {
+	t.Helper()
+	cloudWatchCmd := &cobra.Command{Use: "cloudwatch", SilenceUsage: true, SilenceErrors: true}
+	collection := clients.Collection()
+	InitCreateMetricCommand(collection, cloudWatchCmd)
+	InitDeleteMetricCommand(collection, cloudWatchCmd)
+	InitListMetricsCommand(collection, cloudWatchCmd)
+
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+	cloudWatchCmd.SetArgs(args)
+	err := cloudWatchCmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func newFakeCloudWatch() *fake.Clients {
+	clients := fake.New()
+	clients.CloudWatch.ListMetricsFunc = func(context.Context, *cloudwatch.ListMetricsInput) (*cloudwatch.ListMetricsOutput, error) {
+		metric := func(name string) types.Metric {
+			return types.Metric{
+				MetricName: aws.String(name),
+				Namespace:  aws.String("AWS/EC2"),
+				Dimensions: []types.Dimension{{Name: aws.String("InstanceId"), Value: aws.String("i-1")}},
+			}
+		}
+		return &cloudwatch.ListMetricsOutput{Metrics: []types.Metric{metric("CPUUtilization"), metric("NetworkIn")}}, nil
+	}
+	return clients
+}
+
+func TestListMetricsCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		want      string
+		wantInput *cloudwatch.ListMetricsInput
+		wantErr   bool
+	}{
+		{
+			name:      "all",
+			args:      []string{"list-metrics", "--all"},
+			want:      "CPUUtilization\tAWS/EC2\tInstanceId=i-1\nNetworkIn\tAWS/EC2\tInstanceId=i-1\n",
+			wantInput: &cloudwatch.ListMetricsInput{},
+		},
+		{
+			name: "namespace, dimension and pattern",
+			args: []string{"list-metrics", "-s", "AWS/EC2", "-d", "InstanceId", "-v", "i-1", "-p", "CPU*"},
+			want: "CPUUtilization\tAWS/EC2\tInstanceId=i-1\n",
+			wantInput: &cloudwatch.ListMetricsInput{
+				Namespace:  aws.String("AWS/EC2"),
+				Dimensions: []types.DimensionFilter{{Name: aws.String("InstanceId"), Value: aws.String("i-1")}},
+			},
+		},
+		{name: "no filter", args: []string{"list-metrics"}, wantErr: true},
+		{name: "all with filter", args: []string{"list-metrics", "--all", "-s", "AWS/EC2"}, wantErr: true},
+		{name: "dimension name without value", args: []string{"list-metrics", "-d", "InstanceId"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeCloudWatch()
+			got, err := run(t, clients, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if calls := clients.CloudWatch.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, tt.wantInput) {
+				t.Errorf("calls = %+v, want %+v", calls, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestDeleteMetricsCommand(t *testing.T) {
+	confirm.SetAssumeYes(true)
+	t.Cleanup(func() { confirm.SetAssumeYes(false) })
+
+	clients := newFakeCloudWatch()
+	if _, err := run(t, clients, "delete-metrics", "-p", "Network*"); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	var deleted []string
+	for _, call := range clients.CloudWatch.Calls() {
+		if input, ok := call.Input.(*cloudwatch.DeleteAlarmsInput); ok {
+			deleted = append(deleted, input.AlarmNames...)
+		}
+	}
+	if want := []string{"NetworkIn"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleted = %v, want %v", deleted, want)
+	}
+
+	if _, err := run(t, newFakeCloudWatch(), "delete-metrics"); err == nil || !strings.Contains(err.Error(), "at least one filter") {
+		t.Errorf("error = %v, want a missing filter", err)
+	}
+}
+
+func TestCreateMetricCommand(t *testing.T) {
+	clients := newFakeCloudWatch()
+	if _, err := run(t, clients, "create-metric", "-n", "Queue", "-s", "App", "-d", "Service", "-v", "web"); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	want := &cloudwatch.PutMetricDataInput{
+		Namespace: aws.String("App"),
+		MetricData: []types.MetricDatum{{
+			MetricName: aws.String("Queue"),
+			Dimensions: []types.Dimension{{Name: aws.String("Service"), Value: aws.String("web")}},
+			Value:      aws.Float64(0),
+		}},
+	}
+	if calls := clients.CloudWatch.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, want) {
+		t.Errorf("calls = %+v, want %+v", calls, want)
+	}
+
+	if _, err := run(t, newFakeCloudWatch(), "create-metric", "-n", "Queue"); err == nil {
+		t.Error("error = nil, want missing flags")
+	}
+}
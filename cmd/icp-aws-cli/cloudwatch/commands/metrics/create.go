@@ -3,6 +3,9 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/dryrun"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
@@ -10,7 +13,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitCreateMetricCommand(cwClient *cloudwatch.Client, cloudWatchCmd *cobra.Command) {
+func InitCreateMetricCommand(clients *awsclient.AWSClientCollection, cloudWatchCmd *cobra.Command) {
 	var metricName string
 	var namespace string
 	var dimensionName string
@@ -23,7 +26,7 @@ func InitCreateMetricCommand(cwClient *cloudwatch.Client, cloudWatchCmd *cobra.C
 			if metricName == "" || namespace == "" || dimensionName == "" || dimensionValue == "" {
 				return fmt.Errorf("metric name, namespace, dimension name, and dimension value must be specified")
 			}
-			return createMetric(cwClient, metricName, namespace, dimensionName, dimensionValue)
+			return createMetric(cmd.Context(), clients.CloudWatch, metricName, namespace, dimensionName, dimensionValue)
 		},
 	}
 
@@ -34,8 +37,12 @@ func InitCreateMetricCommand(cwClient *cloudwatch.Client, cloudWatchCmd *cobra.C
 	cloudWatchCmd.AddCommand(createMetricCmd)
 }
 
-func createMetric(cwClient *cloudwatch.Client, metricName, namespace, dimensionName, dimensionValue string) error {
-	_, err := cwClient.PutMetricData(context.TODO(), &cloudwatch.PutMetricDataInput{
+func createMetric(ctx context.Context, cwClient awsclient.CloudWatchAPI, metricName, namespace, dimensionName, dimensionValue string) error {
+	if dryrun.Skip("Would create metric %s in namespace %s", metricName, namespace) {
+		return nil
+	}
+
+	_, err := cwClient.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
 		Namespace: &namespace,
 		MetricData: []types.MetricDatum{
 			{
@@ -50,6 +57,7 @@ func createMetric(cwClient *cloudwatch.Client, metricName, namespace, dimensionN
 			},
 		},
 	})
+	audit.Record(namespace+"/"+metricName, err)
 	if err != nil {
 		return fmt.Errorf("could not create metric %s: %w", metricName, err)
 	}
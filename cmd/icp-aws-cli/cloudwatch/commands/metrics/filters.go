@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"fmt"
+	"icp-aws-cli/pkg/filter"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/spf13/cobra"
+)
+
+// dimensionPrefix starts the keys selecting a dimension value, e.g. dimension:InstanceId=i-123
+const dimensionPrefix = "dimension:"
+
+// newMetricSelector registers the selection flags shared by the metric commands
+func newMetricSelector(cmd *cobra.Command) *filter.Selector {
+	selector := filter.NewSelector(cmd, "metrics")
+	selector.Shorthand(cmd, "metric-name", "n", "Metric name to filter metrics (same as --filter name=NAME)", filter.Is("name"))
+	selector.Shorthand(cmd, "prefix", "x", "Prefix to filter metrics by name (same as --filter name=PREFIX*)", func(prefix string) string {
+		return "name=" + prefix + "*"
+	})
+	selector.Shorthand(cmd, "pattern", "p", "Name glob to filter metrics, e.g. *Utilization (same as --filter name=PATTERN)", filter.Is("name"))
+	selector.Shorthand(cmd, "namespace", "s", "Namespace to filter metrics (same as --filter namespace=NAMESPACE)", filter.Is("namespace"))
+	selector.Pair(cmd, "dimension-name", "d", "dimension-value", "v", dimensionPrefix, "Dimension")
+	return selector
+}
+
+// metricsInput translates the exact single-valued conditions into a ListMetrics
+// input, returning the conditions left to evaluate client-side
+func metricsInput(expression filter.Expression) (*cloudwatch.ListMetricsInput, filter.Expression, error) {
+	if err := checkMetricKeys(expression); err != nil {
+		return nil, nil, err
+	}
+
+	input := &cloudwatch.ListMetricsInput{}
+	remaining := filter.Expression{}
+	for _, condition := range expression {
+		if !condition.Exact() || len(condition.Values) != 1 {
+			remaining = append(remaining, condition)
+			continue
+		}
+		value := condition.Values[0]
+		dimension, isDimension := strings.CutPrefix(condition.Key, dimensionPrefix)
+		switch {
+		case condition.Key == "name" && input.MetricName == nil:
+			input.MetricName = aws.String(value)
+		case condition.Key == "namespace" && input.Namespace == nil:
+			input.Namespace = aws.String(value)
+		case isDimension:
+			input.Dimensions = append(input.Dimensions, types.DimensionFilter{Name: aws.String(dimension), Value: aws.String(value)})
+		default:
+			remaining = append(remaining, condition)
+		}
+	}
+	return input, remaining, nil
+}
+
+// filterMetrics keeps the metrics satisfying the client-side conditions
+func filterMetrics(metrics []types.Metric, expression filter.Expression) []types.Metric {
+	if len(expression) == 0 {
+		return metrics
+	}
+	matched := []types.Metric{}
+	for _, metric := range metrics {
+		if expression.Match(metricFields(metric)) {
+			matched = append(matched, metric)
+		}
+	}
+	return matched
+}
+
+// metricFields resolves the filter keys of a metric
+func metricFields(metric types.Metric) filter.Fields {
+	return func(key string) (string, bool) {
+		if dimension, ok := strings.CutPrefix(key, dimensionPrefix); ok {
+			for _, d := range metric.Dimensions {
+				if aws.ToString(d.Name) == dimension {
+					return aws.ToString(d.Value), true
+				}
+			}
+			return "", false
+		}
+
+		switch key {
+		case "name":
+			return aws.ToString(metric.MetricName), true
+		case "namespace":
+			return aws.ToString(metric.Namespace), true
+		}
+		return "", false
+	}
+}
+
+// checkMetricKeys rejects keys metrics do not have. Metrics carry no tags.
+func checkMetricKeys(expression filter.Expression) error {
+	for _, condition := range expression {
+		if condition.Key == "name" || condition.Key == "namespace" || strings.HasPrefix(condition.Key, dimensionPrefix) {
+			continue
+		}
+		return fmt.Errorf("unsupported filter %q (supported keys: name, namespace, dimension:<name>)", condition.String())
+	}
+	return nil
+}
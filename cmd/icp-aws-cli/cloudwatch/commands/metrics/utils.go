@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// describeAllMetrics returns every metric matching the input, following all pages
+func describeAllMetrics(ctx context.Context, cwClient awsclient.CloudWatchAPI, input *cloudwatch.ListMetricsInput) ([]types.Metric, error) {
+	metrics := []types.Metric{}
+	paginator := cloudwatch.NewListMetricsPaginator(cwClient, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not list metrics: %w", err)
+		}
+		metrics = append(metrics, page.Metrics...)
+	}
+	return metrics, nil
+}
+
+// metricNames returns the name of every metric
+func metricNames(items []types.Metric) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = *item.MetricName
+	}
+	return names
+}
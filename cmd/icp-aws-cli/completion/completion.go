@@ -0,0 +1,44 @@
+package completion
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func InitCommands() *cobra.Command {
+	completionCmd := &cobra.Command{
+		Use:   "completion [bash|zsh|fish]",
+		Short: "Generates the shell completion script",
+		Long: `Generates the completion script for bash, zsh or fish.
+
+Resource identifiers (instance IDs, bucket, table, log group, AutoScaling group
+and alarm names) are completed from the AWS account selected by --profile and
+--region, and cached for a minute.
+
+  bash: source <(icp-aws-cli completion bash)
+  zsh:  icp-aws-cli completion zsh > "${fpath[1]}/_icp-aws-cli"
+  fish: icp-aws-cli completion fish > ~/.config/fish/completions/icp-aws-cli.fish`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		// Generating the script needs no AWS clients
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return cmd.Root().GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return cmd.Root().GenZshCompletion(os.Stdout)
+			case "fish":
+				return cmd.Root().GenFishCompletion(os.Stdout, true)
+			default:
+				return fmt.Errorf("unsupported shell %q (valid values: bash, zsh, fish)", args[0])
+			}
+		},
+	}
+
+	return completionCmd
+}
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/spf13/cobra"
+)
+
+// run executes a dynamodb subcommand against the fakes and returns what it
+// printed as text.
+func run(t *testing.T, clients *fake.Clients, args ...string) (string, error) {
+	t.Helper()
+	dynamodbCmd := &cobra.Command{Use: "dynamodb", SilenceUsage: true, SilenceErrors: true}
+	collection := clients.Collection()
+	InitCreateCommands(collection, dynamodbCmd)
+	InitDeleteCommands(collection, dynamodbCmd)
+	InitDescribeCommands(collection, dynamodbCmd)
+	InitItemCommands(collection, dynamodbCmd)
+	InitListCommands(collection, dynamodbCmd)
+	InitQueryCommands(collection, dynamodbCmd)
+
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+	dynamodbCmd.SetArgs(args)
+	err := dynamodbCmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func newFakeDynamoDB() *fake.Clients {
+	clients := fake.New()
+	clients.DynamoDB.ListTablesFunc = func(context.Context, *dynamodb.ListTablesInput) (*dynamodb.ListTablesOutput, error) {
+		return &dynamodb.ListTablesOutput{TableNames: []string{"orders", "users"}}, nil
+	}
+	clients.DynamoDB.DescribeTableFunc = func(_ context.Context, in *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+		return &dynamodb.DescribeTableOutput{Table: &types.TableDescription{
+			TableName: in.TableName, TableStatus: types.TableStatusActive, ItemCount: aws.Int64(42),
+		}}, nil
+	}
+	clients.DynamoDB.GetItemFunc = func(context.Context, *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		return &dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{
+			"id":   &types.AttributeValueMemberN{Value: "1"},
+			"name": &types.AttributeValueMemberS{Value: "Ada"},
+		}}, nil
+	}
+	clients.DynamoDB.QueryFunc = func(context.Context, *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{
+			{"id": &types.AttributeValueMemberN{Value: "1"}, "total": &types.AttributeValueMemberN{Value: "9.5"}},
+			{"id": &types.AttributeValueMemberN{Value: "1"}, "total": &types.AttributeValueMemberN{Value: "12"}},
+		}}, nil
+	}
+	return clients
+}
+
+func TestReadCommands(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"list"}, want: "orders\nusers\n"},
+		{args: []string{"describe", "orders"}, want: "orders\tACTIVE\t42\n"},
+		{args: []string{"getItem", "users", `{"id": 1}`}, want: "1\tAda\n"},
+		{args: []string{"query", "orders", "id = :id", `{":id": 1}`}, want: "1\t9.5\n1\t12\n"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := run(t, newFakeDynamoDB(), tt.args...)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryInput(t *testing.T) {
+	clients := newFakeDynamoDB()
+	if _, err := run(t, clients, "query", "orders", "id = :id", `{":id": "a-1"}`); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	want := &dynamodb.QueryInput{
+		TableName:                 aws.String("orders"),
+		KeyConditionExpression:    aws.String("id = :id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":id": &types.AttributeValueMemberS{Value: "a-1"}},
+	}
+	if calls := clients.DynamoDB.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, want) {
+		t.Errorf("calls = %+v, want Query %+v", calls, want)
+	}
+}
+
+func TestMutatingCommands(t *testing.T) {
+	confirm.SetAssumeYes(true)
+	t.Cleanup(func() { confirm.SetAssumeYes(false) })
+
+	key := map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "a-1"}}
+	tests := []struct {
+		args      []string
+		wantCall  string
+		wantInput interface{}
+	}{
+		{
+			args:     []string{"createTable", "orders", "id", "S"},
+			wantCall: "CreateTable",
+			wantInput: &dynamodb.CreateTableInput{
+				TableName:             aws.String("orders"),
+				AttributeDefinitions:  []types.AttributeDefinition{{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS}},
+				KeySchema:             []types.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash}},
+				ProvisionedThroughput: &types.ProvisionedThroughput{ReadCapacityUnits: aws.Int64(5), WriteCapacityUnits: aws.Int64(5)},
+			},
+		},
+		{
+			args:     []string{"createTable", "orders", "id", "S", "created", "N"},
+			wantCall: "CreateTable",
+			wantInput: &dynamodb.CreateTableInput{
+				TableName: aws.String("orders"),
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("created"), AttributeType: types.ScalarAttributeTypeN},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("created"), KeyType: types.KeyTypeRange},
+				},
+				ProvisionedThroughput: &types.ProvisionedThroughput{ReadCapacityUnits: aws.Int64(5), WriteCapacityUnits: aws.Int64(5)},
+			},
+		},
+		{
+			args:      []string{"deleteTable", "orders"},
+			wantCall:  "DeleteTable",
+			wantInput: &dynamodb.DeleteTableInput{TableName: aws.String("orders")},
+		},
+		{
+			args:      []string{"putItem", "orders", `{"id": "a-1"}`},
+			wantCall:  "PutItem",
+			wantInput: &dynamodb.PutItemInput{TableName: aws.String("orders"), Item: key},
+		},
+		{
+			args:      []string{"deleteItem", "orders", `{"id": "a-1"}`},
+			wantCall:  "DeleteItem",
+			wantInput: &dynamodb.DeleteItemInput{TableName: aws.String("orders"), Key: key},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			clients := fake.New()
+			if _, err := run(t, clients, tt.args...); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			calls := clients.DynamoDB.Calls()
+			if len(calls) != 1 || calls[0].Operation != tt.wantCall {
+				t.Fatalf("calls = %v, want %s", clients.DynamoDB.Operations(), tt.wantCall)
+			}
+			if !reflect.DeepEqual(calls[0].Input, tt.wantInput) {
+				t.Errorf("input = %+v, want %+v", calls[0].Input, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	tests := [][]string{
+		{"putItem", "orders", `{"id": `},
+		{"deleteItem", "orders", "id=1"},
+		{"createTable", "orders", "id", "S", "created"},
+	}
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			clients := fake.New()
+			if _, err := run(t, clients, args...); err == nil {
+				t.Error("error = nil, want an invalid argument")
+			}
+			if operations := clients.DynamoDB.Operations(); len(operations) != 0 {
+				t.Errorf("operations = %v, want none", operations)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/completion"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// completeTableNames suggests table names as the first argument
+func completeTableNames(clients *awsclient.AWSClientCollection) completion.Func {
+	return completion.Args(map[int]completion.Func{
+		0: completion.Resources("dynamodb-tables", func(ctx context.Context) ([]string, error) {
+			names := []string{}
+			paginator := dynamodb.NewListTablesPaginator(clients.DynamoDB, &dynamodb.ListTablesInput{})
+			for paginator.HasMorePages() {
+				page, err := paginator.NextPage(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("error listing tables: %w", err)
+				}
+				names = append(names, page.TableNames...)
+			}
+			return names, nil
+		}),
+	})
+}
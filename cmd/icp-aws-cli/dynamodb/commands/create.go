@@ -3,6 +3,9 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/dryrun"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -10,7 +13,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitCreateCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Command) {
+func InitCreateCommands(clients *awsclient.AWSClientCollection, dynamodbCmd *cobra.Command) {
 	createTableCmd := &cobra.Command{
 		Use:   "createTable",
 		Short: "Creates a new DynamoDB table",
@@ -24,7 +27,7 @@ func InitCreateCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Comm
 				skName = args[3]
 				skType = args[4]
 			}
-			return createTable(dynamodbClient, args[0], args[1], args[2], skName, skType)
+			return createTable(cmd.Context(), clients.DynamoDB, args[0], args[1], args[2], skName, skType)
 		},
 	}
 
@@ -32,7 +35,7 @@ func InitCreateCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Comm
 }
 
 // createTable creates a new DynamoDB table
-func createTable(client *dynamodb.Client, tableName, pkName, pkType, skName, skType string) error {
+func createTable(ctx context.Context, client awsclient.DynamoDBAPI, tableName, pkName, pkType, skName, skType string) error {
 	attrs := []types.AttributeDefinition{{
 		AttributeName: aws.String(pkName),
 		AttributeType: types.ScalarAttributeType(pkType),
@@ -53,7 +56,11 @@ func createTable(client *dynamodb.Client, tableName, pkName, pkType, skName, skT
 			KeyType:       types.KeyTypeRange,
 		})
 	}
-	_, err := client.CreateTable(context.TODO(), &dynamodb.CreateTableInput{
+	if dryrun.Skip("Would create table %s", tableName) {
+		return nil
+	}
+
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
 		TableName:            aws.String(tableName),
 		AttributeDefinitions: attrs,
 		KeySchema:            keySchema,
@@ -62,6 +69,7 @@ func createTable(client *dynamodb.Client, tableName, pkName, pkType, skName, skT
 			WriteCapacityUnits: aws.Int64(5),
 		},
 	})
+	audit.Record(tableName, err)
 	if err != nil {
 		return fmt.Errorf("error creating table %s: %w", tableName, err)
 	}
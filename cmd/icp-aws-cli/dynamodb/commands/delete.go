@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -11,22 +15,24 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitDeleteCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Command) {
+func InitDeleteCommands(clients *awsclient.AWSClientCollection, dynamodbCmd *cobra.Command) {
 	deleteTableCmd := &cobra.Command{
-		Use:   "deleteTable",
-		Short: "Deletes a DynamoDB table",
-		Args:  cobra.ExactArgs(1),
+		Use:               "deleteTable",
+		Short:             "Deletes a DynamoDB table",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTableNames(clients),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return deleteTable(dynamodbClient, args[0])
+			return deleteTable(cmd.Context(), clients.DynamoDB, args[0])
 		},
 	}
 
 	deleteItemCmd := &cobra.Command{
-		Use:   "deleteItem",
-		Short: "Deletes an item from a DynamoDB table",
-		Args:  cobra.ExactArgs(2),
+		Use:               "deleteItem",
+		Short:             "Deletes an item from a DynamoDB table",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeTableNames(clients),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return deleteItem(dynamodbClient, args[0], args[1])
+			return deleteItem(cmd.Context(), clients.DynamoDB, args[0], args[1])
 		},
 	}
 
@@ -35,10 +41,19 @@ func InitDeleteCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Comm
 }
 
 // deleteTable deletes a DynamoDB table
-func deleteTable(client *dynamodb.Client, tableName string) error {
-	_, err := client.DeleteTable(context.TODO(), &dynamodb.DeleteTableInput{
+func deleteTable(ctx context.Context, client awsclient.DynamoDBAPI, tableName string) error {
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "delete", Kind: "table", Targets: []string{tableName}, Strict: true}); err != nil {
+		return err
+	}
+
+	if dryrun.Skip("Would delete table %s", tableName) {
+		return nil
+	}
+
+	_, err := client.DeleteTable(ctx, &dynamodb.DeleteTableInput{
 		TableName: aws.String(tableName),
 	})
+	audit.Record(tableName, err)
 	if err != nil {
 		return fmt.Errorf("error deleting table %s: %w", tableName, err)
 	}
@@ -48,21 +63,30 @@ func deleteTable(client *dynamodb.Client, tableName string) error {
 }
 
 // deleteItem deletes an item from the given table name
-func deleteItem(client *dynamodb.Client, tableName string, keyJSON string) error {
+func deleteItem(ctx context.Context, client awsclient.DynamoDBAPI, tableName string, keyJSON string) error {
 	var key map[string]interface{}
 	if err := json.Unmarshal([]byte(keyJSON), &key); err != nil {
 		return fmt.Errorf("error parsing key JSON: %w", err)
 	}
 
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "delete", Kind: "item", Targets: []string{tableName + " " + keyJSON}}); err != nil {
+		return err
+	}
+
 	av, err := attributevalue.MarshalMap(key)
 	if err != nil {
 		return fmt.Errorf("error marshaling key: %w", err)
 	}
 
-	_, err = client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+	if dryrun.Skip("Would delete item %s from table %s", keyJSON, tableName) {
+		return nil
+	}
+
+	_, err = client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
 		TableName: aws.String(tableName),
 		Key:       av,
 	})
+	audit.Record(tableName+" "+keyJSON, err)
 	if err != nil {
 		return fmt.Errorf("error deleting item: %w", err)
 	}
@@ -3,19 +3,28 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/spf13/cobra"
 )
 
-func InitDescribeCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Command) {
+func InitDescribeCommands(clients *awsclient.AWSClientCollection, dynamodbCmd *cobra.Command) {
 	describeTableCmd := &cobra.Command{
-		Use:   "describe",
-		Short: "Describes a DynamoDB table",
-		Args:  cobra.ExactArgs(1),
+		Use:               "describe",
+		Short:             "Describes a DynamoDB table",
+		Annotations:       fanout.ReadOnly(),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTableNames(clients),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return describeTable(dynamodbClient, args[0])
+			result, err := describeTable(cmd.Context(), clients.DynamoDB, args[0])
+			if err != nil {
+				return err
+			}
+			return output.Print(result)
 		},
 	}
 
@@ -23,16 +32,15 @@ func InitDescribeCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Co
 }
 
 // describeTable describes a DynamoDB table
-func describeTable(client *dynamodb.Client, tableName string) error {
-	result, err := client.DescribeTable(context.TODO(), &dynamodb.DescribeTableInput{
+func describeTable(ctx context.Context, client awsclient.DynamoDBAPI, tableName string) (*output.Result, error) {
+	result, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
 		TableName: aws.String(tableName),
 	})
 	if err != nil {
-		return fmt.Errorf("error describing table %s: %w", tableName, err)
+		return nil, fmt.Errorf("error describing table %s: %w", tableName, err)
 	}
 
-	fmt.Printf("Table Name: %s\n", *result.Table.TableName)
-	fmt.Printf("Status: %s\n", result.Table.TableStatus)
-	fmt.Printf("Item Count: %d\n", result.Table.ItemCount)
-	return nil
+	records := output.NewResult("TableName", "Status", "ItemCount")
+	records.Add(*result.Table.TableName, string(result.Table.TableStatus), aws.ToInt64(result.Table.ItemCount))
+	return records, nil
 }
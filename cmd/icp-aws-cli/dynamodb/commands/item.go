@@ -4,6 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
+	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -11,22 +17,25 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitItemCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Command) {
+func InitItemCommands(clients *awsclient.AWSClientCollection, dynamodbCmd *cobra.Command) {
 	putItemCmd := &cobra.Command{
-		Use:   "putItem",
-		Short: "Puts an item into a DynamoDB table",
-		Args:  cobra.ExactArgs(2),
+		Use:               "putItem",
+		Short:             "Puts an item into a DynamoDB table",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeTableNames(clients),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return putItem(dynamodbClient, args[0], args[1])
+			return putItem(cmd.Context(), clients.DynamoDB, args[0], args[1])
 		},
 	}
 
 	getItemCmd := &cobra.Command{
-		Use:   "getItem",
-		Short: "Retrieves an item from a DynamoDB table",
-		Args:  cobra.ExactArgs(2),
+		Use:               "getItem",
+		Short:             "Retrieves an item from a DynamoDB table",
+		Annotations:       fanout.ReadOnly(),
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeTableNames(clients),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return getItem(dynamodbClient, args[0], args[1])
+			return getItem(cmd.Context(), clients.DynamoDB, args[0], args[1])
 		},
 	}
 
@@ -35,7 +44,7 @@ func InitItemCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Comman
 }
 
 // putItems adds a new item, given as JSON, to the given table name
-func putItem(client *dynamodb.Client, tableName string, itemJSON string) error {
+func putItem(ctx context.Context, client awsclient.DynamoDBAPI, tableName string, itemJSON string) error {
 	var item map[string]interface{}
 	if err := json.Unmarshal([]byte(itemJSON), &item); err != nil {
 		return fmt.Errorf("error parsing item JSON: %w", err)
@@ -45,10 +54,15 @@ func putItem(client *dynamodb.Client, tableName string, itemJSON string) error {
 	if err != nil {
 		return fmt.Errorf("error marshaling item: %w", err)
 	}
-	_, err = client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+	if dryrun.Skip("Would put item %s into table %s", itemJSON, tableName) {
+		return nil
+	}
+
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: aws.String(tableName),
 		Item:      av,
 	})
+	audit.Record(tableName, err)
 	if err != nil {
 		return fmt.Errorf("error putting item: %w", err)
 	}
@@ -58,7 +72,7 @@ func putItem(client *dynamodb.Client, tableName string, itemJSON string) error {
 }
 
 // getItem retrieves an item from the provided table name
-func getItem(client *dynamodb.Client, tableName string, keyJSON string) error {
+func getItem(ctx context.Context, client awsclient.DynamoDBAPI, tableName string, keyJSON string) error {
 	var key map[string]interface{}
 	if err := json.Unmarshal([]byte(keyJSON), &key); err != nil {
 		return fmt.Errorf("error parsing key JSON: %w", err)
@@ -69,7 +83,7 @@ func getItem(client *dynamodb.Client, tableName string, keyJSON string) error {
 		return fmt.Errorf("error marshaling key: %w", err)
 	}
 
-	result, err := client.GetItem(context.TODO(), &dynamodb.GetItemInput{
+	result, err := client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(tableName),
 		Key:       av,
 	})
@@ -77,9 +91,10 @@ func getItem(client *dynamodb.Client, tableName string, keyJSON string) error {
 		return fmt.Errorf("error getting item: %w", err)
 	}
 
+	records := output.NewResult()
 	if len(result.Item) == 0 {
-		fmt.Println("No item found")
-		return nil
+		fmt.Fprintln(os.Stderr, "No item found")
+		return output.Print(records)
 	}
 
 	var item map[string]interface{}
@@ -87,6 +102,6 @@ func getItem(client *dynamodb.Client, tableName string, keyJSON string) error {
 		return fmt.Errorf("error unmarshaling item: %w", err)
 	}
 
-	fmt.Println(item)
-	return nil
+	records.AddRecord(item)
+	return output.Print(records)
 }
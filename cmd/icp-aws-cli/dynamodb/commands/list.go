@@ -3,32 +3,59 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/spf13/cobra"
 )
 
-func InitListCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Command) {
+func InitListCommands(clients *awsclient.AWSClientCollection, dynamodbCmd *cobra.Command) {
+	var pagingOpts paging.Options
+
 	listTablesCmd := &cobra.Command{
-		Use:   "list",
-		Short: "Lists DynamoDB tables",
+		Use:         "list",
+		Short:       "Lists DynamoDB tables",
+		Annotations: fanout.ReadOnly(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listTables(dynamodbClient)
+			result, token, err := listTables(cmd.Context(), clients.DynamoDB, pagingOpts)
+			if err != nil {
+				return err
+			}
+			paging.PrintNextToken(token)
+			return output.Print(result)
 		},
 	}
 
+	paging.AddFlags(listTablesCmd, &pagingOpts, paging.Range{Min: 1, Max: 100})
 	dynamodbCmd.AddCommand(listTablesCmd)
 }
 
 // listTables retrieves all the DynamoDB tables the current user has access to
-func listTables(dynamodbClient *dynamodb.Client) error {
-	result, err := dynamodbClient.ListTables(context.TODO(), &dynamodb.ListTablesInput{})
+func listTables(ctx context.Context, dynamodbClient awsclient.DynamoDBAPI, pagingOpts paging.Options) (*output.Result, string, error) {
+	collector, err := paging.NewCollector[string](pagingOpts)
 	if err != nil {
-		return fmt.Errorf("error listing DynamoDB tables: %w", err)
+		return nil, "", err
+	}
+
+	paginator := dynamodb.NewListTablesPaginator(dynamodbClient, &dynamodb.ListTablesInput{
+		ExclusiveStartTableName: collector.StartToken(),
+	}, func(o *dynamodb.ListTablesPaginatorOptions) {
+		o.Limit = pagingOpts.PageSize
+	})
+	for paginator.HasMorePages() && !collector.Done() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("error listing DynamoDB tables: %w", err)
+		}
+		collector.Add(page.TableNames, page.LastEvaluatedTableName)
 	}
 
-	for _, tableName := range result.TableNames {
-		fmt.Println(tableName)
+	records := output.NewResult("TableName")
+	for _, tableName := range collector.Items() {
+		records.Add(tableName)
 	}
-	return nil
+	return records, collector.NextToken(), nil
 }
@@ -4,27 +4,37 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/spf13/cobra"
 )
 
-func InitQueryCommands(dynamodbClient *dynamodb.Client, dynamodbCmd *cobra.Command) {
+func InitQueryCommands(clients *awsclient.AWSClientCollection, dynamodbCmd *cobra.Command) {
+	var pagingOpts paging.Options
+
 	queryItemsCmd := &cobra.Command{
-		Use:   "query",
-		Short: "Queries items in a DynamoDB table",
-		Args:  cobra.ExactArgs(3),
+		Use:               "query",
+		Short:             "Queries items in a DynamoDB table",
+		Annotations:       fanout.ReadOnly(),
+		Args:              cobra.ExactArgs(3),
+		ValidArgsFunction: completeTableNames(clients),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return queryItems(dynamodbClient, args[0], args[1], args[2])
+			return queryItems(cmd.Context(), clients.DynamoDB, args[0], args[1], args[2], pagingOpts)
 		},
 	}
 
+	paging.AddFlags(queryItemsCmd, &pagingOpts, paging.Range{Min: 1})
 	dynamodbCmd.AddCommand(queryItemsCmd)
 }
 
-func queryItems(client *dynamodb.Client, tableName, keyCondition, exprAttrValuesJSON string) error {
+func queryItems(ctx context.Context, client awsclient.DynamoDBAPI, tableName, keyCondition, exprAttrValuesJSON string, pagingOpts paging.Options) error {
 	var exprAttrValues map[string]interface{}
 	if err := json.Unmarshal([]byte(exprAttrValuesJSON), &exprAttrValues); err != nil {
 		return fmt.Errorf("error parsing expression attribute values JSON: %w", err)
@@ -35,21 +45,102 @@ func queryItems(client *dynamodb.Client, tableName, keyCondition, exprAttrValues
 		return fmt.Errorf("error marshaling expression attribute values: %w", err)
 	}
 
-	result, err := client.Query(context.TODO(), &dynamodb.QueryInput{
+	collector, err := paging.NewCollector[map[string]types.AttributeValue](pagingOpts)
+	if err != nil {
+		return err
+	}
+	startKey, err := decodeKey(collector.StartToken())
+	if err != nil {
+		return err
+	}
+
+	paginator := dynamodb.NewQueryPaginator(client, &dynamodb.QueryInput{
 		TableName:                 aws.String(tableName),
 		KeyConditionExpression:    aws.String(keyCondition),
 		ExpressionAttributeValues: avs,
+		ExclusiveStartKey:         startKey,
+	}, func(o *dynamodb.QueryPaginatorOptions) {
+		o.Limit = pagingOpts.PageSize
 	})
-	if err != nil {
-		return fmt.Errorf("error querying items: %w", err)
+	for paginator.HasMorePages() && !collector.Done() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("error querying items: %w", err)
+		}
+		nextKey, err := encodeKey(page.LastEvaluatedKey)
+		if err != nil {
+			return err
+		}
+		collector.Add(page.Items, nextKey)
 	}
 
-	for _, item := range result.Items {
+	records := output.NewResult()
+	for _, item := range collector.Items() {
 		var itemMap map[string]interface{}
 		if err := attributevalue.UnmarshalMap(item, &itemMap); err != nil {
 			return fmt.Errorf("error unmarshaling item: %w", err)
 		}
-		fmt.Println(itemMap)
+		records.AddRecord(itemMap)
+	}
+	collector.PrintNextToken()
+	return output.Print(records)
+}
+
+// keyAttribute is the DynamoDB JSON form of a key attribute, which can only be a string, number or binary
+type keyAttribute struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+	B []byte  `json:"B,omitempty"`
+}
+
+// encodeKey turns a LastEvaluatedKey into a pagination token
+func encodeKey(key map[string]types.AttributeValue) (*string, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+
+	encoded := map[string]keyAttribute{}
+	for name, value := range key {
+		switch v := value.(type) {
+		case *types.AttributeValueMemberS:
+			encoded[name] = keyAttribute{S: aws.String(v.Value)}
+		case *types.AttributeValueMemberN:
+			encoded[name] = keyAttribute{N: aws.String(v.Value)}
+		case *types.AttributeValueMemberB:
+			encoded[name] = keyAttribute{B: v.Value}
+		default:
+			return nil, fmt.Errorf("unsupported key attribute type for %s", name)
+		}
+	}
+
+	token, err := json.Marshal(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding last evaluated key: %w", err)
+	}
+	return aws.String(string(token)), nil
+}
+
+// decodeKey turns a pagination token back into an ExclusiveStartKey
+func decodeKey(token *string) (map[string]types.AttributeValue, error) {
+	if token == nil {
+		return nil, nil
+	}
+
+	var encoded map[string]keyAttribute
+	if err := json.Unmarshal([]byte(*token), &encoded); err != nil {
+		return nil, fmt.Errorf("invalid starting token: %w", err)
+	}
+
+	key := map[string]types.AttributeValue{}
+	for name, value := range encoded {
+		switch {
+		case value.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *value.S}
+		case value.N != nil:
+			key[name] = &types.AttributeValueMemberN{Value: *value.N}
+		default:
+			key[name] = &types.AttributeValueMemberB{Value: value.B}
+		}
 	}
-	return nil
+	return key, nil
 }
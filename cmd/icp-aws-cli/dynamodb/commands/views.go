@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
+	"icp-aws-cli/pkg/ui"
+)
+
+// TablesView browses the tables in the ui command.
+func TablesView(clients *awsclient.AWSClientCollection) ui.View {
+	return ui.View{
+		Name: "Tables",
+		ID:   "TableName",
+		List: func(ctx context.Context) (*output.Result, error) {
+			result, _, err := listTables(ctx, clients.DynamoDB, paging.Options{})
+			return result, err
+		},
+		Detail: func(ctx context.Context, id string) (*output.Result, error) {
+			return describeTable(ctx, clients.DynamoDB, id)
+		},
+		Actions: []ui.Action{
+			{Key: 'd', Name: "delete", Args: func(id string) []string {
+				return []string{"dynamodb", "deleteTable", id}
+			}},
+		},
+	}
+}
@@ -2,12 +2,13 @@ package dynamodb
 
 import (
 	"icp-aws-cli/cmd/icp-aws-cli/dynamodb/commands"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/ui"
 
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/spf13/cobra"
 )
 
-func InitCommands(dynamodbClient *dynamodb.Client) *cobra.Command {
+func InitCommands(clients *awsclient.AWSClientCollection) *cobra.Command {
 	dynamodbCmd := &cobra.Command{
 		Use:   "dynamodb",
 		Short: "Commands to interact with Amazon DynamoDB",
@@ -15,12 +16,17 @@ func InitCommands(dynamodbClient *dynamodb.Client) *cobra.Command {
 	}
 
 	// Initialize subcommands
-	commands.InitCreateCommands(dynamodbClient, dynamodbCmd)
-	commands.InitDeleteCommands(dynamodbClient, dynamodbCmd)
-	commands.InitDescribeCommands(dynamodbClient, dynamodbCmd)
-	commands.InitItemCommands(dynamodbClient, dynamodbCmd)
-	commands.InitListCommands(dynamodbClient, dynamodbCmd)
-	commands.InitQueryCommands(dynamodbClient, dynamodbCmd)
+	commands.InitCreateCommands(clients, dynamodbCmd)
+	commands.InitDeleteCommands(clients, dynamodbCmd)
+	commands.InitDescribeCommands(clients, dynamodbCmd)
+	commands.InitItemCommands(clients, dynamodbCmd)
+	commands.InitListCommands(clients, dynamodbCmd)
+	commands.InitQueryCommands(clients, dynamodbCmd)
 
 	return dynamodbCmd
 }
+
+// Views returns the resources of the service browsed by the ui command.
+func Views(clients *awsclient.AWSClientCollection) []ui.View {
+	return []ui.View{commands.TablesView(clients)}
+}
@@ -0,0 +1,319 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/filter"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	"github.com/spf13/cobra"
+)
+
+var launched = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func instance(id, name string, state types.InstanceStateName) types.Instance {
+	i := types.Instance{
+		InstanceId:   aws.String(id),
+		InstanceType: types.InstanceTypeT3Micro,
+		State:        &types.InstanceState{Name: state},
+		LaunchTime:   aws.Time(launched),
+	}
+	if name != "" {
+		i.Tags = []types.Tag{{Key: aws.String("Name"), Value: aws.String(name)}}
+	}
+	return i
+}
+
+// newFakeEC2 returns fakes whose DescribeInstances returns instances, applying
+// only the instance-id filter; the other filters are checked separately.
+func newFakeEC2(instances ...types.Instance) *fake.Clients {
+	clients := fake.New()
+	clients.EC2.DescribeInstancesFunc = func(_ context.Context, input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+		matched := instances
+		for _, f := range input.Filters {
+			if aws.ToString(f.Name) != "instance-id" {
+				continue
+			}
+			matched = nil
+			for _, i := range instances {
+				if slices.Contains(f.Values, aws.ToString(i.InstanceId)) {
+					matched = append(matched, i)
+				}
+			}
+		}
+		return &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: matched}}}, nil
+	}
+	clients.EC2.RunInstancesFunc = func(context.Context, *ec2.RunInstancesInput) (*ec2.RunInstancesOutput, error) {
+		return &ec2.RunInstancesOutput{Instances: []types.Instance{instance("i-9", "", "pending")}}, nil
+	}
+	return clients
+}
+
+// run executes an ec2 subcommand and returns what it printed as text.
+func run(t *testing.T, clients *fake.Clients, args ...string) (string, error) {
+	t.Helper()
+	ec2Cmd := &cobra.Command{Use: "ec2", SilenceUsage: true, SilenceErrors: true}
+	collection := clients.Collection()
+	InitListCommands(collection, ec2Cmd)
+	InitStartCommands(collection, ec2Cmd)
+	InitStopCommands(collection, ec2Cmd)
+	InitRebootCommands(collection, ec2Cmd)
+	InitTerminateCommands(collection, ec2Cmd)
+	InitCreateCommands(collection, ec2Cmd)
+
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+	ec2Cmd.SetArgs(args)
+	err := ec2Cmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+// instanceIDs returns the instance IDs of the calls to operation.
+func instanceIDs(clients *fake.Clients, operation string) []string {
+	var ids []string
+	for _, call := range clients.EC2.Calls() {
+		if call.Operation != operation {
+			continue
+		}
+		switch input := call.Input.(type) {
+		case *ec2.StartInstancesInput:
+			ids = append(ids, input.InstanceIds...)
+		case *ec2.StopInstancesInput:
+			ids = append(ids, input.InstanceIds...)
+		case *ec2.RebootInstancesInput:
+			ids = append(ids, input.InstanceIds...)
+		case *ec2.TerminateInstancesInput:
+			ids = append(ids, input.InstanceIds...)
+		}
+	}
+	return ids
+}
+
+func TestInstanceFilters(t *testing.T) {
+	tests := []struct {
+		args          []string
+		wantFilters   map[string][]string
+		wantRemaining []string
+		wantErr       bool
+	}{
+		{
+			args:        []string{"state=running,stopped", "name=web-*", "tag:Env=prod", "vpc-id=vpc-123"},
+			wantFilters: map[string][]string{"instance-state-name": {"running", "stopped"}, "tag:Name": {"web-*"}, "tag:Env": {"prod"}, "vpc-id": {"vpc-123"}},
+		},
+		{
+			args:          []string{"id=i-1", "name~^web", "tag:Env!=prod"},
+			wantFilters:   map[string][]string{"instance-id": {"i-1"}},
+			wantRemaining: []string{"name~^web", "tag:Env!=prod"},
+		},
+		{args: []string{"vpc-id!=vpc-123"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			expression, err := filter.Parse(tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filters, remaining, err := instanceFilters(expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("instanceFilters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			gotFilters := map[string][]string{}
+			for _, f := range filters {
+				gotFilters[aws.ToString(f.Name)] = f.Values
+			}
+			if !reflect.DeepEqual(gotFilters, tt.wantFilters) {
+				t.Errorf("filters = %v, want %v", gotFilters, tt.wantFilters)
+			}
+			var gotRemaining []string
+			for _, condition := range remaining {
+				gotRemaining = append(gotRemaining, condition.String())
+			}
+			if !reflect.DeepEqual(gotRemaining, tt.wantRemaining) {
+				t.Errorf("remaining = %q, want %q", gotRemaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestListCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantFilters []types.Filter
+		wantErr     string
+	}{
+		{
+			name:        "all",
+			args:        []string{"list", "--all"},
+			wantFilters: []types.Filter{},
+		},
+		{
+			name: "pattern and state",
+			args: []string{"list", "-p", "web-*", "--state", "running"},
+			wantFilters: []types.Filter{
+				{Name: aws.String("tag:Name"), Values: []string{"web-*"}},
+				{Name: aws.String("instance-state-name"), Values: []string{"running"}},
+			},
+		},
+		{
+			name:        "tag",
+			args:        []string{"list", "-k", "Env", "-v", "prod"},
+			wantFilters: []types.Filter{{Name: aws.String("tag:Env"), Values: []string{"prod"}}},
+		},
+		{
+			name:    "nothing selected",
+			args:    []string{"list"},
+			wantErr: "at least one filter must be specified",
+		},
+		{
+			name:    "all with a filter",
+			args:    []string{"list", "--all", "--state", "running"},
+			wantErr: "cannot be combined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeEC2(instance("i-1", "web-1", "running"), instance("i-2", "", "stopped"))
+			got, err := run(t, clients, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if operations := clients.EC2.Operations(); len(operations) != 0 {
+					t.Errorf("operations = %v, want none", operations)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			want := "web-1\ti-1\tt3.micro\trunning\t2026-01-02 03:04:05\n" +
+				"<Not Assigned>\ti-2\tt3.micro\tstopped\t2026-01-02 03:04:05\n"
+			if got != want {
+				t.Errorf("output = %q, want %q", got, want)
+			}
+			input := clients.EC2.Calls()[0].Input.(*ec2.DescribeInstancesInput)
+			if !reflect.DeepEqual(input.Filters, tt.wantFilters) {
+				t.Errorf("filters = %+v, want %+v", input.Filters, tt.wantFilters)
+			}
+		})
+	}
+}
+
+func TestManageCommands(t *testing.T) {
+	confirm.SetAssumeYes(true)
+	t.Cleanup(func() { confirm.SetAssumeYes(false) })
+
+	tests := []struct {
+		args          []string
+		wantOperation string
+		wantIDs       []string
+		wantErr       string
+	}{
+		{args: []string{"stop", "-i", "i-1"}, wantOperation: "StopInstances", wantIDs: []string{"i-1"}},
+		{args: []string{"start", "-p", "web-*"}, wantOperation: "StartInstances", wantIDs: []string{"i-1", "i-2"}},
+		{args: []string{"reboot", "-k", "Env", "-v", "prod"}, wantOperation: "RebootInstances", wantIDs: []string{"i-1", "i-2"}},
+		{args: []string{"terminate", "-i", "i-2"}, wantOperation: "TerminateInstances", wantIDs: []string{"i-2"}},
+		{args: []string{"stop", "-s", "running", "-f", "name!~-2$"}, wantOperation: "StopInstances", wantIDs: []string{"i-1"}},
+		{args: []string{"terminate"}, wantErr: "at least one filter must be specified"},
+		{args: []string{"stop", "--all", "-p", "web-*"}, wantErr: "cannot be combined"},
+		{args: []string{"stop", "-k", "Env"}, wantErr: "must be specified together"},
+		{args: []string{"stop", "-f", "size~large"}, wantErr: "unsupported filter"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			clients := newFakeEC2(instance("i-1", "web-1", "running"), instance("i-2", "web-2", "running"))
+			_, err := run(t, clients, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				for _, operation := range clients.EC2.Operations() {
+					if operation != "DescribeInstances" {
+						t.Errorf("%s called despite %v", operation, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got := instanceIDs(clients, tt.wantOperation); !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("%s on %v, want %v", tt.wantOperation, got, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestManageNoMatch(t *testing.T) {
+	clients := newFakeEC2()
+	if _, err := run(t, clients, "stop", "-p", "api-*"); err == nil || !strings.Contains(err.Error(), "no instances found") {
+		t.Errorf("error = %v, want no instances found", err)
+	}
+	if got := instanceIDs(clients, "StopInstances"); got != nil {
+		t.Errorf("stopped %v, want none", got)
+	}
+}
+
+func TestCreateCommand(t *testing.T) {
+	clients := newFakeEC2()
+	if _, err := run(t, clients, "create", "ami-123", "t3.micro"); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	want := &ec2.RunInstancesInput{ImageId: aws.String("ami-123"), InstanceType: types.InstanceTypeT3Micro, MinCount: aws.Int32(1), MaxCount: aws.Int32(1), DryRun: aws.Bool(false)}
+	if calls := clients.EC2.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, want) {
+		t.Errorf("calls = %+v, want RunInstances %+v", calls, want)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	dryrun.SetEnabled(true)
+	t.Cleanup(func() { dryrun.SetEnabled(false) })
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr bool
+	}{
+		{name: "permitted", code: "DryRunOperation"},
+		{name: "denied", code: "UnauthorizedOperation", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeEC2(instance("i-1", "web-1", types.InstanceStateNameRunning))
+			clients.EC2.StopInstancesFunc = func(context.Context, *ec2.StopInstancesInput) (*ec2.StopInstancesOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: tt.code}
+			}
+			_, err := run(t, clients, "stop", "-p", "web-*")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			calls := clients.EC2.Calls()
+			input, ok := calls[len(calls)-1].Input.(*ec2.StopInstancesInput)
+			if !ok || !aws.ToBool(input.DryRun) {
+				t.Errorf("last call = %+v, want StopInstances with DryRun set", calls[len(calls)-1])
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/completion"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// completeInstanceIDs suggests instance IDs, described by their Name tag or,
+// for unnamed instances, their state
+func completeInstanceIDs(clients *awsclient.AWSClientCollection) completion.Func {
+	return completion.Resources("ec2-instances", func(ctx context.Context) ([]string, error) {
+		candidates := []string{}
+		paginator := ec2.NewDescribeInstancesPaginator(clients.EC2, &ec2.DescribeInstancesInput{})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("error describing instances: %w", err)
+			}
+			for _, instance := range reservationInstances(page.Reservations) {
+				candidate := *instance.InstanceId
+				if description := completionDescription(instance); description != "" {
+					candidate += "\t" + description
+				}
+				candidates = append(candidates, candidate)
+			}
+		}
+		return candidates, nil
+	})
+}
+
+// completionDescription describes an instance next to its ID, which the shell
+// already displays
+func completionDescription(instance types.Instance) string {
+	for _, tag := range instance.Tags {
+		if aws.ToString(tag.Key) == "Name" && aws.ToString(tag.Value) != "" {
+			return *tag.Value
+		}
+	}
+	if instance.State != nil {
+		return string(instance.State.Name)
+	}
+	return ""
+}
@@ -3,6 +3,9 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/dryrun"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -10,26 +13,35 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func InitCreateCommands(ec2Client *ec2.Client, ec2Cmd *cobra.Command) {
+func InitCreateCommands(clients *awsclient.AWSClientCollection, ec2Cmd *cobra.Command) {
 	var createInstanceCmd = &cobra.Command{
 		Use:   "create [ami-id] [instance-type]",
 		Short: "Creates a new EC2 instance",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return createInstance(ec2Client, args[0], args[1])
+			return createInstance(cmd.Context(), clients.EC2, args[0], args[1])
 		},
 	}
 
 	ec2Cmd.AddCommand(createInstanceCmd)
 }
 
-func createInstance(ec2Client *ec2.Client, amiID, instanceType string) error {
-	runResult, err := ec2Client.RunInstances(context.TODO(), &ec2.RunInstancesInput{
+func createInstance(ctx context.Context, ec2Client awsclient.EC2API, amiID, instanceType string) error {
+	runResult, err := ec2Client.RunInstances(ctx, &ec2.RunInstancesInput{
 		ImageId:      aws.String(amiID),
 		InstanceType: types.InstanceType(instanceType),
 		MinCount:     aws.Int32(1),
 		MaxCount:     aws.Int32(1),
+		DryRun:       aws.Bool(dryrun.Enabled()),
 	})
+	if dryrun.Enabled() {
+		if err := dryrun.CheckEC2(err); err != nil {
+			return fmt.Errorf("could not create instance: %w", err)
+		}
+		dryrun.Plan("Would create a %s instance from %s", instanceType, amiID)
+		return nil
+	}
+	audit.Record(amiID, err)
 	if err != nil {
 		return fmt.Errorf("could not create instance: %w", err)
 	}
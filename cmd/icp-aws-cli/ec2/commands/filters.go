@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"icp-aws-cli/pkg/filter"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/spf13/cobra"
+)
+
+// instanceFilterNames maps the filter keys evaluated client-side to their EC2 filter names.
+// Equalities on any other key are passed to EC2 unchanged, e.g. vpc-id=vpc-123.
+var instanceFilterNames = map[string]string{
+	"id":     "instance-id",
+	"name":   "tag:Name",
+	"state":  "instance-state-name",
+	"type":   "instance-type",
+	"az":     "availability-zone",
+	"vpc":    "vpc-id",
+	"subnet": "subnet-id",
+	"image":  "image-id",
+}
+
+// newInstanceSelector registers the selection flags shared by the instance commands
+func newInstanceSelector(cmd *cobra.Command) *filter.Selector {
+	selector := filter.NewSelector(cmd, "instances")
+	selector.Shorthand(cmd, "instance-id", "i", "Instance ID to filter instances (same as --filter id=ID)", filter.Is("id"))
+	selector.Shorthand(cmd, "pattern", "p", "Name glob to filter instances, e.g. web-* (same as --filter name=PATTERN)", filter.Is("name"))
+	selector.TagFlags(cmd)
+	selector.Shorthand(cmd, "state", "s", "State to filter instances, e.g. running or stopped (same as --filter state=STATE)", filter.Is("state"))
+	return selector
+}
+
+// instanceFilters splits an expression into EC2 filters and the conditions left to evaluate client-side
+func instanceFilters(expression filter.Expression) ([]types.Filter, filter.Expression, error) {
+	filters := []types.Filter{}
+	remaining := filter.Expression{}
+	for _, condition := range expression {
+		if condition.Operator != filter.Equals {
+			remaining = append(remaining, condition)
+			continue
+		}
+		// EC2 filters support the same * and ? wildcards
+		name, ok := instanceFilterNames[condition.Key]
+		if !ok {
+			name = condition.Key
+		}
+		filters = append(filters, types.Filter{Name: aws.String(name), Values: condition.Values})
+	}
+
+	if err := remaining.CheckKeys("id", "name", "state", "type", "az", "vpc", "subnet", "image"); err != nil {
+		return nil, nil, err
+	}
+	return filters, remaining, nil
+}
+
+// matchInstances returns the instances satisfying the client-side conditions
+func matchInstances(instances []types.Instance, expression filter.Expression) []types.Instance {
+	if len(expression) == 0 {
+		return instances
+	}
+	matched := []types.Instance{}
+	for _, instance := range instances {
+		if expression.Match(instanceFields(instance)) {
+			matched = append(matched, instance)
+		}
+	}
+	return matched
+}
+
+// instanceFields resolves the filter keys of an instance
+func instanceFields(instance types.Instance) filter.Fields {
+	return func(key string) (string, bool) {
+		if tagKey, ok := filter.TagKey(key); ok {
+			for _, tag := range instance.Tags {
+				if aws.ToString(tag.Key) == tagKey {
+					return aws.ToString(tag.Value), true
+				}
+			}
+			return "", false
+		}
+
+		var value *string
+		switch key {
+		case "id":
+			value = instance.InstanceId
+		case "name":
+			return instanceFields(instance)(filter.TagPrefix + "Name")
+		case "state":
+			if instance.State != nil {
+				return string(instance.State.Name), true
+			}
+		case "type":
+			return string(instance.InstanceType), true
+		case "az":
+			if instance.Placement != nil {
+				value = instance.Placement.AvailabilityZone
+			}
+		case "vpc":
+			value = instance.VpcId
+		case "subnet":
+			value = instance.SubnetId
+		case "image":
+			value = instance.ImageId
+		}
+		return aws.ToString(value), value != nil
+	}
+}
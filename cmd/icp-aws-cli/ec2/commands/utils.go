@@ -3,26 +3,39 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/filter"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
-type ActionFunc func(*ec2.Client, context.Context, interface{}) (interface{}, error)
+type ActionFunc func(awsclient.EC2API, context.Context, interface{}) (interface{}, error)
 type InputBuilderFunc func([]string) interface{}
 
-func manageInstancesWithFilters(ec2Client *ec2.Client, filters []types.Filter, buildInput InputBuilderFunc, actionFunc ActionFunc) error {
-	result, err := ec2Client.DescribeInstances(context.TODO(), &ec2.DescribeInstancesInput{
-		Filters: filters,
-	})
+func manageInstancesWithFilters(ctx context.Context, ec2Client awsclient.EC2API, expression filter.Expression, action string, buildInput InputBuilderFunc, actionFunc ActionFunc) error {
+	filters, remaining, err := instanceFilters(expression)
 	if err != nil {
-		return fmt.Errorf("error describing instances: %w", err)
+		return err
 	}
 
 	instanceIDs := []string{}
-	for _, reservation := range result.Reservations {
-		for _, instance := range reservation.Instances {
+	targets := []string{}
+	paginator := ec2.NewDescribeInstancesPaginator(ec2Client, &ec2.DescribeInstancesInput{
+		Filters: filters,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("error describing instances: %w", err)
+		}
+		for _, instance := range matchInstances(reservationInstances(page.Reservations), remaining) {
 			instanceIDs = append(instanceIDs, *instance.InstanceId)
+			targets = append(targets, instanceLabel(instance))
 		}
 	}
 
@@ -30,8 +43,23 @@ func manageInstancesWithFilters(ec2Client *ec2.Client, filters []types.Filter, b
 		return fmt.Errorf("no instances found with the specified filters")
 	}
 
+	if err := confirm.Confirm(ctx, confirm.Request{Action: action, Kind: "EC2 instance", Targets: targets}); err != nil {
+		return err
+	}
+
+	// The builders set the native DryRun parameter, so EC2 still validates the request and IAM permissions
 	input := buildInput(instanceIDs)
-	_, err = actionFunc(ec2Client, context.TODO(), input)
+	_, err = actionFunc(ec2Client, ctx, input)
+	if dryrun.Enabled() {
+		if err := dryrun.CheckEC2(err); err != nil {
+			return fmt.Errorf("error managing instances: %w", err)
+		}
+		dryrun.Plan("Would %s instances %v", action, instanceIDs)
+		return nil
+	}
+	for _, instanceID := range instanceIDs {
+		audit.Record(instanceID, err)
+	}
 	if err != nil {
 		return fmt.Errorf("error managing instances: %w", err)
 	}
@@ -39,3 +67,22 @@ func manageInstancesWithFilters(ec2Client *ec2.Client, filters []types.Filter, b
 	fmt.Printf("Instances %v managed successfully\n", instanceIDs)
 	return nil
 }
+
+// instanceLabel identifies an instance in confirmation prompts by its ID and Name tag
+func instanceLabel(instance types.Instance) string {
+	for _, tag := range instance.Tags {
+		if aws.ToString(tag.Key) == "Name" && aws.ToString(tag.Value) != "" {
+			return fmt.Sprintf("%s (%s)", *instance.InstanceId, *tag.Value)
+		}
+	}
+	return *instance.InstanceId
+}
+
+// reservationInstances flattens the instances of a DescribeInstances page
+func reservationInstances(reservations []types.Reservation) []types.Instance {
+	instances := []types.Instance{}
+	for _, reservation := range reservations {
+		instances = append(instances, reservation.Instances...)
+	}
+	return instances
+}
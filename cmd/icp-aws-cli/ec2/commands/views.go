@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
+	"icp-aws-cli/pkg/ui"
+)
+
+// InstancesView browses the instances in the ui command.
+func InstancesView(clients *awsclient.AWSClientCollection) ui.View {
+	return ui.View{
+		Name: "Instances",
+		ID:   "InstanceId",
+		List: func(ctx context.Context) (*output.Result, error) {
+			result, _, err := listInstances(ctx, clients.EC2, nil, nil, paging.Options{})
+			return result, err
+		},
+		Actions: []ui.Action{
+			{Key: 's', Name: "start", Args: instanceCommand("start")},
+			{Key: 't', Name: "stop", Args: instanceCommand("stop")},
+			{Key: 'b', Name: "reboot", Args: instanceCommand("reboot")},
+			{Key: 'd', Name: "terminate", Args: instanceCommand("terminate")},
+		},
+	}
+}
+
+func instanceCommand(action string) func(id string) []string {
+	return func(id string) []string {
+		return []string{"ec2", action, "--instance-id", id}
+	}
+}
@@ -2,12 +2,13 @@ package ec2
 
 import (
 	"icp-aws-cli/cmd/icp-aws-cli/ec2/commands"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/ui"
 
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/spf13/cobra"
 )
 
-func InitCommands(ec2Client *ec2.Client) *cobra.Command {
+func InitCommands(clients *awsclient.AWSClientCollection) *cobra.Command {
 	var ec2Cmd = &cobra.Command{
 		Use:   "ec2",
 		Short: "Commands to interact with Amazon EC2",
@@ -15,12 +16,17 @@ func InitCommands(ec2Client *ec2.Client) *cobra.Command {
 	}
 
 	// Initialize subcommands
-	commands.InitListCommands(ec2Client, ec2Cmd)
-	commands.InitStartCommands(ec2Client, ec2Cmd)
-	commands.InitStopCommands(ec2Client, ec2Cmd)
-	commands.InitRebootCommands(ec2Client, ec2Cmd)
-	commands.InitTerminateCommands(ec2Client, ec2Cmd)
-	commands.InitCreateCommands(ec2Client, ec2Cmd)
+	commands.InitListCommands(clients, ec2Cmd)
+	commands.InitStartCommands(clients, ec2Cmd)
+	commands.InitStopCommands(clients, ec2Cmd)
+	commands.InitRebootCommands(clients, ec2Cmd)
+	commands.InitTerminateCommands(clients, ec2Cmd)
+	commands.InitCreateCommands(clients, ec2Cmd)
 
 	return ec2Cmd
 }
+
+// Views returns the resources of the service browsed by the ui command.
+func Views(clients *awsclient.AWSClientCollection) []ui.View {
+	return []ui.View{commands.InstancesView(clients)}
+}
@@ -0,0 +1,51 @@
+package inventory
+
+import (
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/inventory"
+	"icp-aws-cli/pkg/output"
+
+	"github.com/spf13/cobra"
+)
+
+func InitCommands(clients *awsclient.AWSClientCollection) *cobra.Command {
+	var types, regions []string
+	var summary bool
+
+	inventoryCmd := &cobra.Command{
+		Use:   "inventory",
+		Short: "Lists the resources of every supported service",
+		Long: `Enumerates EC2 instances, S3 buckets, DynamoDB tables, RDS instances and
+snapshots, AutoScaling groups, CloudWatch alarms and log groups as records with
+the same columns: type, ID, name, region, state, tags and creation time.
+Use -o csv or -o json to export them, or --summary to count them.`,
+		Annotations: fanout.ReadOnly(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resourceTypes, err := inventory.ParseTypes(types)
+			if err != nil {
+				return err
+			}
+			records, err := inventory.Run(cmd.Context(), clients, regions, resourceTypes)
+			if records == nil {
+				return err
+			}
+
+			result := inventory.Result(records)
+			if summary {
+				result = inventory.Summary(records)
+			}
+			if printErr := output.Print(result); printErr != nil {
+				return printErr
+			}
+			return err
+		},
+	}
+
+	inventoryCmd.Flags().StringSliceVarP(&types, "types", "t", nil, "Only these resource types, e.g. ec2:instance,s3:bucket (default all)")
+	inventoryCmd.Flags().BoolVar(&summary, "summary", false, "Count the resources per type and region instead of listing them")
+	fanout.AddRegionsFlag(inventoryCmd, &regions)
+	inventoryCmd.RegisterFlagCompletionFunc("types", cobra.FixedCompletions(inventory.Types, cobra.ShellCompDirectiveNoFileComp))
+
+	return inventoryCmd
+}
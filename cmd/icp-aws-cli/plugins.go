@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/plugin"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// addPluginCommands registers a subcommand per plugin found on PATH. Built-in
+// commands take precedence over plugins of the same name.
+func addPluginCommands() {
+	for _, p := range plugin.Discover() {
+		if cmd, _, err := RootCmd.Find([]string{p.Name}); err == nil && cmd != RootCmd {
+			continue
+		}
+		RootCmd.AddCommand(newPluginCommand(p))
+	}
+}
+
+func newPluginCommand(p plugin.Plugin) *cobra.Command {
+	var pluginArgs []string
+
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              fmt.Sprintf("Plugin %s", p.Path),
+		DisableFlagParsing: true,
+		// The plugin reports its own usage errors
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Flags are not parsed for plugins: global flags come before the
+			// plugin name, everything after it belongs to the plugin
+			globals, rest := splitPluginArgs(commandArgs, p.Name)
+			if err := RootCmd.PersistentFlags().Parse(globals); err != nil {
+				return err
+			}
+			pluginArgs = rest
+			if len(profiles) > 0 {
+				return fmt.Errorf("--profiles is not supported by plugins")
+			}
+			// Merges the parsed global flags into cmd.Flags() for the configuration defaults
+			cmd.InheritedFlags()
+			return RootCmd.PersistentPreRunE(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			env := plugin.Env{
+				Profile:     clientOptions.Profile,
+				Region:      clients.Region,
+				EndpointURL: clientOptions.EndpointURL,
+				Output:      output.CurrentFormat(),
+				DryRun:      dryrun.Enabled(),
+				AssumeYes:   assumeYes,
+			}
+			if clientOptions.RoleARN != "" {
+				credentials, err := clients.Credentials(cmd.Context())
+				if err != nil {
+					return fmt.Errorf("could not assume role %s: %w", clientOptions.RoleARN, err)
+				}
+				env.Credentials = &credentials
+			}
+			return p.Run(cmd.Context(), pluginArgs, env)
+		},
+	}
+}
+
+// splitPluginArgs splits args at the plugin name into the global flags before it
+// and the plugin arguments after it.
+func splitPluginArgs(args []string, name string) ([]string, []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == name {
+			return args[:i], args[i+1:]
+		}
+		if !strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+			continue
+		}
+		if takesValue(arg) {
+			i++
+		}
+	}
+	return args, nil
+}
+
+// takesValue reports whether the global flag arg consumes the next argument.
+func takesValue(arg string) bool {
+	name := strings.TrimLeft(arg, "-")
+	flag := RootCmd.PersistentFlags().Lookup(name)
+	if flag == nil && !strings.HasPrefix(arg, "--") && len(name) == 1 {
+		flag = RootCmd.PersistentFlags().ShorthandLookup(name)
+	}
+	return flag != nil && flag.NoOptDefVal == ""
+}
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/spf13/cobra"
+)
+
+// run executes an rds subcommand against the fakes and returns what it printed
+// as text.
+func run(t *testing.T, clients *fake.Clients, args ...string) (string, error) {
+	t.Helper()
+	rdsCmd := &cobra.Command{Use: "rds", SilenceUsage: true, SilenceErrors: true}
+	collection := clients.Collection()
+	InitListCommands(collection, rdsCmd)
+	InitCreateCommands(collection, rdsCmd)
+	InitDeleteCommands(collection, rdsCmd)
+	InitStartStopCommands(collection, rdsCmd)
+
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+	rdsCmd.SetArgs(args)
+	err := rdsCmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func newFakeRDS() *fake.Clients {
+	clients := fake.New()
+	clients.RDS.DescribeDBInstancesFunc = func(context.Context, *rds.DescribeDBInstancesInput) (*rds.DescribeDBInstancesOutput, error) {
+		return &rds.DescribeDBInstancesOutput{DBInstances: []types.DBInstance{
+			{DBInstanceIdentifier: aws.String("web-db"), Engine: aws.String("postgres"), DBInstanceClass: aws.String("db.t3.micro"), DBInstanceStatus: aws.String("available")},
+		}}, nil
+	}
+	clients.RDS.DescribeDBSnapshotsFunc = func(context.Context, *rds.DescribeDBSnapshotsInput) (*rds.DescribeDBSnapshotsOutput, error) {
+		return &rds.DescribeDBSnapshotsOutput{DBSnapshots: []types.DBSnapshot{
+			{DBSnapshotIdentifier: aws.String("web-db-1"), DBInstanceIdentifier: aws.String("web-db"), Status: aws.String("available"),
+				SnapshotCreateTime: aws.Time(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))},
+		}}, nil
+	}
+	return clients
+}
+
+func TestListCommands(t *testing.T) {
+	tests := []struct {
+		args      []string
+		want      string
+		wantInput interface{}
+	}{
+		{
+			args:      []string{"list"},
+			want:      "web-db\tpostgres\tdb.t3.micro\tavailable\n",
+			wantInput: &rds.DescribeDBInstancesInput{},
+		},
+		{
+			args:      []string{"listSnapshots", "web-db"},
+			want:      "web-db-1\tweb-db\tavailable\t2026-01-02 03:04:05\n",
+			wantInput: &rds.DescribeDBSnapshotsInput{DBInstanceIdentifier: aws.String("web-db")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			clients := newFakeRDS()
+			got, err := run(t, clients, tt.args...)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if calls := clients.RDS.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0].Input, tt.wantInput) {
+				t.Errorf("calls = %+v, want %+v", calls, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestMutatingCommands(t *testing.T) {
+	confirm.SetAssumeYes(true)
+	t.Cleanup(func() { confirm.SetAssumeYes(false) })
+
+	tests := []struct {
+		args      []string
+		wantCall  string
+		wantInput interface{}
+	}{
+		{
+			args:     []string{"createInstance", `{"DBInstanceIdentifier": "web-db", "Engine": "postgres", "DBInstanceClass": "db.t3.micro", "AllocatedStorage": 20}`},
+			wantCall: "CreateDBInstance",
+			wantInput: &rds.CreateDBInstanceInput{
+				DBInstanceIdentifier: aws.String("web-db"), Engine: aws.String("postgres"), DBInstanceClass: aws.String("db.t3.micro"), AllocatedStorage: aws.Int32(20),
+			},
+		},
+		{
+			args:      []string{"createSnapshot", `{"DBInstanceIdentifier": "web-db", "DBSnapshotIdentifier": "web-db-1"}`},
+			wantCall:  "CreateDBSnapshot",
+			wantInput: &rds.CreateDBSnapshotInput{DBInstanceIdentifier: aws.String("web-db"), DBSnapshotIdentifier: aws.String("web-db-1")},
+		},
+		{
+			args:      []string{"deleteInstance", "web-db", "true"},
+			wantCall:  "DeleteDBInstance",
+			wantInput: &rds.DeleteDBInstanceInput{DBInstanceIdentifier: aws.String("web-db"), SkipFinalSnapshot: aws.Bool(true)},
+		},
+		{
+			args:      []string{"deleteSnapshot", "web-db-1"},
+			wantCall:  "DeleteDBSnapshot",
+			wantInput: &rds.DeleteDBSnapshotInput{DBSnapshotIdentifier: aws.String("web-db-1")},
+		},
+		{
+			args:      []string{"startInstance", "web-db"},
+			wantCall:  "StartDBInstance",
+			wantInput: &rds.StartDBInstanceInput{DBInstanceIdentifier: aws.String("web-db")},
+		},
+		{
+			args:      []string{"stopInstance", "web-db"},
+			wantCall:  "StopDBInstance",
+			wantInput: &rds.StopDBInstanceInput{DBInstanceIdentifier: aws.String("web-db")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantCall, func(t *testing.T) {
+			clients := newFakeRDS()
+			if _, err := run(t, clients, tt.args...); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			calls := clients.RDS.Calls()
+			if len(calls) == 0 || calls[len(calls)-1].Operation != tt.wantCall {
+				t.Fatalf("calls = %v, want %s last", clients.RDS.Operations(), tt.wantCall)
+			}
+			if input := calls[len(calls)-1].Input; !reflect.DeepEqual(input, tt.wantInput) {
+				t.Errorf("input = %+v, want %+v", input, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	tests := [][]string{
+		{"deleteInstance", "web-db", "maybe"},
+		{"createInstance", "{"},
+		{"createSnapshot"},
+	}
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			clients := newFakeRDS()
+			if _, err := run(t, clients, args...); err == nil {
+				t.Error("error = nil, want an invalid argument")
+			}
+			for _, operation := range clients.RDS.Operations() {
+				if !strings.HasPrefix(operation, "Describe") {
+					t.Errorf("%s called despite the invalid arguments", operation)
+				}
+			}
+		})
+	}
+}
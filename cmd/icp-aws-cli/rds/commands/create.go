@@ -4,18 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/dryrun"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/spf13/cobra"
 )
 
-func InitCreateCommands(rdsClient *rds.Client, rdsCmd *cobra.Command) {
+func InitCreateCommands(clients *awsclient.AWSClientCollection, rdsCmd *cobra.Command) {
 	createSnapshotCmd := &cobra.Command{
 		Use:   "createSnapshot",
 		Short: "Creates a database snapshot",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return createSnapshot(rdsClient, args[0])
+			return createSnapshot(cmd.Context(), clients.RDS, args[0])
 		},
 	}
 
@@ -24,7 +28,7 @@ func InitCreateCommands(rdsClient *rds.Client, rdsCmd *cobra.Command) {
 		Short: "Creates a new RDS instance",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return createInstance(rdsClient, args[0])
+			return createInstance(cmd.Context(), clients.RDS, args[0])
 		},
 	}
 
@@ -32,14 +36,19 @@ func InitCreateCommands(rdsClient *rds.Client, rdsCmd *cobra.Command) {
 	rdsCmd.AddCommand(createInstanceCmd)
 }
 
-func createSnapshot(rdsClient *rds.Client, configJSON string) error {
+func createSnapshot(ctx context.Context, rdsClient awsclient.RDSAPI, configJSON string) error {
 	var input rds.CreateDBSnapshotInput
 
 	if err := json.Unmarshal([]byte(configJSON), &input); err != nil {
 		return fmt.Errorf("error parsing input JSON: %w", err)
 	}
 
-	_, err := rdsClient.CreateDBSnapshot(context.TODO(), &input)
+	if dryrun.Skip("Would create snapshot %s", aws.ToString(input.DBSnapshotIdentifier)) {
+		return nil
+	}
+
+	_, err := rdsClient.CreateDBSnapshot(ctx, &input)
+	audit.Record(aws.ToString(input.DBSnapshotIdentifier), err)
 	if err != nil {
 		return fmt.Errorf("error creating snapshot: %w", err)
 	}
@@ -48,14 +57,19 @@ func createSnapshot(rdsClient *rds.Client, configJSON string) error {
 	return nil
 }
 
-func createInstance(rdsClient *rds.Client, configJSON string) error {
+func createInstance(ctx context.Context, rdsClient awsclient.RDSAPI, configJSON string) error {
 	var input rds.CreateDBInstanceInput
 
 	if err := json.Unmarshal([]byte(configJSON), &input); err != nil {
 		return fmt.Errorf("error parsing input JSON: %w", err)
 	}
 
-	_, err := rdsClient.CreateDBInstance(context.TODO(), &input)
+	if dryrun.Skip("Would create instance %s", aws.ToString(input.DBInstanceIdentifier)) {
+		return nil
+	}
+
+	_, err := rdsClient.CreateDBInstance(ctx, &input)
+	audit.Record(aws.ToString(input.DBInstanceIdentifier), err)
 	if err != nil {
 		return fmt.Errorf("error creating instance: %w", err)
 	}
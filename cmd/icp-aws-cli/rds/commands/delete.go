@@ -3,19 +3,23 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
 	"strconv"
 
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/spf13/cobra"
 )
 
-func InitDeleteCommands(rdsClient *rds.Client, rdsCmd *cobra.Command) {
+func InitDeleteCommands(clients *awsclient.AWSClientCollection, rdsCmd *cobra.Command) {
 	deleteInstanceCmd := &cobra.Command{
 		Use:   "deleteInstance",
 		Short: "Deletes an RDS instance",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return deleteInstance(rdsClient, args[0], args[1])
+			return deleteInstance(cmd.Context(), clients.RDS, args[0], args[1])
 		},
 	}
 
@@ -24,7 +28,7 @@ func InitDeleteCommands(rdsClient *rds.Client, rdsCmd *cobra.Command) {
 		Short: "Deletes a database snapshot",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return deleteSnapshot(rdsClient, args[0])
+			return deleteSnapshot(cmd.Context(), clients.RDS, args[0])
 		},
 	}
 
@@ -32,16 +36,25 @@ func InitDeleteCommands(rdsClient *rds.Client, rdsCmd *cobra.Command) {
 	rdsCmd.AddCommand(deleteSnapshotCmd)
 }
 
-func deleteInstance(rdsClient *rds.Client, databaseName string, skipFinalSnapshot string) error {
+func deleteInstance(ctx context.Context, rdsClient awsclient.RDSAPI, databaseName string, skipFinalSnapshot string) error {
 	skip, err := strconv.ParseBool(skipFinalSnapshot)
 	if err != nil {
 		return fmt.Errorf("invalid skip snapshot value: %w", err)
 	}
 
-	_, err = rdsClient.DeleteDBInstance(context.TODO(), &rds.DeleteDBInstanceInput{
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "delete", Kind: "DB instance", Targets: []string{databaseName}, Strict: true}); err != nil {
+		return err
+	}
+
+	if dryrun.Skip("Would delete instance %s (skip final snapshot: %t)", databaseName, skip) {
+		return nil
+	}
+
+	_, err = rdsClient.DeleteDBInstance(ctx, &rds.DeleteDBInstanceInput{
 		DBInstanceIdentifier: &databaseName,
 		SkipFinalSnapshot:    &skip,
 	})
+	audit.Record(databaseName, err)
 	if err != nil {
 		return fmt.Errorf("error deleting instance: %w", err)
 	}
@@ -50,10 +63,19 @@ func deleteInstance(rdsClient *rds.Client, databaseName string, skipFinalSnapsho
 	return nil
 }
 
-func deleteSnapshot(rdsClient *rds.Client, snapshotID string) error {
-	_, err := rdsClient.DeleteDBSnapshot(context.TODO(), &rds.DeleteDBSnapshotInput{
+func deleteSnapshot(ctx context.Context, rdsClient awsclient.RDSAPI, snapshotID string) error {
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "delete", Kind: "DB snapshot", Targets: []string{snapshotID}}); err != nil {
+		return err
+	}
+
+	if dryrun.Skip("Would delete snapshot %s", snapshotID) {
+		return nil
+	}
+
+	_, err := rdsClient.DeleteDBSnapshot(ctx, &rds.DeleteDBSnapshotInput{
 		DBSnapshotIdentifier: &snapshotID,
 	})
+	audit.Record(snapshotID, err)
 	if err != nil {
 		return fmt.Errorf("error deleting snapshot: %w", err)
 	}
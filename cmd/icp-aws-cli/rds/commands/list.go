@@ -3,55 +3,101 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
 	"github.com/spf13/cobra"
 )
 
-func InitListCommands(rdsClient *rds.Client, rdsCmd *cobra.Command) {
+func InitListCommands(clients *awsclient.AWSClientCollection, rdsCmd *cobra.Command) {
+	var listInstancesPaging, listSnapshotsPaging paging.Options
+	var regions []string
+
 	listInstancesCmd := &cobra.Command{
-		Use:   "list",
-		Short: "Lists RDS instances",
+		Use:         "list",
+		Short:       "Lists RDS instances",
+		Annotations: fanout.ReadOnly(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listInstances(rdsClient)
+			return fanout.Run(cmd.Context(), clients, regions, listInstancesPaging, func(ctx context.Context, clients *awsclient.AWSClientCollection) (*output.Result, string, error) {
+				return listInstances(ctx, clients.RDS, listInstancesPaging)
+			})
 		},
 	}
 
 	listSnapshotsCmd := &cobra.Command{
-		Use:   "listSnapshots",
-		Short: "Lists database snapshots",
-		Args:  cobra.ExactArgs(1),
+		Use:         "listSnapshots",
+		Short:       "Lists database snapshots",
+		Annotations: fanout.ReadOnly(),
+		Args:        cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listSnapshots(rdsClient, args[0])
+			return listSnapshots(cmd.Context(), clients.RDS, args[0], listSnapshotsPaging)
 		},
 	}
 
+	fanout.AddRegionsFlag(listInstancesCmd, &regions)
+	paging.AddFlags(listInstancesCmd, &listInstancesPaging, paging.Range{Min: 20, Max: 100})
+	paging.AddFlags(listSnapshotsCmd, &listSnapshotsPaging, paging.Range{Min: 20, Max: 100})
+
 	rdsCmd.AddCommand(listInstancesCmd)
 	rdsCmd.AddCommand(listSnapshotsCmd)
 }
 
-func listInstances(rdsClient *rds.Client) error {
-	result, err := rdsClient.DescribeDBInstances(context.TODO(), &rds.DescribeDBInstancesInput{})
+// listInstances returns the RDS instances of the region
+func listInstances(ctx context.Context, rdsClient awsclient.RDSAPI, pagingOpts paging.Options) (*output.Result, string, error) {
+	collector, err := paging.NewCollector[types.DBInstance](pagingOpts)
 	if err != nil {
-		return fmt.Errorf("error listing RDS instances: %w", err)
+		return nil, "", err
+	}
+
+	paginator := rds.NewDescribeDBInstancesPaginator(rdsClient, &rds.DescribeDBInstancesInput{
+		Marker: collector.StartToken(),
+	}, func(o *rds.DescribeDBInstancesPaginatorOptions) {
+		o.Limit = pagingOpts.PageSize
+	})
+	for paginator.HasMorePages() && !collector.Done() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("error listing RDS instances: %w", err)
+		}
+		collector.Add(page.DBInstances, page.Marker)
 	}
 
-	for _, instance := range result.DBInstances {
-		fmt.Println(*instance.DBInstanceIdentifier)
+	records := output.NewResult("DBInstanceIdentifier", "Engine", "DBInstanceClass", "Status")
+	for _, instance := range collector.Items() {
+		records.Add(*instance.DBInstanceIdentifier, aws.ToString(instance.Engine), aws.ToString(instance.DBInstanceClass), aws.ToString(instance.DBInstanceStatus))
 	}
-	return nil
+	return records, collector.NextToken(), nil
 }
 
-func listSnapshots(rdsClient *rds.Client, databaseID string) error {
-	result, err := rdsClient.DescribeDBSnapshots(context.TODO(), &rds.DescribeDBSnapshotsInput{
+func listSnapshots(ctx context.Context, rdsClient awsclient.RDSAPI, databaseID string, pagingOpts paging.Options) error {
+	collector, err := paging.NewCollector[types.DBSnapshot](pagingOpts)
+	if err != nil {
+		return err
+	}
+
+	paginator := rds.NewDescribeDBSnapshotsPaginator(rdsClient, &rds.DescribeDBSnapshotsInput{
 		DBInstanceIdentifier: &databaseID,
+		Marker:               collector.StartToken(),
+	}, func(o *rds.DescribeDBSnapshotsPaginatorOptions) {
+		o.Limit = pagingOpts.PageSize
 	})
-	if err != nil {
-		return fmt.Errorf("error listing snapshots: %w", err)
+	for paginator.HasMorePages() && !collector.Done() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing snapshots: %w", err)
+		}
+		collector.Add(page.DBSnapshots, page.Marker)
 	}
 
-	for _, snapshot := range result.DBSnapshots {
-		fmt.Println(*snapshot.DBSnapshotIdentifier)
+	records := output.NewResult("DBSnapshotIdentifier", "DBInstanceIdentifier", "Status", "SnapshotCreateTime")
+	for _, snapshot := range collector.Items() {
+		records.Add(*snapshot.DBSnapshotIdentifier, aws.ToString(snapshot.DBInstanceIdentifier), aws.ToString(snapshot.Status), aws.ToTime(snapshot.SnapshotCreateTime))
 	}
-	return nil
+	collector.PrintNextToken()
+	return output.Print(records)
 }
@@ -3,18 +3,22 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
 
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/spf13/cobra"
 )
 
-func InitStartStopCommands(rdsClient *rds.Client, rdsCmd *cobra.Command) {
+func InitStartStopCommands(clients *awsclient.AWSClientCollection, rdsCmd *cobra.Command) {
 	startInstanceCmd := &cobra.Command{
 		Use:   "startInstance",
 		Short: "Starts a stopped RDS instance",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return startInstance(rdsClient, args[0])
+			return startInstance(cmd.Context(), clients.RDS, args[0])
 		},
 	}
 
@@ -23,7 +27,7 @@ func InitStartStopCommands(rdsClient *rds.Client, rdsCmd *cobra.Command) {
 		Short: "Stops a running RDS instance",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return stopInstance(rdsClient, args[0])
+			return stopInstance(cmd.Context(), clients.RDS, args[0])
 		},
 	}
 
@@ -31,10 +35,15 @@ func InitStartStopCommands(rdsClient *rds.Client, rdsCmd *cobra.Command) {
 	rdsCmd.AddCommand(stopInstanceCmd)
 }
 
-func startInstance(rdsClient *rds.Client, instanceID string) error {
-	_, err := rdsClient.StartDBInstance(context.TODO(), &rds.StartDBInstanceInput{
+func startInstance(ctx context.Context, rdsClient awsclient.RDSAPI, instanceID string) error {
+	if dryrun.Skip("Would start instance %s", instanceID) {
+		return nil
+	}
+
+	_, err := rdsClient.StartDBInstance(ctx, &rds.StartDBInstanceInput{
 		DBInstanceIdentifier: &instanceID,
 	})
+	audit.Record(instanceID, err)
 	if err != nil {
 		return fmt.Errorf("error starting instance: %w", err)
 	}
@@ -43,10 +52,19 @@ func startInstance(rdsClient *rds.Client, instanceID string) error {
 	return nil
 }
 
-func stopInstance(rdsClient *rds.Client, instanceID string) error {
-	_, err := rdsClient.StopDBInstance(context.TODO(), &rds.StopDBInstanceInput{
+func stopInstance(ctx context.Context, rdsClient awsclient.RDSAPI, instanceID string) error {
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "stop", Kind: "DB instance", Targets: []string{instanceID}}); err != nil {
+		return err
+	}
+
+	if dryrun.Skip("Would stop instance %s", instanceID) {
+		return nil
+	}
+
+	_, err := rdsClient.StopDBInstance(ctx, &rds.StopDBInstanceInput{
 		DBInstanceIdentifier: &instanceID,
 	})
+	audit.Record(instanceID, err)
 	if err != nil {
 		return fmt.Errorf("error stopping instance: %w", err)
 	}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
+	"icp-aws-cli/pkg/ui"
+)
+
+// InstancesView browses the DB instances in the ui command. Deleting keeps a
+// final snapshot.
+func InstancesView(clients *awsclient.AWSClientCollection) ui.View {
+	return ui.View{
+		Name: "Instances",
+		ID:   "DBInstanceIdentifier",
+		List: func(ctx context.Context) (*output.Result, error) {
+			result, _, err := listInstances(ctx, clients.RDS, paging.Options{})
+			return result, err
+		},
+		Actions: []ui.Action{
+			{Key: 's', Name: "start", Args: func(id string) []string {
+				return []string{"rds", "startInstance", id}
+			}},
+			{Key: 't', Name: "stop", Args: func(id string) []string {
+				return []string{"rds", "stopInstance", id}
+			}},
+			{Key: 'd', Name: "delete", Args: func(id string) []string {
+				return []string{"rds", "deleteInstance", id, "false"}
+			}},
+		},
+	}
+}
@@ -2,12 +2,13 @@ package rds
 
 import (
 	"icp-aws-cli/cmd/icp-aws-cli/rds/commands"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/ui"
 
-	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/spf13/cobra"
 )
 
-func InitCommands(rdsClient *rds.Client) *cobra.Command {
+func InitCommands(clients *awsclient.AWSClientCollection) *cobra.Command {
 	rdsCmd := &cobra.Command{
 		Use:   "rds",
 		Short: "Commands to interact with Amazon RDS",
@@ -15,10 +16,15 @@ func InitCommands(rdsClient *rds.Client) *cobra.Command {
 	}
 
 	// Initialize subcommands
-	commands.InitListCommands(rdsClient, rdsCmd)
-	commands.InitCreateCommands(rdsClient, rdsCmd)
-	commands.InitDeleteCommands(rdsClient, rdsCmd)
-	commands.InitStartStopCommands(rdsClient, rdsCmd)
+	commands.InitListCommands(clients, rdsCmd)
+	commands.InitCreateCommands(clients, rdsCmd)
+	commands.InitDeleteCommands(clients, rdsCmd)
+	commands.InitStartStopCommands(clients, rdsCmd)
 
 	return rdsCmd
 }
+
+// Views returns the resources of the service browsed by the ui command.
+func Views(clients *awsclient.AWSClientCollection) []ui.View {
+	return []ui.View{commands.InstancesView(clients)}
+}
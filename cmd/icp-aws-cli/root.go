@@ -1,36 +1,356 @@
 package commands
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"icp-aws-cli/cmd/icp-aws-cli/audit"
 	"icp-aws-cli/cmd/icp-aws-cli/autoscaling"
 	"icp-aws-cli/cmd/icp-aws-cli/cloudwatch"
+	completioncmd "icp-aws-cli/cmd/icp-aws-cli/completion"
 	"icp-aws-cli/cmd/icp-aws-cli/dynamodb"
 	"icp-aws-cli/cmd/icp-aws-cli/ec2"
+	"icp-aws-cli/cmd/icp-aws-cli/inventory"
 	"icp-aws-cli/cmd/icp-aws-cli/rds"
 	"icp-aws-cli/cmd/icp-aws-cli/s3"
+	stackcmd "icp-aws-cli/cmd/icp-aws-cli/stack"
+	"icp-aws-cli/cmd/icp-aws-cli/state"
+	"icp-aws-cli/cmd/icp-aws-cli/tag"
+	auditlog "icp-aws-cli/pkg/audit"
 	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/completion"
+	"icp-aws-cli/pkg/config"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/exitcode"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/hint"
+	"icp-aws-cli/pkg/output"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/spf13/cobra"
 )
 
+var outputFormat string
+var dryRun bool
+var assumeYes bool
+var timeout time.Duration
+var configProfile string
+var logFile string
+var errorFormat string
+var profiles []string
+
+// envErrorFormat selects the error format when --error-format is not given, so
+// that errors reported before the flags are parsed follow it too.
+const envErrorFormat = "ICP_AWS_CLI_ERROR_FORMAT"
+
+// commandArgs is the command line after alias expansion, replayed per profile by --profiles.
+var commandArgs []string
+
+// userConfig is loaded from the configuration file before the command line is parsed.
+var userConfig = &config.Config{}
+
+// cancelTimeout releases the --timeout context once the command has returned.
+var cancelTimeout context.CancelFunc = func() {}
+var clientOptions awsclient.Options
+
+// clients is shared by every command and populated in PersistentPreRunE,
+// once the global flags have been parsed.
+var clients = &awsclient.AWSClientCollection{}
+
+// clientsKey identifies the options clients were built with, so the shell
+// reuses them until a global flag changes.
+var clientsKey string
+
 var RootCmd = &cobra.Command{
 	Use:   "icp-aws-cli",
 	Short: "CLI to interact with AWS",
 	Long:  "A CLI in Go to manage AWS resources from EC2, S3, DynamoDB, AutoScaling, RDS and CloudWatch.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := userConfig.ApplyDefaults(cmd, configProfile); err != nil {
+			return err
+		}
+
+		format, err := output.ParseFormat(outputFormat)
+		if err != nil {
+			return exitcode.UsageError(err)
+		}
+		output.SetFormat(format)
+		if errorFormat != "text" && errorFormat != "json" {
+			return exitcode.UsageError(fmt.Errorf("invalid error format %q (valid values: text, json)", errorFormat))
+		}
+		dryrun.SetEnabled(dryRun)
+		confirm.SetAssumeYes(assumeYes)
+
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(ctx)
+			cancelTimeout = cancel
+		}
+
+		if len(profiles) > 0 {
+			return setupProfilesFanout(cmd)
+		}
+		return initClients(cmd.Context())
+	},
 }
 
-func InitCommands(clients *awsclient.AWSClientCollection) {
-	RootCmd.AddCommand(s3.InitCommands(clients.S3))
-	RootCmd.AddCommand(ec2.InitCommands(clients.EC2))
-	RootCmd.AddCommand(dynamodb.InitCommands(clients.DynamoDB))
-	RootCmd.AddCommand(rds.InitCommands(clients.RDS))
-	RootCmd.AddCommand(cloudwatch.InitCommands(clients.CloudWatch, clients.CloudWatchLogs))
-	RootCmd.AddCommand(autoscaling.InitCommands(clients.AutoScaling))
+// setupProfilesFanout replaces the command with one run per --profiles entry.
+func setupProfilesFanout(cmd *cobra.Command) error {
+	if !fanout.IsReadOnly(cmd) {
+		return fmt.Errorf("--profiles is only supported by read-only commands such as list and describe")
+	}
+	// A profile from the configuration file is only a default, replaced per run
+	if cmd.Flags().Changed("profile") {
+		return fmt.Errorf("--profile cannot be combined with --profiles")
+	}
+	names, err := fanout.Profiles(profiles)
+	if err != nil {
+		return err
+	}
+
+	args := fanout.StripFlag(commandArgs, "profiles")
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		// Restored for the next command line of the shell
+		cmd.RunE = run
+		return fanout.RunProfiles(cmd.Context(), clientOptions, names, args)
+	}
+	return nil
+}
+
+// initClients fills the shared client collection from the global flags.
+func initClients(ctx context.Context) error {
+	if logFile != "" && clientOptions.LogOutput == nil {
+		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("could not open log file: %w", err)
+		}
+		clientOptions.LogOutput = file
+	}
+	if clientOptions.Credentials == nil {
+		// Handed over when the command runs for one profile of --profiles
+		session, err := fanout.Session()
+		if err != nil {
+			return err
+		}
+		clientOptions.Credentials = session
+	}
+
+	key := fmt.Sprintf("%+v", clientOptions)
+	if key == clientsKey {
+		return nil
+	}
+	collection, err := awsclient.NewAWSClientCollection(ctx, clientOptions)
+	if err != nil {
+		return err
+	}
+	*clients = *collection
+	clientsKey = key
+	return nil
+}
+
+// prepareCompletion readies the clients for dynamic completion, which runs
+// without PersistentPreRunE. The cache scope separates accounts and regions.
+func prepareCompletion(cmd *cobra.Command) (string, error) {
+	if err := userConfig.ApplyDefaults(cmd, configProfile); err != nil {
+		return "", err
+	}
+	// Completion cannot prompt; an expired MFA session is renewed by the next command
+	clientOptions.MFATokenProvider = func() (string, error) {
+		return "", fmt.Errorf("the MFA session has expired")
+	}
+	if err := initClients(cmd.Context()); err != nil {
+		return "", err
+	}
+	return strings.Join([]string{clientOptions.Profile, clientOptions.RoleARN, clients.Region, clientOptions.EndpointURL}, "|"), nil
+}
+
+func init() {
+	// Replaced by the completion command, limited to the shells we support
+	RootCmd.CompletionOptions.DisableDefaultCmd = true
+	completion.SetPrepare(prepareCompletion)
+
+	// Errors are reported by runCommand, with the usage only for command line errors
+	RootCmd.SilenceErrors = true
+	RootCmd.SilenceUsage = true
+	RootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return exitcode.UsageError(err)
+	})
+
+	RootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", string(output.FormatTable), "Output format (table, json, yaml, text, csv)")
+	RootCmd.PersistentFlags().StringVar(&configProfile, "config-profile", "", "Profile of defaults from the configuration file (e.g. staging, prod)")
+	RootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Resolve targets and print the planned operations without changing any resource")
+	RootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Skip confirmation prompts for destructive commands (e.g. in CI)")
+	RootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Maximum duration of the whole command, e.g. 30s or 5m (0 means no limit)")
+	RootCmd.PersistentFlags().StringVar(&clientOptions.Profile, "profile", "", "Shared config profile to use")
+	RootCmd.PersistentFlags().StringSliceVar(&profiles, "profiles", nil, "Run a read-only command once per shared config profile, e.g. dev,prod or 'prod-*'")
+	RootCmd.PersistentFlags().StringVar(&clientOptions.Region, "region", "", "AWS region to use")
+	RootCmd.PersistentFlags().StringVar(&clientOptions.EndpointURL, "endpoint-url", "", "Endpoint URL used for every service (e.g. http://localhost:4566)")
+	RootCmd.PersistentFlags().StringVar(&clientOptions.RoleARN, "role-arn", "", "ARN of a role to assume with the resolved credentials")
+	RootCmd.PersistentFlags().StringVar(&clientOptions.ExternalID, "external-id", "", "External ID required by the trust policy of --role-arn")
+	RootCmd.PersistentFlags().StringVar(&clientOptions.MFASerial, "mfa-serial", "", "ARN of the MFA device required by --role-arn; the token code is prompted once per session")
+	RootCmd.PersistentFlags().DurationVar(&clientOptions.SessionDuration, "session-duration", time.Hour, "Duration of the assumed-role session, cached until it expires (e.g. 1h, 12h)")
+	RootCmd.PersistentFlags().StringToStringVar(&clientOptions.ServiceEndpoints, "service-endpoint-url", nil, "Endpoint URL override for a single service (service=url, e.g. dynamodb=http://localhost:8000)")
+	RootCmd.PersistentFlags().IntVar(&clientOptions.MaxRetries, "max-retries", -1, "Maximum number of retries per AWS request (-1 uses the SDK default)")
+	RootCmd.PersistentFlags().StringVar(&clientOptions.RetryMode, "retry-mode", "", "Retry mode: standard or adaptive (adaptive also slows down on throttling)")
+	RootCmd.PersistentFlags().IntVar(&clientOptions.RateLimit, "rate-limit", 0, "Maximum requests per second sent to each service (0 means no limit)")
+	RootCmd.PersistentFlags().StringToIntVar(&clientOptions.ServiceRateLimits, "service-rate-limit", nil, "Requests per second limit for a single service (service=n, e.g. logs=5)")
+	RootCmd.PersistentFlags().BoolVar(&clientOptions.Verbose, "verbose", false, "Log every AWS API call with its latency")
+	RootCmd.PersistentFlags().BoolVar(&clientOptions.Debug, "debug", false, "Also log SDK requests, responses, retries and signing")
+	RootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Write --verbose and --debug logs to this file as JSON lines instead of stderr")
+	RootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", "text", "Format of errors on stderr: text, or json for an object with the exit code, AWS error code, request ID and resource")
+}
+
+func InitCommands() {
+	RootCmd.AddCommand(s3.InitCommands(clients))
+	RootCmd.AddCommand(ec2.InitCommands(clients))
+	RootCmd.AddCommand(dynamodb.InitCommands(clients))
+	RootCmd.AddCommand(rds.InitCommands(clients))
+	RootCmd.AddCommand(cloudwatch.InitCommands(clients))
+	RootCmd.AddCommand(autoscaling.InitCommands(clients))
+	stackcmd.InitCommands(clients, RootCmd)
+	RootCmd.AddCommand(inventory.InitCommands(clients))
+	RootCmd.AddCommand(state.InitCommands(clients))
+	RootCmd.AddCommand(tag.InitCommands(clients))
+	RootCmd.AddCommand(audit.InitCommands())
+	RootCmd.AddCommand(completioncmd.InitCommands())
+	RootCmd.AddCommand(newShellCommand())
+	RootCmd.AddCommand(newUICommand())
+	addPluginCommands()
+	markUsageErrors(RootCmd)
+}
+
+// markUsageErrors classifies the argument validation errors of cmd and its
+// subcommands as command line errors.
+func markUsageErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			return exitcode.UsageError(validate(cmd, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
 }
 
 func Execute() {
-	// cobra.CheckErr(RootCmd.Execute()) Removed as it prints the error message again before exiting
-	if err := RootCmd.Execute(); err != nil {
-		os.Exit(1)
+	cfg, err := config.Load()
+	if err != nil {
+		reportError(nil, err)
+		os.Exit(exitcode.Of(err))
+	}
+	userConfig = cfg
+
+	// Ctrl-C and SIGTERM cancel the context shared by every AWS call, so bulk operations stop cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = runCommand(ctx, os.Args[1:])
+	stop()
+	os.Exit(exitcode.Of(err))
+}
+
+// runCommand executes one command line, once per process or once per line of
+// the shell, and writes its audit entry.
+func runCommand(ctx context.Context, args []string) error {
+	expanded, err := userConfig.ExpandAlias(RootCmd, args)
+	if err != nil {
+		reportError(nil, err)
+		return err
+	}
+	commandArgs = expanded
+	RootCmd.SetArgs(commandArgs)
+
+	cmd, err := RootCmd.ExecuteContextC(ctx)
+	cancelTimeout()
+	cancelTimeout = func() {}
+	if auditlog.Pending() {
+		if err := writeAuditEntry(cmd); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning:", err)
+		}
+	}
+	if err != nil {
+		// Unknown commands are reported by cobra before any flag or argument validation
+		if _, _, findErr := RootCmd.Find(commandArgs); findErr != nil {
+			err = exitcode.UsageError(err)
+			cmd = nil
+		}
+		reportError(cmd, err)
+	}
+	return err
+}
+
+// errorReport is the JSON error object of --error-format json.
+type errorReport struct {
+	exitcode.Details
+	Hint       string `json:"hint,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// reportError prints err on stderr as text, followed by a hint for common
+// failures and the usage of cmd (or a pointer to the help without cmd) for
+// command line errors, or as a JSON object with --error-format json.
+func reportError(cmd *cobra.Command, err error) {
+	report := errorReport{Details: exitcode.Classify(err)}
+	// The command context may be cancelled already, e.g. after a timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if h := hint.For(ctx, clients.STS, err, hint.Command{Name: RootCmd.Name(), Args: commandArgs, Profile: clientOptions.Profile}); h != nil {
+		report.Hint, report.Suggestion = h.Explanation, h.Suggestion
+	}
+
+	format := errorFormat
+	if !RootCmd.PersistentFlags().Changed("error-format") && os.Getenv(envErrorFormat) != "" {
+		format = os.Getenv(envErrorFormat)
+	}
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stderr)
+		encoder.SetEscapeHTML(false)
+		if encodeErr := encoder.Encode(map[string]errorReport{"error": report}); encodeErr == nil {
+			return
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	if report.Hint != "" {
+		fmt.Fprintln(os.Stderr, "Hint:", report.Hint)
+		fmt.Fprintln(os.Stderr, "Try: ", report.Suggestion)
+	}
+	details := report.Details
+	switch {
+	case details.ExitCode != exitcode.Usage:
+	case cmd != nil:
+		fmt.Fprintln(os.Stderr, cmd.UsageString())
+	default:
+		fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", RootCmd.CommandPath())
 	}
 }
+
+// writeAuditEntry appends the mutating operations of cmd, which just ran, to
+// the audit log. The caller identity is looked up with a fresh context so that
+// interrupted and timed out commands are logged too.
+func writeAuditEntry(cmd *cobra.Command) error {
+	if cmd == nil {
+		cmd = RootCmd
+	}
+	entry := auditlog.Entry{
+		Profile: clientOptions.Profile,
+		Region:  clients.Region,
+		Command: auditlog.Command(cmd),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if clients.STS != nil {
+		if identity, err := clients.STS.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}); err == nil {
+			entry.Identity = aws.ToString(identity.Arn)
+			entry.Account = aws.ToString(identity.Account)
+		}
+	}
+
+	return auditlog.Flush(entry)
+}
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/spf13/cobra"
+)
+
+var created = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// run executes an s3 subcommand against the fakes and returns what it printed
+// as text.
+func run(t *testing.T, clients *fake.Clients, args ...string) (string, error) {
+	t.Helper()
+	s3Cmd := &cobra.Command{Use: "s3", SilenceUsage: true, SilenceErrors: true}
+	collection := clients.Collection()
+	InitCopyCommands(collection, s3Cmd)
+	InitDeleteCommands(collection, s3Cmd)
+	InitListCommands(collection, s3Cmd)
+	InitCreateCommands(collection, s3Cmd)
+
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+	s3Cmd.SetArgs(args)
+	err := s3Cmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func newFakeS3() *fake.Clients {
+	clients := fake.New()
+	clients.S3.ListBucketsFunc = func(context.Context, *s3.ListBucketsInput) (*s3.ListBucketsOutput, error) {
+		return &s3.ListBucketsOutput{Buckets: []types.Bucket{
+			{Name: aws.String("web-assets"), CreationDate: aws.Time(created)},
+			{Name: aws.String("web-logs"), CreationDate: aws.Time(created)},
+		}}, nil
+	}
+	clients.S3.ListObjectsV2Func = func(context.Context, *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+		return &s3.ListObjectsV2Output{Contents: []types.Object{
+			{Key: aws.String("index.html"), Size: aws.Int64(512), LastModified: aws.Time(created)},
+			{Key: aws.String("app.js"), Size: aws.Int64(2048), LastModified: aws.Time(created)},
+		}}, nil
+	}
+	return clients
+}
+
+func TestListCommands(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{
+			args: []string{"list"},
+			want: "web-assets\t2026-01-02 03:04:05\nweb-logs\t2026-01-02 03:04:05\n",
+		},
+		{
+			args: []string{"listObjects", "web-assets"},
+			want: "index.html\t512\t2026-01-02 03:04:05\napp.js\t2048\t2026-01-02 03:04:05\n",
+		},
+		{
+			args: []string{"listObjectsByExtension", "web-assets", "js"},
+			want: "app.js\t2048\t2026-01-02 03:04:05\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := run(t, newFakeS3(), tt.args...)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListError(t *testing.T) {
+	clients := fake.New()
+	clients.S3.ListBucketsFunc = func(context.Context, *s3.ListBucketsInput) (*s3.ListBucketsOutput, error) {
+		return nil, errors.New("access denied")
+	}
+	if _, err := run(t, clients, "list"); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Errorf("error = %v, want the API error", err)
+	}
+}
+
+func TestMutatingCommands(t *testing.T) {
+	confirm.SetAssumeYes(true)
+	t.Cleanup(func() { confirm.SetAssumeYes(false) })
+
+	tests := []struct {
+		args      []string
+		wantCall  string
+		wantInput interface{}
+	}{
+		{
+			args:      []string{"createBucket", "web-assets"},
+			wantCall:  "CreateBucket",
+			wantInput: &s3.CreateBucketInput{Bucket: aws.String("web-assets")},
+		},
+		{
+			args:      []string{"deleteBucket", "web-assets"},
+			wantCall:  "DeleteBucket",
+			wantInput: &s3.DeleteBucketInput{Bucket: aws.String("web-assets")},
+		},
+		{
+			args:      []string{"deleteObject", "web-assets", "index.html"},
+			wantCall:  "DeleteObject",
+			wantInput: &s3.DeleteObjectInput{Bucket: aws.String("web-assets"), Key: aws.String("index.html")},
+		},
+		{
+			args:      []string{"copyObject", "web-assets", "index.html", "web-backup", "index.html.bak"},
+			wantCall:  "CopyObject",
+			wantInput: &s3.CopyObjectInput{Bucket: aws.String("web-backup"), CopySource: aws.String("web-assets/index.html"), Key: aws.String("index.html.bak")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			clients := fake.New()
+			if _, err := run(t, clients, tt.args...); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			calls := clients.S3.Calls()
+			if len(calls) != 1 || calls[0].Operation != tt.wantCall {
+				t.Fatalf("calls = %v, want %s", clients.S3.Operations(), tt.wantCall)
+			}
+			if !reflect.DeepEqual(calls[0].Input, tt.wantInput) {
+				t.Errorf("input = %+v, want %+v", calls[0].Input, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestArgumentsChecked(t *testing.T) {
+	for _, args := range [][]string{{"deleteBucket"}, {"deleteObject", "web-assets"}, {"copyObject", "a", "b", "c"}} {
+		clients := fake.New()
+		if _, err := run(t, clients, args...); err == nil {
+			t.Errorf("%v: error = nil, want an argument error", args)
+		}
+		if operations := clients.S3.Operations(); len(operations) != 0 {
+			t.Errorf("%v: operations = %v, want none", args, operations)
+		}
+	}
+}
+
+func TestDryRunSkipsMutations(t *testing.T) {
+	dryrun.SetEnabled(true)
+	t.Cleanup(func() { dryrun.SetEnabled(false) })
+
+	for _, args := range [][]string{
+		{"createBucket", "web-assets"},
+		{"deleteBucket", "web-assets"},
+		{"deleteObject", "web-assets", "index.html"},
+		{"copyObject", "web-assets", "index.html", "web-backup", "index.html"},
+	} {
+		t.Run(args[0], func(t *testing.T) {
+			clients := newFakeS3()
+			if _, err := run(t, clients, args...); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if ops := clients.S3.Operations(); len(ops) != 0 {
+				t.Errorf("calls = %v, want none in dry-run mode", ops)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/completion"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// completeBucketNames suggests bucket names
+func completeBucketNames(clients *awsclient.AWSClientCollection) completion.Func {
+	return completion.Resources("s3-buckets", func(ctx context.Context) ([]string, error) {
+		names := []string{}
+		paginator := s3.NewListBucketsPaginator(clients.S3, &s3.ListBucketsInput{})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("error listing buckets: %w", err)
+			}
+			for _, bucket := range page.Buckets {
+				names = append(names, *bucket.Name)
+			}
+		}
+		return names, nil
+	})
+}
@@ -3,31 +3,41 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/completion"
+	"icp-aws-cli/pkg/dryrun"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/spf13/cobra"
 )
 
-func InitCopyCommands(s3Client *s3.Client, s3Command *cobra.Command) {
+func InitCopyCommands(clients *awsclient.AWSClientCollection, s3Command *cobra.Command) {
 	copyObjectCmd := &cobra.Command{
-		Use:   "copyObject",
-		Short: "Copies an object from one bucket to another",
-		Args:  cobra.ExactArgs(4),
+		Use:               "copyObject",
+		Short:             "Copies an object from one bucket to another",
+		Args:              cobra.ExactArgs(4),
+		ValidArgsFunction: completion.Args(map[int]completion.Func{0: completeBucketNames(clients), 2: completeBucketNames(clients)}),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return copyObject(s3Client, args[0], args[1], args[2], args[3])
+			return copyObject(cmd.Context(), clients.S3, args[0], args[1], args[2], args[3])
 		},
 	}
 
 	s3Command.AddCommand(copyObjectCmd)
 }
 
-func copyObject(s3Client *s3.Client, srcBucket string, srcKey string, destBucket string, destKey string) error {
+func copyObject(ctx context.Context, s3Client awsclient.S3API, srcBucket string, srcKey string, destBucket string, destKey string) error {
+	if dryrun.Skip("Would copy object %s from bucket %s to bucket %s as %s", srcKey, srcBucket, destBucket, destKey) {
+		return nil
+	}
+
 	copySource := fmt.Sprintf("%s/%s", srcBucket, srcKey)
-	_, err := s3Client.CopyObject(context.TODO(), &s3.CopyObjectInput{
+	_, err := s3Client.CopyObject(ctx, &s3.CopyObjectInput{
 		Bucket:     &destBucket,
 		CopySource: &copySource,
 		Key:        &destKey,
 	})
+	audit.Record(destBucket+"/"+destKey, err)
 	if err != nil {
 		return fmt.Errorf("error copying object %s from bucket %s to %s: %w", srcKey, srcBucket, destBucket, err)
 	}
@@ -3,28 +3,36 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/dryrun"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/spf13/cobra"
 )
 
-func InitCreateCommands(s3Client *s3.Client, s3Command *cobra.Command) {
+func InitCreateCommands(clients *awsclient.AWSClientCollection, s3Command *cobra.Command) {
 	createBucketCmd := &cobra.Command{
 		Use:   "createBucket",
 		Short: "Creates a new S3 bucket",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return createBucket(s3Client, args[0])
+			return createBucket(cmd.Context(), clients.S3, args[0])
 		},
 	}
 
 	s3Command.AddCommand(createBucketCmd)
 }
 
-func createBucket(s3Client *s3.Client, bucketName string) error {
-	_, err := s3Client.CreateBucket(context.TODO(), &s3.CreateBucketInput{
+func createBucket(ctx context.Context, s3Client awsclient.S3API, bucketName string) error {
+	if dryrun.Skip("Would create bucket %s", bucketName) {
+		return nil
+	}
+
+	_, err := s3Client.CreateBucket(ctx, &s3.CreateBucketInput{
 		Bucket: &bucketName,
 	})
+	audit.Record(bucketName, err)
 	if err != nil {
 		return fmt.Errorf("failed to create bucket %s: %v", bucketName, err)
 	}
@@ -3,27 +3,34 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/completion"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/spf13/cobra"
 )
 
-func InitDeleteCommands(s3Client *s3.Client, s3Command *cobra.Command) {
+func InitDeleteCommands(clients *awsclient.AWSClientCollection, s3Command *cobra.Command) {
 	deleteObjectCmd := &cobra.Command{
-		Use:   "deleteObject",
-		Short: "Deletes a specific object from an S3 bucket",
-		Args:  cobra.ExactArgs(2),
+		Use:               "deleteObject",
+		Short:             "Deletes a specific object from an S3 bucket",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completion.Args(map[int]completion.Func{0: completeBucketNames(clients)}),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return deleteObject(s3Client, args[0], args[1])
+			return deleteObject(cmd.Context(), clients.S3, args[0], args[1])
 		},
 	}
 
 	deleteBucketCmd := &cobra.Command{
-		Use:   "deleteBucket",
-		Short: "Deletes an S3 bucket",
-		Args:  cobra.ExactArgs(1),
+		Use:               "deleteBucket",
+		Short:             "Deletes an S3 bucket",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completion.Args(map[int]completion.Func{0: completeBucketNames(clients)}),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return deleteBucket(s3Client, args[0])
+			return deleteBucket(cmd.Context(), clients.S3, args[0])
 		},
 	}
 
@@ -31,10 +38,19 @@ func InitDeleteCommands(s3Client *s3.Client, s3Command *cobra.Command) {
 	s3Command.AddCommand(deleteBucketCmd)
 }
 
-func deleteBucket(s3Client *s3.Client, bucketName string) error {
-	_, err := s3Client.DeleteBucket(context.TODO(), &s3.DeleteBucketInput{
+func deleteBucket(ctx context.Context, s3Client awsclient.S3API, bucketName string) error {
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "delete", Kind: "bucket", Targets: []string{bucketName}, Strict: true}); err != nil {
+		return err
+	}
+
+	if dryrun.Skip("Would delete bucket %s", bucketName) {
+		return nil
+	}
+
+	_, err := s3Client.DeleteBucket(ctx, &s3.DeleteBucketInput{
 		Bucket: &bucketName,
 	})
+	audit.Record(bucketName, err)
 	if err != nil {
 		return fmt.Errorf("failed to delete bucket %s: %v", bucketName, err)
 	}
@@ -43,11 +59,20 @@ func deleteBucket(s3Client *s3.Client, bucketName string) error {
 	return nil
 }
 
-func deleteObject(s3Client *s3.Client, bucketName string, objectKey string) error {
-	_, err := s3Client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+func deleteObject(ctx context.Context, s3Client awsclient.S3API, bucketName string, objectKey string) error {
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "delete", Kind: "object", Targets: []string{bucketName + "/" + objectKey}}); err != nil {
+		return err
+	}
+
+	if dryrun.Skip("Would delete object %s from bucket %s", objectKey, bucketName) {
+		return nil
+	}
+
+	_, err := s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: &bucketName,
 		Key:    &objectKey,
 	})
+	audit.Record(bucketName+"/"+objectKey, err)
 	if err != nil {
 		return fmt.Errorf("error deleting object %s: %w", objectKey, err)
 	}
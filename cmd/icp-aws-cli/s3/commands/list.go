@@ -3,78 +3,129 @@ package commands
 import (
 	"context"
 	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/completion"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
 	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/spf13/cobra"
 )
 
-func InitListCommands(s3Client *s3.Client, s3Command *cobra.Command) {
+func InitListCommands(clients *awsclient.AWSClientCollection, s3Command *cobra.Command) {
+	var listBucketsPaging, listObjectsPaging, listObjectsByExtensionPaging paging.Options
+
 	listBucketsCmd := &cobra.Command{
-		Use:   "list",
-		Short: "Lists S3 buckets",
+		Use:         "list",
+		Short:       "Lists S3 buckets",
+		Annotations: fanout.ReadOnly(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listBuckets(s3Client)
+			result, token, err := listBuckets(cmd.Context(), clients.S3, listBucketsPaging)
+			if err != nil {
+				return err
+			}
+			paging.PrintNextToken(token)
+			return output.Print(result)
 		},
 	}
 
 	listObjectsCmd := &cobra.Command{
-		Use:   "listObjects",
-		Short: "Lists all objects of an S3 bucket",
-		Args:  cobra.ExactArgs(1),
+		Use:               "listObjects",
+		Short:             "Lists all objects of an S3 bucket",
+		Annotations:       fanout.ReadOnly(),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completion.Args(map[int]completion.Func{0: completeBucketNames(clients)}),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listObjects(s3Client, args[0])
+			return listObjects(cmd.Context(), clients.S3, args[0], "", listObjectsPaging)
 		},
 	}
 
 	listObjectsByExtensionCmd := &cobra.Command{
-		Use:   "listObjectsByExtension",
-		Short: "Lists all objects in a bucket with a specific file extension",
-		Args:  cobra.ExactArgs(2),
+		Use:               "listObjectsByExtension",
+		Short:             "Lists all objects in a bucket with a specific file extension",
+		Annotations:       fanout.ReadOnly(),
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completion.Args(map[int]completion.Func{0: completeBucketNames(clients)}),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listObjectsByExtension(s3Client, args[0], args[1])
+			return listObjects(cmd.Context(), clients.S3, args[0], args[1], listObjectsByExtensionPaging)
 		},
 	}
 
+	paging.AddFlags(listBucketsCmd, &listBucketsPaging, paging.Range{Min: 1, Max: 10000})
+	paging.AddFlags(listObjectsCmd, &listObjectsPaging, paging.Range{Min: 1, Max: 1000})
+	paging.AddFlags(listObjectsByExtensionCmd, &listObjectsByExtensionPaging, paging.Range{Min: 1, Max: 1000})
+
 	s3Command.AddCommand(listBucketsCmd)
 	s3Command.AddCommand(listObjectsCmd)
 	s3Command.AddCommand(listObjectsByExtensionCmd)
 }
 
-func listBuckets(s3Client *s3.Client) error {
-	result, err := s3Client.ListBuckets(context.TODO(), &s3.ListBucketsInput{})
+// listBuckets returns the buckets of the account
+func listBuckets(ctx context.Context, s3Client awsclient.S3API, pagingOpts paging.Options) (*output.Result, string, error) {
+	collector, err := paging.NewCollector[types.Bucket](pagingOpts)
 	if err != nil {
-		return fmt.Errorf("error listing buckets: %w", err)
-	}
-
-	for _, bucket := range result.Buckets {
-		fmt.Println(*bucket.Name)
+		return nil, "", err
 	}
-	return nil
-}
 
-func listObjects(s3Client *s3.Client, bucketName string) error {
-	result, err := s3Client.ListObjectsV2(context.TODO(), &s3.ListObjectsV2Input{Bucket: &bucketName})
-	if err != nil {
-		return fmt.Errorf("error listing objects: %w", err)
+	paginator := s3.NewListBucketsPaginator(s3Client, &s3.ListBucketsInput{
+		ContinuationToken: collector.StartToken(),
+	}, func(o *s3.ListBucketsPaginatorOptions) {
+		o.Limit = pagingOpts.PageSize
+	})
+	for paginator.HasMorePages() && !collector.Done() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("error listing buckets: %w", err)
+		}
+		collector.Add(page.Buckets, page.ContinuationToken)
 	}
 
-	for _, object := range result.Contents {
-		fmt.Println(*object.Key)
+	records := output.NewResult("Name", "CreationDate")
+	for _, bucket := range collector.Items() {
+		records.Add(*bucket.Name, aws.ToTime(bucket.CreationDate))
 	}
-	return nil
+	return records, collector.NextToken(), nil
 }
 
-func listObjectsByExtension(s3Client *s3.Client, bucketName string, extension string) error {
-	result, err := s3Client.ListObjectsV2(context.TODO(), &s3.ListObjectsV2Input{Bucket: &bucketName})
+// listObjects lists the objects of a bucket, keeping only keys with the given extension when it is not empty
+func listObjects(ctx context.Context, s3Client awsclient.S3API, bucketName string, extension string, pagingOpts paging.Options) error {
+	collector, err := paging.NewCollector[types.Object](pagingOpts)
 	if err != nil {
-		return fmt.Errorf("error listing objects: %w", err)
+		return err
 	}
 
-	for _, object := range result.Contents {
-		if strings.HasSuffix(*object.Key, "."+extension) {
-			fmt.Println(*object.Key)
+	paginator := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket:            &bucketName,
+		ContinuationToken: collector.StartToken(),
+	}, func(o *s3.ListObjectsV2PaginatorOptions) {
+		o.Limit = pagingOpts.PageSize
+	})
+	for paginator.HasMorePages() && !collector.Done() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing objects: %w", err)
+		}
+
+		objects := page.Contents
+		if extension != "" {
+			objects = []types.Object{}
+			for _, object := range page.Contents {
+				if strings.HasSuffix(*object.Key, "."+extension) {
+					objects = append(objects, object)
+				}
+			}
 		}
+		collector.Add(objects, page.NextContinuationToken)
+	}
+
+	records := output.NewResult("Key", "Size", "LastModified")
+	for _, object := range collector.Items() {
+		records.Add(*object.Key, aws.ToInt64(object.Size), aws.ToTime(object.LastModified))
 	}
-	return nil
+	collector.PrintNextToken()
+	return output.Print(records)
 }
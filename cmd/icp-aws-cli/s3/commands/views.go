@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
+	"icp-aws-cli/pkg/ui"
+)
+
+// BucketsView browses the buckets in the ui command.
+func BucketsView(clients *awsclient.AWSClientCollection) ui.View {
+	return ui.View{
+		Name: "Buckets",
+		ID:   "Name",
+		List: func(ctx context.Context) (*output.Result, error) {
+			result, _, err := listBuckets(ctx, clients.S3, paging.Options{})
+			return result, err
+		},
+		Actions: []ui.Action{
+			{Key: 'd', Name: "delete", Args: func(id string) []string {
+				return []string{"s3", "deleteBucket", id}
+			}},
+		},
+	}
+}
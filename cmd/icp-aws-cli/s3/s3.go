@@ -2,12 +2,13 @@ package s3
 
 import (
 	"icp-aws-cli/cmd/icp-aws-cli/s3/commands"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/ui"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/spf13/cobra"
 )
 
-func InitCommands(s3Client *s3.Client) *cobra.Command {
+func InitCommands(clients *awsclient.AWSClientCollection) *cobra.Command {
 	s3Cmd := &cobra.Command{
 		Use:   "s3",
 		Short: "Commands to interact with Amazon S3",
@@ -15,10 +16,15 @@ func InitCommands(s3Client *s3.Client) *cobra.Command {
 	}
 
 	// Initialize subcommands
-	commands.InitCopyCommands(s3Client, s3Cmd)
-	commands.InitDeleteCommands(s3Client, s3Cmd)
-	commands.InitListCommands(s3Client, s3Cmd)
-	commands.InitCreateCommands(s3Client, s3Cmd)
+	commands.InitCopyCommands(clients, s3Cmd)
+	commands.InitDeleteCommands(clients, s3Cmd)
+	commands.InitListCommands(clients, s3Cmd)
+	commands.InitCreateCommands(clients, s3Cmd)
 
 	return s3Cmd
 }
+
+// Views returns the resources of the service browsed by the ui command.
+func Views(clients *awsclient.AWSClientCollection) []ui.View {
+	return []ui.View{commands.BucketsView(clients)}
+}
@@ -0,0 +1,424 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/shellwords"
+	"icp-aws-cli/pkg/stdin"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// historySize is the number of lines kept in the shell history file.
+const historySize = 100
+
+const shellHelp = `Type any icp-aws-cli command without the program name, e.g. "ec2 list --all".
+
+Shell commands:
+  use <flag> <value>   Keep a global flag for the rest of the session, e.g. "use region eu-west-1"
+  use <flag>           Go back to the default value of the flag
+  use                  Show the flags kept for the session
+  help                 Show this help ("help <command>" for the help of a command)
+  exit, quit           Leave the shell (or Ctrl-D)
+`
+
+func newShellCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "shell",
+		Short: "Start an interactive shell reusing the AWS clients between commands",
+		Long: "Start an interactive shell. The AWS configuration is loaded once and the clients are reused " +
+			"until the profile, region or another global flag changes. Commands are typed without the program " +
+			"name, with history and tab completion of commands, flags and resource names.\n\n" + shellHelp,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return newShell().run()
+		},
+	}
+}
+
+// shell reads command lines and runs them through RootCmd.
+type shell struct {
+	// pinned holds the global flags kept for the session: those given to the
+	// shell command itself and those set with "use".
+	pinned   map[string]string
+	terminal *term.Terminal
+	history  string
+}
+
+func newShell() *shell {
+	s := &shell{pinned: map[string]string{}}
+	RootCmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed {
+			s.pinned[flag.Name] = flagValue(flag)
+		}
+	})
+	if dir, err := os.UserCacheDir(); err == nil {
+		s.history = filepath.Join(dir, "icp-aws-cli", "shell_history")
+	}
+	return s
+}
+
+func (s *shell) run() error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		// Commands piped to the shell, one per line
+		scanner := bufio.NewScanner(stdin.Reader)
+		for scanner.Scan() {
+			if s.execute(scanner.Text()) {
+				break
+			}
+		}
+		return scanner.Err()
+	}
+
+	screen := &terminalIO{Reader: stdin.Reader, Writer: os.Stdout}
+	s.terminal = term.NewTerminal(screen, "")
+	s.loadHistory(screen)
+	s.terminal.AutoCompleteCallback = s.complete
+	fmt.Println(`icp-aws-cli shell. Type "help" for the shell commands, "exit" or Ctrl-D to leave.`)
+
+	for {
+		if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+			s.terminal.SetSize(width, height)
+		}
+		s.terminal.SetPrompt(fmt.Sprintf("icp-aws-cli %s> ", s.location()))
+
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("could not configure the terminal: %w", err)
+		}
+		line, err := s.terminal.ReadLine()
+		term.Restore(fd, state)
+		if err == io.EOF {
+			fmt.Println()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		s.saveHistory(line)
+		if s.execute(line) {
+			return nil
+		}
+	}
+}
+
+// execute runs one command line and reports whether the shell should exit.
+func (s *shell) execute(line string) bool {
+	words, err := shellwords.Split(line)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return false
+	}
+	if len(words) == 0 {
+		return false
+	}
+
+	switch words[0] {
+	case "exit", "quit":
+		return true
+	case "help":
+		if len(words) == 1 {
+			fmt.Print(shellHelp)
+			return false
+		}
+	case "use":
+		if err := s.use(words[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		return false
+	case "shell":
+		fmt.Fprintln(os.Stderr, "Error: already in a shell")
+		return false
+	}
+
+	// runCommand already printed the error
+	_ = s.dispatch(words)
+	return false
+}
+
+// dispatch runs a command line of the CLI with the flags pinned for the session.
+func (s *shell) dispatch(args []string) error {
+	// Ctrl-C cancels the running command, not the shell
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := s.reset(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return err
+	}
+	return runCommand(ctx, args)
+}
+
+// use pins a global flag for the rest of the session and reloads the clients.
+func (s *shell) use(args []string) error {
+	if len(args) == 0 {
+		names := make([]string, 0, len(s.pinned))
+		for name := range s.pinned {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s %s\n", name, s.pinned[name])
+		}
+		return nil
+	}
+	if len(args) > 2 {
+		return fmt.Errorf("usage: use <flag> [value]")
+	}
+
+	name := strings.TrimLeft(args[0], "-")
+	if RootCmd.PersistentFlags().Lookup(name) == nil {
+		return fmt.Errorf("unknown global flag %q", name)
+	}
+
+	previous, wasPinned := s.pinned[name]
+	if len(args) == 1 {
+		delete(s.pinned, name)
+	} else {
+		s.pinned[name] = args[1]
+	}
+	err := s.reset(context.Background())
+	if err == nil {
+		err = initClients(context.Background())
+	}
+	if err != nil {
+		if wasPinned {
+			s.pinned[name] = previous
+		} else {
+			delete(s.pinned, name)
+		}
+		if resetErr := s.reset(context.Background()); resetErr == nil {
+			_ = initClients(context.Background())
+		}
+		return err
+	}
+	return nil
+}
+
+// reset restores every flag to its default before a command line is parsed,
+// as cobra keeps the values and context of the previous line, then applies
+// the flags pinned for the session.
+func (s *shell) reset(ctx context.Context) error {
+	resetCommand(RootCmd, ctx)
+	// Map flags merge into their value once set, so they are emptied directly
+	clientOptions.ServiceEndpoints = map[string]string{}
+	clientOptions.ServiceRateLimits = map[string]int{}
+	// Set by completion, which cannot prompt for an MFA code
+	clientOptions.MFATokenProvider = nil
+
+	for name, value := range s.pinned {
+		if err := RootCmd.PersistentFlags().Set(name, value); err != nil {
+			return fmt.Errorf("invalid value %q for --%s: %w", value, name, err)
+		}
+	}
+	return nil
+}
+
+func resetCommand(cmd *cobra.Command, ctx context.Context) {
+	cmd.SetContext(ctx)
+	reset := func(flag *pflag.Flag) {
+		switch value := flag.Value.(type) {
+		case pflag.SliceValue:
+			defaults := strings.Trim(flag.DefValue, "[]")
+			if defaults == "" {
+				_ = value.Replace(nil)
+			} else {
+				_ = value.Replace(strings.Split(defaults, ","))
+			}
+		default:
+			if !strings.HasPrefix(flag.Value.Type(), "stringTo") {
+				_ = flag.Value.Set(flag.DefValue)
+			}
+		}
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, child := range cmd.Commands() {
+		resetCommand(child, ctx)
+	}
+}
+
+// flagValue returns the value of flag in the syntax accepted by Set.
+func flagValue(flag *pflag.Flag) string {
+	value := flag.Value.String()
+	if _, ok := flag.Value.(pflag.SliceValue); ok || strings.HasPrefix(flag.Value.Type(), "stringTo") {
+		return strings.Trim(value, "[]")
+	}
+	return value
+}
+
+// location describes the profile and region of the session.
+func (s *shell) location() string {
+	profile := clientOptions.Profile
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	return profile + "/" + clients.Region
+}
+
+// complete is called by the terminal for each key and completes the word
+// before the cursor on Tab.
+func (s *shell) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	head := line[:pos]
+	start := strings.LastIndexAny(head, " \t") + 1
+	toComplete := head[start:]
+	words, err := shellwords.Split(head[:start])
+	if err != nil {
+		return line, pos, true
+	}
+
+	candidates := s.candidates(words, toComplete)
+	if len(candidates) == 0 {
+		return line, pos, true
+	}
+	completed := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		completed += " "
+	} else if completed == toComplete {
+		fmt.Fprintln(s.terminal, strings.Join(candidates, "  "))
+	}
+
+	head = head[:start] + completed
+	return head + line[pos:], len(head), true
+}
+
+// candidates lists the completions of toComplete after words.
+func (s *shell) candidates(words []string, toComplete string) []string {
+	var candidates []string
+	switch {
+	case len(words) > 0 && words[0] == "use":
+		if len(words) == 1 {
+			RootCmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+				candidates = append(candidates, flag.Name)
+			})
+		} else if len(words) == 2 && words[1] == "profile" {
+			candidates, _ = fanout.Profiles([]string{"*"})
+		}
+	default:
+		if len(words) == 0 {
+			candidates = []string{"exit", "help", "quit", "use"}
+		}
+		candidates = append(candidates, cobraCompletions(words, toComplete)...)
+		_ = s.reset(context.Background())
+	}
+
+	matches := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, toComplete) {
+			matches = append(matches, candidate)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// cobraCompletions asks cobra for the completions of a command line, as the
+// shell completion scripts do, including the dynamic resource completions.
+func cobraCompletions(words []string, toComplete string) []string {
+	var out bytes.Buffer
+	RootCmd.SetOut(&out)
+	RootCmd.SetErr(io.Discard)
+	defer RootCmd.SetOut(nil)
+	defer RootCmd.SetErr(nil)
+	args := append([]string{cobra.ShellCompRequestCmd}, words...)
+	RootCmd.SetArgs(append(args, toComplete))
+	if err := RootCmd.Execute(); err != nil {
+		return nil
+	}
+
+	candidates := []string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, ":") {
+			break
+		}
+		if candidate, _, _ := strings.Cut(line, "\t"); candidate != "" {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// terminalIO connects the line editor to the terminal. Its ends are swapped to
+// replay the saved history, as term.Terminal only records typed lines.
+type terminalIO struct {
+	io.Reader
+	io.Writer
+}
+
+// loadHistory feeds the history file to the terminal with its output discarded.
+func (s *shell) loadHistory(screen *terminalIO) {
+	if s.history == "" {
+		return
+	}
+	data, err := os.ReadFile(s.history)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > historySize {
+		lines = lines[len(lines)-historySize:]
+	}
+
+	reader, writer := screen.Reader, screen.Writer
+	screen.Reader = strings.NewReader(strings.Join(lines, "\r") + "\r")
+	screen.Writer = io.Discard
+	for range lines {
+		if _, err := s.terminal.ReadLine(); err != nil {
+			break
+		}
+	}
+	screen.Reader, screen.Writer = reader, writer
+}
+
+// saveHistory appends line to the history file on a best-effort basis.
+func (s *shell) saveHistory(line string) {
+	if s.history == "" || strings.TrimSpace(line) == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.history), 0o700); err != nil {
+		return
+	}
+	data, err := os.ReadFile(s.history)
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	lines := append(strings.Split(strings.TrimRight(string(data), "\n"), "\n"), line)
+	if lines[0] == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > historySize {
+		lines = lines[len(lines)-historySize:]
+	}
+	_ = os.WriteFile(s.history, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"icp-aws-cli/pkg/config"
+	"icp-aws-cli/pkg/paging"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// probed are the values a command line ran with.
+type probed struct {
+	Region    string
+	DryRun    bool
+	Endpoints map[string]string
+	Name      string
+	Keys      []string
+	All       bool
+	PageSize  int32
+}
+
+// addProbe registers a probe command recording the values of its flags and of
+// the global flags each time it runs.
+func addProbe(t *testing.T) *[]probed {
+	t.Helper()
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("ICP_AWS_CLI_CONFIG_PROFILE", "")
+
+	runs := []probed{}
+	var name string
+	var keys []string
+	var all bool
+	var pageOpts paging.Options
+	probe := &cobra.Command{
+		Use: "probe",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			endpoints := map[string]string{}
+			for service, url := range clientOptions.ServiceEndpoints {
+				endpoints[service] = url
+			}
+			runs = append(runs, probed{
+				Region:    clientOptions.Region,
+				DryRun:    dryRun,
+				Endpoints: endpoints,
+				Name:      name,
+				Keys:      append([]string{}, keys...),
+				All:       all,
+				PageSize:  pageOpts.PageSize,
+			})
+			return nil
+		},
+	}
+	probe.Flags().StringVar(&name, "name", "", "")
+	probe.Flags().StringSliceVar(&keys, "keys", nil, "")
+	probe.Flags().BoolVar(&all, "all", false, "")
+	paging.AddFlags(probe, &pageOpts, paging.Range{Min: 5, Max: 100})
+	RootCmd.AddCommand(probe)
+
+	previous := userConfig
+	userConfig = &config.Config{Profiles: map[string]config.Defaults{
+		"prod": {Flags: map[string]interface{}{"region": "us-east-1"}, Commands: map[string]map[string]interface{}{"probe": {"keys": []interface{}{"team"}}}},
+	}}
+	t.Cleanup(func() {
+		RootCmd.RemoveCommand(probe)
+		userConfig = previous
+	})
+	return &runs
+}
+
+func TestShellResetsFlagsBetweenLines(t *testing.T) {
+	runs := addProbe(t)
+	s := &shell{pinned: map[string]string{}}
+	for _, line := range []string{
+		"probe --region eu-west-1 --dry-run --service-endpoint-url s3=http://localhost:4566 --name web --keys a,b --all --page-size 50",
+		"probe --keys c",
+		"probe --config-profile prod",
+		"probe",
+	} {
+		if s.execute(line) {
+			t.Fatalf("execute(%q) exited the shell", line)
+		}
+	}
+
+	want := []probed{
+		{Region: "eu-west-1", DryRun: true, Endpoints: map[string]string{"s3": "http://localhost:4566"}, Name: "web", Keys: []string{"a", "b"}, All: true, PageSize: 50},
+		{Endpoints: map[string]string{}, Keys: []string{"c"}},
+		// Configuration defaults apply to their line only
+		{Region: "us-east-1", Endpoints: map[string]string{}, Keys: []string{"team"}},
+		{Endpoints: map[string]string{}, Keys: []string{}},
+	}
+	if !reflect.DeepEqual(*runs, want) {
+		t.Errorf("runs = %+v\nwant %+v", *runs, want)
+	}
+}
+
+func TestShellKeepsPinnedFlags(t *testing.T) {
+	runs := addProbe(t)
+	s := &shell{pinned: map[string]string{}}
+	for _, line := range []string{
+		"use region eu-west-1",
+		"probe --region us-east-1",
+		"probe",
+		"use region",
+		"probe",
+	} {
+		s.execute(line)
+	}
+
+	var regions []string
+	for _, run := range *runs {
+		regions = append(regions, run.Region)
+	}
+	if want := []string{"us-east-1", "eu-west-1", ""}; !reflect.DeepEqual(regions, want) {
+		t.Errorf("regions = %q, want %q", regions, want)
+	}
+}
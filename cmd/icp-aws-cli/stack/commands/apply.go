@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/stack"
+
+	"github.com/spf13/cobra"
+)
+
+func InitApplyCommand(clients *awsclient.AWSClientCollection, rootCmd *cobra.Command) {
+	var file string
+
+	var applyCmd = &cobra.Command{
+		Use:   "apply",
+		Short: "Creates, updates and deletes resources to converge a stack manifest",
+		Long: `Compares the buckets, tables, log groups, alarms and AutoScaling groups of a
+stack manifest with the live resources, prints the plan and applies it.
+Resources are owned by the stack through the icp-aws-cli:stack tag: resources
+of the stack missing from the manifest are deleted after confirmation.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" {
+				return fmt.Errorf("manifest file must be specified")
+			}
+			manifest, err := stack.Load(file)
+			if err != nil {
+				return err
+			}
+			plan, err := stack.Build(cmd.Context(), clients, manifest)
+			if err != nil {
+				return err
+			}
+			return applyPlan(cmd.Context(), clients, plan)
+		},
+	}
+
+	applyCmd.Flags().StringVarP(&file, "file", "f", "", "Stack manifest (YAML)")
+
+	rootCmd.AddCommand(applyCmd)
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/stack"
+
+	"github.com/spf13/cobra"
+)
+
+func InitDestroyCommand(clients *awsclient.AWSClientCollection, rootCmd *cobra.Command) {
+	var file, name string
+
+	var destroyCmd = &cobra.Command{
+		Use:   "destroy",
+		Short: "Deletes every resource owned by a stack",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (file == "") == (name == "") {
+				return fmt.Errorf("either a manifest file or a stack name must be specified")
+			}
+			if file != "" {
+				manifest, err := stack.Load(file)
+				if err != nil {
+					return err
+				}
+				name = manifest.Stack
+			}
+			plan, err := stack.Destroy(cmd.Context(), clients, name)
+			if err != nil {
+				return err
+			}
+			return applyPlan(cmd.Context(), clients, plan)
+		},
+	}
+
+	destroyCmd.Flags().StringVarP(&file, "file", "f", "", "Stack manifest (YAML) naming the stack")
+	destroyCmd.Flags().StringVar(&name, "stack", "", "Name of the stack")
+
+	rootCmd.AddCommand(destroyCmd)
+}
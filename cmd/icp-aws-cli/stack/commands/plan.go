@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/stack"
+
+	"github.com/spf13/cobra"
+)
+
+func InitPlanCommand(clients *awsclient.AWSClientCollection, rootCmd *cobra.Command) {
+	var file string
+
+	var planCmd = &cobra.Command{
+		Use:   "plan",
+		Short: "Shows the changes apply would make to converge a stack manifest",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" {
+				return fmt.Errorf("manifest file must be specified")
+			}
+			manifest, err := stack.Load(file)
+			if err != nil {
+				return err
+			}
+			plan, err := stack.Build(cmd.Context(), clients, manifest)
+			if err != nil {
+				return err
+			}
+			return printPlan(plan)
+		},
+	}
+
+	planCmd.Flags().StringVarP(&file, "file", "f", "", "Stack manifest (YAML)")
+
+	rootCmd.AddCommand(planCmd)
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/stack"
+	"os"
+)
+
+// printPlan shows the changes, if any, followed by the skipped resources and
+// the count of changes on stderr.
+func printPlan(plan *stack.Plan) error {
+	if len(plan.Changes) > 0 {
+		if err := output.Print(plan.Result()); err != nil {
+			return err
+		}
+	}
+	for _, skipped := range plan.Skipped {
+		fmt.Fprintln(os.Stderr, "Warning: skipped", skipped)
+	}
+	fmt.Fprintln(os.Stderr, plan.Summary())
+	return nil
+}
+
+// applyPlan prints the plan and applies it once the deletions, if any, are confirmed.
+func applyPlan(ctx context.Context, clients *awsclient.AWSClientCollection, plan *stack.Plan) error {
+	if err := printPlan(plan); err != nil {
+		return err
+	}
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+
+	deletes := []string{}
+	for _, change := range plan.Deletes() {
+		deletes = append(deletes, change.Kind+" "+change.Name)
+	}
+	if err := confirm.Confirm(ctx, confirm.Request{Action: "delete", Kind: "stack resource", Targets: deletes, Strict: true}); err != nil {
+		return err
+	}
+	return plan.Apply(ctx)
+}
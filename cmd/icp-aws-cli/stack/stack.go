@@ -0,0 +1,15 @@
+package stack
+
+import (
+	"icp-aws-cli/cmd/icp-aws-cli/stack/commands"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/spf13/cobra"
+)
+
+// InitCommands adds the plan, apply and destroy commands to rootCmd.
+func InitCommands(clients *awsclient.AWSClientCollection, rootCmd *cobra.Command) {
+	commands.InitPlanCommand(clients, rootCmd)
+	commands.InitApplyCommand(clients, rootCmd)
+	commands.InitDestroyCommand(clients, rootCmd)
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/inventory"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func InitDiffCommand(clients *awsclient.AWSClientCollection, stateCmd *cobra.Command) {
+	var live bool
+
+	var diffCmd = &cobra.Command{
+		Use:   "diff OLD.json [NEW.json]",
+		Short: "Shows the resources added, removed and changed between two snapshots",
+		Long: `Compares two snapshots written by "state export", or a snapshot with the live
+resources when --live is given. The live inventory covers the resource types
+and regions of the snapshot. Resources are matched by type, region and ID;
+changes to their state, size, capacity and tags are reported.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if live != (len(args) == 1) {
+				return fmt.Errorf("give either two snapshots or one snapshot and --live")
+			}
+			old, err := inventory.ReadSnapshot(args[0])
+			if err != nil {
+				return err
+			}
+			var current *inventory.Snapshot
+			if live {
+				current, err = inventory.Take(cmd.Context(), clients, old.Regions, old.Types)
+			} else {
+				current, err = inventory.ReadSnapshot(args[1])
+			}
+			if err != nil {
+				return err
+			}
+
+			differences, types, regions := inventory.Compare(old, current)
+			if len(types) < len(old.Types) || len(types) < len(current.Types) || len(regions) < len(old.Regions) || len(regions) < len(current.Regions) {
+				fmt.Fprintf(os.Stderr, "The snapshots cover different resources; comparing only types %s in regions %s\n",
+					strings.Join(types, ","), strings.Join(regions, ","))
+			}
+			if len(differences) > 0 {
+				if err := output.Print(inventory.DiffResult(differences)); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintln(os.Stderr, inventory.DiffSummary(differences))
+			return nil
+		},
+	}
+
+	diffCmd.Flags().BoolVar(&live, "live", false, "Compare the snapshot with the live resources")
+
+	stateCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/inventory"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func InitExportCommand(clients *awsclient.AWSClientCollection, stateCmd *cobra.Command) {
+	var types, regions []string
+	var file string
+
+	var exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Saves the inventory to a JSON snapshot",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resourceTypes, err := inventory.ParseTypes(types)
+			if err != nil {
+				return err
+			}
+			snapshot, err := inventory.Take(cmd.Context(), clients, regions, resourceTypes)
+			if err != nil {
+				return err
+			}
+
+			if file == "" {
+				return snapshot.Write(os.Stdout)
+			}
+			f, err := os.Create(file)
+			if err != nil {
+				return fmt.Errorf("could not create snapshot: %w", err)
+			}
+			if err := snapshot.Write(f); err != nil {
+				f.Close()
+				return fmt.Errorf("could not write snapshot: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("could not write snapshot: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Saved %d resources to %s\n", len(snapshot.Resources), file)
+			return nil
+		},
+	}
+
+	exportCmd.Flags().StringVarP(&file, "file", "f", "", "Write the snapshot to this file instead of stdout")
+	exportCmd.Flags().StringSliceVarP(&types, "types", "t", nil, "Only these resource types, e.g. ec2:instance,s3:bucket (default all)")
+	fanout.AddRegionsFlag(exportCmd, &regions)
+	exportCmd.RegisterFlagCompletionFunc("types", cobra.FixedCompletions(inventory.Types, cobra.ShellCompDirectiveNoFileComp))
+
+	stateCmd.AddCommand(exportCmd)
+}
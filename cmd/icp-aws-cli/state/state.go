@@ -0,0 +1,22 @@
+package state
+
+import (
+	"icp-aws-cli/cmd/icp-aws-cli/state/commands"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/spf13/cobra"
+)
+
+func InitCommands(clients *awsclient.AWSClientCollection) *cobra.Command {
+	stateCmd := &cobra.Command{
+		Use:   "state",
+		Short: "Commands to snapshot the inventory and detect drift",
+		Long:  "Allows saving the inventory of an account to a file and comparing it with a later snapshot or the live resources.",
+	}
+
+	// Initialize subcommands
+	commands.InitExportCommand(clients, stateCmd)
+	commands.InitDiffCommand(clients, stateCmd)
+
+	return stateCmd
+}
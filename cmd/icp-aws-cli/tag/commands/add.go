@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func InitAddCommand(clients *awsclient.AWSClientCollection, tagCmd *cobra.Command) {
+	var pairs []string
+	var flags *targetFlags
+
+	var addCmd = &cobra.Command{
+		Use:   "add [RESOURCE...]",
+		Short: "Adds tags to resources or updates their values",
+		Long: `Adds tags to the resources given by ID, ARN or name, or to every resource
+matched by --filter, e.g. tag add --filter type=dynamodb:table --filter name~^orders --tags Team=web`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(pairs) == 0 {
+				return fmt.Errorf("at least one tag must be specified with --tags")
+			}
+			tags, err := parseTags(pairs)
+			if err != nil {
+				return err
+			}
+			if err := checkKeys(tagging.Keys(tags)); err != nil {
+				return err
+			}
+			targets, err := flags.targets(cmd, clients, args)
+			if err != nil {
+				return err
+			}
+			return addTags(cmd.Context(), clients, targets, len(args) == 0, tags)
+		},
+	}
+
+	addCmd.Flags().StringSliceVar(&pairs, "tags", nil, "Tags to add (key=value)")
+	flags = newTargetFlags(addCmd)
+
+	tagCmd.AddCommand(addCmd)
+}
+
+func addTags(ctx context.Context, clients *awsclient.AWSClientCollection, targets []resource, selected bool, tags map[string]string) error {
+	formatted := strings.Join(tagging.Format(tags), ", ")
+	planned := func(target resource) string {
+		return fmt.Sprintf("tag %s with %s", target, formatted)
+	}
+	return applyToTargets(ctx, clients, targets, selected, "tag", "tagged", planned, func(ctx context.Context, tagger tagging.Tagger, target resource) error {
+		if err := tagger.Add(ctx, target.ID, tags); err != nil {
+			return err
+		}
+		fmt.Printf("Tagged %s with %s\n", target, formatted)
+		return nil
+	})
+}
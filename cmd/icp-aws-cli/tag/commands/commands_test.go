@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/spf13/cobra"
+)
+
+const (
+	instanceRef = "i-0123456789abcdef0"
+	dbARN       = "arn:aws:rds:us-east-1:123456789012:db:web-db"
+	bucketARN   = "arn:aws:s3:::web-logs"
+)
+
+// run executes a tag subcommand against the fakes.
+func run(t *testing.T, clients *fake.Clients, args ...string) error {
+	t.Helper()
+	tagCmd := &cobra.Command{Use: "tag", SilenceUsage: true, SilenceErrors: true}
+	collection := clients.Collection()
+	// Resources in the region of the clients are tagged with the fakes
+	collection.Region = "us-east-1"
+	InitCopyCommand(collection, tagCmd)
+	InitRemoveCommand(collection, tagCmd)
+
+	confirm.SetAssumeYes(true)
+	t.Cleanup(func() { confirm.SetAssumeYes(false) })
+	tagCmd.SetArgs(args)
+	return tagCmd.ExecuteContext(context.Background())
+}
+
+// newFakeTags returns an instance, a DB instance and a bucket, each with tags.
+func newFakeTags() *fake.Clients {
+	clients := fake.New()
+	clients.EC2.DescribeInstancesFunc = func(context.Context, *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+		return &ec2.DescribeInstancesOutput{Reservations: []ec2types.Reservation{{Instances: []ec2types.Instance{{
+			InstanceId: aws.String(instanceRef),
+			Tags: []ec2types.Tag{
+				{Key: aws.String("Name"), Value: aws.String("web")},
+				{Key: aws.String("team"), Value: aws.String("core")},
+				{Key: aws.String("aws:cloudformation:stack-name"), Value: aws.String("web-stack")},
+			},
+		}}}}}, nil
+	}
+	clients.RDS.DescribeDBInstancesFunc = func(context.Context, *rds.DescribeDBInstancesInput) (*rds.DescribeDBInstancesOutput, error) {
+		return &rds.DescribeDBInstancesOutput{DBInstances: []rdstypes.DBInstance{{
+			DBInstanceIdentifier: aws.String("web-db"),
+			DBInstanceArn:        aws.String(dbARN),
+			TagList:              []rdstypes.Tag{{Key: aws.String("env"), Value: aws.String("prod")}},
+		}}}, nil
+	}
+	clients.S3.ListBucketsFunc = func(context.Context, *s3.ListBucketsInput) (*s3.ListBucketsOutput, error) {
+		return &s3.ListBucketsOutput{Buckets: []s3types.Bucket{{Name: aws.String("web-logs"), BucketRegion: aws.String("us-east-1")}}}, nil
+	}
+	clients.S3.GetBucketTaggingFunc = func(context.Context, *s3.GetBucketTaggingInput) (*s3.GetBucketTaggingOutput, error) {
+		return &s3.GetBucketTaggingOutput{TagSet: []s3types.Tag{{Key: aws.String("team"), Value: aws.String("data")}}}, nil
+	}
+	return clients
+}
+
+// added returns the tags set on each resource by the mutating calls.
+func added(clients *fake.Clients) map[string]map[string]string {
+	tags := map[string]map[string]string{}
+	set := func(resource, key, value string) {
+		if tags[resource] == nil {
+			tags[resource] = map[string]string{}
+		}
+		tags[resource][key] = value
+	}
+	for _, call := range clients.EC2.Calls() {
+		if input, ok := call.Input.(*ec2.CreateTagsInput); ok {
+			for _, tag := range input.Tags {
+				set(input.Resources[0], aws.ToString(tag.Key), aws.ToString(tag.Value))
+			}
+		}
+	}
+	for _, call := range clients.RDS.Calls() {
+		if input, ok := call.Input.(*rds.AddTagsToResourceInput); ok {
+			for _, tag := range input.Tags {
+				set(aws.ToString(input.ResourceName), aws.ToString(tag.Key), aws.ToString(tag.Value))
+			}
+		}
+	}
+	for _, call := range clients.S3.Calls() {
+		if input, ok := call.Input.(*s3.PutBucketTaggingInput); ok {
+			for _, tag := range input.Tagging.TagSet {
+				set(aws.ToString(input.Bucket), aws.ToString(tag.Key), aws.ToString(tag.Value))
+			}
+		}
+	}
+	return tags
+}
+
+func TestCopyCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]map[string]string
+		wantErr string
+	}{
+		{
+			name: "instance to DB instance and bucket",
+			args: []string{"copy", instanceRef, dbARN, bucketARN},
+			want: map[string]map[string]string{
+				dbARN: {"Name": "web", "team": "core"},
+				// S3 replaces the whole tag set, so the existing tags are sent back
+				"web-logs": {"Name": "web", "team": "core"},
+			},
+		},
+		{
+			name: "DB instance to instance",
+			args: []string{"copy", dbARN, instanceRef},
+			want: map[string]map[string]string{instanceRef: {"env": "prod"}},
+		},
+		{
+			name: "selected keys",
+			args: []string{"copy", instanceRef, dbARN, "--keys", "team"},
+			want: map[string]map[string]string{dbARN: {"team": "core"}},
+		},
+		{
+			name:    "missing key",
+			args:    []string{"copy", instanceRef, dbARN, "--keys", "owner"},
+			wantErr: "has no tag owner",
+		},
+		{
+			name:    "reserved key",
+			args:    []string{"copy", instanceRef, dbARN, "--keys", "aws:cloudformation:stack-name"},
+			wantErr: "reserved by AWS",
+		},
+		{
+			name:    "unsupported ARN",
+			args:    []string{"copy", instanceRef, "arn:aws:sqs:us-east-1:123456789012:queue"},
+			wantErr: "unsupported ARN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeTags()
+			err := run(t, clients, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if got := added(clients); len(got) != 0 {
+					t.Errorf("tags added = %v, want none", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got := added(clients); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tags added = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyWithoutTags(t *testing.T) {
+	clients := newFakeTags()
+	clients.RDS.DescribeDBInstancesFunc = func(context.Context, *rds.DescribeDBInstancesInput) (*rds.DescribeDBInstancesOutput, error) {
+		return &rds.DescribeDBInstancesOutput{DBInstances: []rdstypes.DBInstance{{
+			DBInstanceIdentifier: aws.String("web-db"),
+			DBInstanceArn:        aws.String(dbARN),
+			TagList:              []rdstypes.Tag{{Key: aws.String("aws:rds:owner"), Value: aws.String("rds")}},
+		}}}, nil
+	}
+	err := run(t, clients, "copy", dbARN, instanceRef)
+	if err == nil || !strings.Contains(err.Error(), "has no tags to copy") {
+		t.Fatalf("error = %v, want no tags to copy", err)
+	}
+}
+
+func TestRemoveCommand(t *testing.T) {
+	clients := newFakeTags()
+	if err := run(t, clients, "remove", instanceRef, dbARN, bucketARN, "--keys", "team"); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	wantEC2 := &ec2.DeleteTagsInput{Resources: []string{instanceRef}, Tags: []ec2types.Tag{{Key: aws.String("team")}}}
+	if got := inputOf(clients.EC2.Calls(), "DeleteTags"); !reflect.DeepEqual(got, wantEC2) {
+		t.Errorf("DeleteTags = %+v, want %+v", got, wantEC2)
+	}
+	wantRDS := &rds.RemoveTagsFromResourceInput{ResourceName: aws.String(dbARN), TagKeys: []string{"team"}}
+	if got := inputOf(clients.RDS.Calls(), "RemoveTagsFromResource"); !reflect.DeepEqual(got, wantRDS) {
+		t.Errorf("RemoveTagsFromResource = %+v, want %+v", got, wantRDS)
+	}
+	// The bucket has no tag left, which S3 only accepts as a deletion of the tag set
+	wantS3 := &s3.DeleteBucketTaggingInput{Bucket: aws.String("web-logs")}
+	if got := inputOf(clients.S3.Calls(), "DeleteBucketTagging"); !reflect.DeepEqual(got, wantS3) {
+		t.Errorf("DeleteBucketTagging = %+v, want %+v", got, wantS3)
+	}
+}
+
+func TestRemoveInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "no keys", args: []string{"remove", instanceRef}, wantErr: "at least one tag key"},
+		{name: "reserved key", args: []string{"remove", instanceRef, "--keys", "aws:autoscaling:groupName"}, wantErr: "reserved by AWS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newFakeTags()
+			err := run(t, clients, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if calls := clients.EC2.Calls(); len(calls) != 0 {
+				t.Errorf("calls = %v, want none", clients.EC2.Operations())
+			}
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	dryrun.SetEnabled(true)
+	t.Cleanup(func() { dryrun.SetEnabled(false) })
+
+	for _, args := range [][]string{
+		{"copy", instanceRef, dbARN, bucketARN},
+		{"remove", instanceRef, dbARN, bucketARN, "--keys", "team"},
+	} {
+		t.Run(args[0], func(t *testing.T) {
+			clients := newFakeTags()
+			if err := run(t, clients, args...); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			for service, operations := range map[string][]string{
+				"EC2": clients.EC2.Operations(),
+				"RDS": clients.RDS.Operations(),
+				"S3":  clients.S3.Operations(),
+			} {
+				for _, operation := range operations {
+					if !strings.HasPrefix(operation, "Describe") && !strings.HasPrefix(operation, "List") && !strings.HasPrefix(operation, "Get") {
+						t.Errorf("%s %s called with --dry-run", service, operation)
+					}
+				}
+			}
+		})
+	}
+}
+
+// inputOf returns the input of the first call of operation, or nil.
+func inputOf(calls []fake.Call, operation string) interface{} {
+	for _, call := range calls {
+		if call.Operation == operation {
+			return call.Input
+		}
+	}
+	return nil
+}
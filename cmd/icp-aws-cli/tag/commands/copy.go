@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func InitCopyCommand(clients *awsclient.AWSClientCollection, tagCmd *cobra.Command) {
+	var keys []string
+	var flags *targetFlags
+
+	var copyCmd = &cobra.Command{
+		Use:   "copy SOURCE [TARGET...]",
+		Short: "Copies the tags of a resource to other resources",
+		Long: `Copies the tags of SOURCE to the resources given by ID, ARN or name, or to
+every resource matched by --filter. Tags reserved by AWS are not copied, and
+existing tags of the targets are kept unless SOURCE has the same key.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, err := resolve(cmd.Context(), clients, args[0], flags.resourceType)
+			if err != nil {
+				return err
+			}
+			tags, err := sourceTags(cmd.Context(), clients, source, keys)
+			if err != nil {
+				return err
+			}
+			if len(tags) == 0 {
+				return fmt.Errorf("%s has no tags to copy", source)
+			}
+			targets, err := flags.targets(cmd, clients, args[1:])
+			if err != nil {
+				return err
+			}
+			return addTags(cmd.Context(), clients, targets, len(args) == 1, tags)
+		},
+	}
+
+	copyCmd.Flags().StringSliceVar(&keys, "keys", nil, "Only copy the tags with these keys (default all)")
+	flags = newTargetFlags(copyCmd)
+
+	tagCmd.AddCommand(copyCmd)
+}
+
+// sourceTags returns the tags of source to copy, restricted to keys when given.
+func sourceTags(ctx context.Context, clients *awsclient.AWSClientCollection, source resource, keys []string) (map[string]string, error) {
+	tagger, err := source.tagger(clients)
+	if err != nil {
+		return nil, err
+	}
+	current, err := tagger.Get(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := map[string]string{}
+	if len(keys) == 0 {
+		for key, value := range current {
+			if !strings.HasPrefix(key, tagging.ReservedPrefix) {
+				tags[key] = value
+			}
+		}
+		return tags, nil
+	}
+	if err := checkKeys(keys); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		value, ok := current[key]
+		if !ok {
+			return nil, fmt.Errorf("%s has no tag %s", source, key)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
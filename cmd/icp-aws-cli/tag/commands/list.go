@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/inventory"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/tagging"
+
+	"github.com/spf13/cobra"
+)
+
+func InitListCommand(clients *awsclient.AWSClientCollection, tagCmd *cobra.Command) {
+	var resourceType string
+
+	var listCmd = &cobra.Command{
+		Use:         "list RESOURCE...",
+		Short:       "Lists the tags of resources",
+		Long:        "Lists the tags of the resources given by ID, ARN or name, one row per tag.",
+		Args:        cobra.MinimumNArgs(1),
+		Annotations: fanout.ReadOnly(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result := output.NewResult("Type", "ID", "Key", "Value")
+			for _, ref := range args {
+				target, err := resolve(cmd.Context(), clients, ref, resourceType)
+				if err != nil {
+					return err
+				}
+				tagger, err := target.tagger(clients)
+				if err != nil {
+					return err
+				}
+				tags, err := tagger.Get(cmd.Context(), target.ID)
+				if err != nil {
+					return err
+				}
+				for _, key := range tagging.Keys(tags) {
+					result.Add(target.Type, target.ID, key, tags[key])
+				}
+			}
+			return output.Print(result)
+		},
+	}
+
+	listCmd.Flags().StringVar(&resourceType, "type", "", "Type of the resources, when a name is ambiguous (e.g. dynamodb:table)")
+	listCmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions(inventory.Types, cobra.ShellCompDirectiveNoFileComp))
+
+	tagCmd.AddCommand(listCmd)
+}
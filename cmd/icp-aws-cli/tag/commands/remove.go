@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func InitRemoveCommand(clients *awsclient.AWSClientCollection, tagCmd *cobra.Command) {
+	var keys []string
+	var flags *targetFlags
+
+	var removeCmd = &cobra.Command{
+		Use:   "remove [RESOURCE...]",
+		Short: "Removes tags from resources",
+		Long: `Removes tags from the resources given by ID, ARN or name, or from every
+resource matched by --filter. Keys missing on a resource are ignored.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(keys) == 0 {
+				return fmt.Errorf("at least one tag key must be specified with --keys")
+			}
+			if err := checkKeys(keys); err != nil {
+				return err
+			}
+			targets, err := flags.targets(cmd, clients, args)
+			if err != nil {
+				return err
+			}
+			return removeTags(cmd.Context(), clients, targets, len(args) == 0, keys)
+		},
+	}
+
+	removeCmd.Flags().StringSliceVar(&keys, "keys", nil, "Keys of the tags to remove")
+	flags = newTargetFlags(removeCmd)
+
+	tagCmd.AddCommand(removeCmd)
+}
+
+func removeTags(ctx context.Context, clients *awsclient.AWSClientCollection, targets []resource, selected bool, keys []string) error {
+	formatted := strings.Join(keys, ", ")
+	planned := func(target resource) string {
+		return fmt.Sprintf("remove tags %s from %s", formatted, target)
+	}
+	return applyToTargets(ctx, clients, targets, selected, "untag", "untagged", planned, func(ctx context.Context, tagger tagging.Tagger, target resource) error {
+		if err := tagger.Remove(ctx, target.ID, keys); err != nil {
+			return err
+		}
+		fmt.Printf("Removed tags %s from %s\n", formatted, target)
+		return nil
+	})
+}
@@ -0,0 +1,297 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/inventory"
+	"icp-aws-cli/pkg/tagging"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// resource identifies a taggable resource. Types are those of the inventory.
+type resource struct {
+	Type string
+	// ID is the identifier expected by the tagger of Type, e.g. an instance ID
+	// or a table name.
+	ID string
+	// Region is set when the resource may live outside the region of the
+	// clients, e.g. a bucket or a resource given by ARN.
+	Region string
+}
+
+// String identifies the resource in messages and the audit log.
+func (r resource) String() string {
+	if r.Region != "" {
+		return fmt.Sprintf("%s %s (%s)", r.Type, r.ID, r.Region)
+	}
+	return r.Type + " " + r.ID
+}
+
+// tagger returns the tagger of the resource, using clients for its region.
+func (r resource) tagger(clients *awsclient.AWSClientCollection) (tagging.Tagger, error) {
+	if r.Region != "" && r.Region != clients.Region {
+		clients = clients.ForRegion(r.Region)
+	}
+	return taggerFor(clients, r.Type)
+}
+
+func taggerFor(clients *awsclient.AWSClientCollection, resourceType string) (tagging.Tagger, error) {
+	switch resourceType {
+	case inventory.TypeEC2Instance:
+		return tagging.Instances(clients.EC2), nil
+	case inventory.TypeS3Bucket:
+		return tagging.Buckets(clients.S3), nil
+	case inventory.TypeDynamoDBTable:
+		return tagging.Tables(clients.DynamoDB), nil
+	case inventory.TypeRDSInstance:
+		return tagging.DBInstances(clients.RDS), nil
+	case inventory.TypeRDSSnapshot:
+		return tagging.DBSnapshots(clients.RDS), nil
+	case inventory.TypeAutoScalingGroup:
+		return tagging.Groups(clients.AutoScaling), nil
+	case inventory.TypeAlarm:
+		return tagging.Alarms(clients.CloudWatch), nil
+	case inventory.TypeLogGroup:
+		return tagging.LogGroups(clients.CloudWatchLogs), nil
+	}
+	return nil, fmt.Errorf("unknown resource type %q (valid values: %s)", resourceType, strings.Join(inventory.Types, ", "))
+}
+
+var instanceID = regexp.MustCompile(`^i-[0-9a-f]{8,17}$`)
+
+// resolve identifies the resource designated by ref, which is an ARN, an
+// instance ID or a name. With resourceType, ref is the ID or name of a resource
+// of that type. Otherwise a name is looked up in every service and must match
+// exactly one resource; EC2 instances match by their Name tag.
+func resolve(ctx context.Context, clients *awsclient.AWSClientCollection, ref, resourceType string) (resource, error) {
+	if resourceType != "" {
+		if _, err := inventory.ParseTypes([]string{resourceType}); err != nil {
+			return resource{}, err
+		}
+	}
+
+	var target resource
+	switch {
+	case strings.HasPrefix(ref, "arn:"):
+		parsed, err := parseARN(ref)
+		if err != nil {
+			return resource{}, err
+		}
+		if resourceType != "" && parsed.Type != resourceType {
+			return resource{}, fmt.Errorf("%s is the ARN of a %s, not a %s", ref, parsed.Type, resourceType)
+		}
+		target = parsed
+	case resourceType == inventory.TypeEC2Instance && !instanceID.MatchString(ref):
+		id, err := instanceByName(ctx, clients.EC2, ref)
+		if err != nil {
+			return resource{}, err
+		}
+		target = resource{Type: resourceType, ID: id}
+	case resourceType != "":
+		target = resource{Type: resourceType, ID: ref}
+	case instanceID.MatchString(ref):
+		target = resource{Type: inventory.TypeEC2Instance, ID: ref}
+	default:
+		return lookup(ctx, clients, ref)
+	}
+
+	if target.Type == inventory.TypeS3Bucket {
+		// Bucket ARNs have no region and the tagging calls must reach the bucket region
+		region, err := bucketRegion(ctx, clients.S3, target.ID)
+		if err != nil {
+			return resource{}, err
+		}
+		if region == "" {
+			return resource{}, fmt.Errorf("bucket %s %w", target.ID, tagging.ErrNotFound)
+		}
+		target.Region = region
+	}
+	return target, nil
+}
+
+// parseARN maps an ARN to the resource it designates.
+func parseARN(value string) (resource, error) {
+	parsed, err := arn.Parse(value)
+	if err != nil {
+		return resource{}, fmt.Errorf("invalid ARN %s: %w", value, err)
+	}
+	target := resource{Region: parsed.Region}
+	kind, id, _ := strings.Cut(parsed.Resource, "/")
+	switch {
+	case parsed.Service == "ec2" && kind == "instance":
+		target.Type, target.ID = inventory.TypeEC2Instance, id
+	case parsed.Service == "s3" && !strings.Contains(parsed.Resource, "/"):
+		target.Type, target.ID = inventory.TypeS3Bucket, parsed.Resource
+	case parsed.Service == "dynamodb" && kind == "table" && !strings.Contains(id, "/"):
+		target.Type, target.ID = inventory.TypeDynamoDBTable, id
+	case parsed.Service == "rds" && strings.HasPrefix(parsed.Resource, "db:"):
+		target.Type, target.ID = inventory.TypeRDSInstance, strings.TrimPrefix(parsed.Resource, "db:")
+	case parsed.Service == "rds" && strings.HasPrefix(parsed.Resource, "snapshot:"):
+		target.Type, target.ID = inventory.TypeRDSSnapshot, strings.TrimPrefix(parsed.Resource, "snapshot:")
+	case parsed.Service == "autoscaling" && strings.HasPrefix(parsed.Resource, "autoScalingGroup:"):
+		// autoScalingGroup:<uuid>:autoScalingGroupName/<name>
+		_, name, ok := strings.Cut(parsed.Resource, ":autoScalingGroupName/")
+		if !ok {
+			return resource{}, fmt.Errorf("invalid AutoScaling group ARN %s", value)
+		}
+		target.Type, target.ID = inventory.TypeAutoScalingGroup, name
+	case parsed.Service == "cloudwatch" && strings.HasPrefix(parsed.Resource, "alarm:"):
+		target.Type, target.ID = inventory.TypeAlarm, strings.TrimPrefix(parsed.Resource, "alarm:")
+	case parsed.Service == "logs" && strings.HasPrefix(parsed.Resource, "log-group:"):
+		target.Type, target.ID = inventory.TypeLogGroup, strings.TrimSuffix(strings.TrimPrefix(parsed.Resource, "log-group:"), ":*")
+	default:
+		return resource{}, fmt.Errorf("unsupported ARN %s (supported types: %s)", value, strings.Join(inventory.Types, ", "))
+	}
+	if target.ID == "" {
+		return resource{}, fmt.Errorf("invalid ARN %s", value)
+	}
+	return target, nil
+}
+
+// instanceByName returns the ID of the instance whose Name tag is name.
+// Terminated instances are ignored, as they keep their tags for a while.
+func instanceByName(ctx context.Context, ec2Client awsclient.EC2API, name string) (string, error) {
+	ids := []string{}
+	paginator := ec2.NewDescribeInstancesPaginator(ec2Client, &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{{Name: aws.String("tag:Name"), Values: []string{name}}},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("could not look up instance %s: %w", name, err)
+		}
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				if instance.State != nil && instance.State.Name == ec2types.InstanceStateNameTerminated {
+					continue
+				}
+				ids = append(ids, aws.ToString(instance.InstanceId))
+			}
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("instance %s %w", name, tagging.ErrNotFound)
+	case 1:
+		return ids[0], nil
+	}
+	return "", fmt.Errorf("several instances are named %s (%s), use an instance ID", name, strings.Join(ids, ", "))
+}
+
+// bucketRegion returns the region of the bucket name if it belongs to the
+// account, an empty string otherwise.
+func bucketRegion(ctx context.Context, s3Client awsclient.S3API, name string) (string, error) {
+	paginator := s3.NewListBucketsPaginator(s3Client, &s3.ListBucketsInput{Prefix: aws.String(name)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("could not list buckets: %w", err)
+		}
+		for _, bucket := range page.Buckets {
+			if aws.ToString(bucket.Name) == name {
+				return aws.ToString(bucket.BucketRegion), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// lookup finds the resource named name in every service concurrently.
+func lookup(ctx context.Context, clients *awsclient.AWSClientCollection, name string) (resource, error) {
+	found := make([]*resource, len(inventory.Types))
+	errs := make([]error, len(inventory.Types))
+	var wg sync.WaitGroup
+	for i, resourceType := range inventory.Types {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found[i], errs[i] = probe(ctx, clients, resourceType, name)
+		}()
+	}
+	wg.Wait()
+
+	matches := []resource{}
+	for _, target := range found {
+		if target != nil {
+			matches = append(matches, *target)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		// A service we could not query might hold the resource
+		if err := errors.Join(errs...); err != nil {
+			return resource{}, fmt.Errorf("could not find resource %s (use --type to search a single service): %w", name, err)
+		}
+		return resource{}, fmt.Errorf("resource %s %w", name, tagging.ErrNotFound)
+	}
+	types := []string{}
+	for _, match := range matches {
+		types = append(types, match.Type)
+	}
+	return resource{}, fmt.Errorf("%s is ambiguous, it names a %s (use --type or an ARN)", name, strings.Join(types, " and a "))
+}
+
+// probe returns the resource of resourceType named name, or nil if there is none.
+func probe(ctx context.Context, clients *awsclient.AWSClientCollection, resourceType, name string) (*resource, error) {
+	switch resourceType {
+	case inventory.TypeEC2Instance:
+		id, err := instanceByName(ctx, clients.EC2, name)
+		if errors.Is(err, tagging.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &resource{Type: resourceType, ID: id}, nil
+	case inventory.TypeS3Bucket:
+		// Bucket names are global, so only the buckets of the account are considered
+		region, err := bucketRegion(ctx, clients.S3, name)
+		if err != nil || region == "" {
+			return nil, err
+		}
+		return &resource{Type: resourceType, ID: name, Region: region}, nil
+	}
+
+	tagger, err := taggerFor(clients, resourceType)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tagger.Get(ctx, name); err != nil {
+		if missing(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &resource{Type: resourceType, ID: name}, nil
+}
+
+// missing reports whether err means the resource does not exist, including
+// when the name is not even valid for the service.
+func missing(err error) bool {
+	if errors.Is(err, tagging.ErrNotFound) {
+		return true
+	}
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "ResourceNotFoundException", "DBInstanceNotFound", "DBSnapshotNotFound",
+		"ValidationException", "ValidationError", "InvalidParameterValue":
+		return true
+	}
+	return false
+}
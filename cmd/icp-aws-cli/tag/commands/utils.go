@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/bulk"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/filter"
+	"icp-aws-cli/pkg/inventory"
+	"icp-aws-cli/pkg/tagging"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// filterKeys are the record fields that can be filtered on besides tags.
+var filterKeys = []string{"type", "id", "name", "region", "state"}
+
+// targetFlags selects the resources of a command, either given as arguments or
+// matched by filters over the inventory.
+type targetFlags struct {
+	resourceType string
+	types        []string
+	regions      []string
+	selector     *filter.Selector
+}
+
+// newTargetFlags registers --type for resources given as arguments, and the
+// selection flags for resources matched by filters.
+func newTargetFlags(cmd *cobra.Command) *targetFlags {
+	f := &targetFlags{}
+	cmd.Flags().StringVar(&f.resourceType, "type", "", "Type of the resources given as arguments, when a name is ambiguous (e.g. dynamodb:table)")
+	f.selector = filter.NewSelector(cmd, "resources")
+	cmd.Flags().StringSliceVarP(&f.types, "types", "t", nil, "Only select resources of these types, e.g. ec2:instance,s3:bucket (default all)")
+	fanout.AddRegionsFlag(cmd, &f.regions)
+	cmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions(inventory.Types, cobra.ShellCompDirectiveNoFileComp))
+	cmd.RegisterFlagCompletionFunc("types", cobra.FixedCompletions(inventory.Types, cobra.ShellCompDirectiveNoFileComp))
+	return f
+}
+
+// selecting reports whether resources are selected by filters rather than given as arguments.
+func (f *targetFlags) selecting(cmd *cobra.Command) bool {
+	for _, name := range []string{"filter", "all", "types", "regions"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// targets resolves refs, or selects the resources matching the filters when
+// refs is empty.
+func (f *targetFlags) targets(cmd *cobra.Command, clients *awsclient.AWSClientCollection, refs []string) ([]resource, error) {
+	ctx := cmd.Context()
+	if len(refs) > 0 {
+		if f.selecting(cmd) {
+			return nil, fmt.Errorf("resources given as arguments cannot be combined with --filter, --all, --types or --regions")
+		}
+		targets := []resource{}
+		for _, ref := range refs {
+			target, err := resolve(ctx, clients, ref, f.resourceType)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, target)
+		}
+		return targets, nil
+	}
+
+	expression, err := f.selector.Expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := expression.CheckKeys(filterKeys...); err != nil {
+		return nil, err
+	}
+	resourceTypes, err := inventory.ParseTypes(f.types)
+	if err != nil {
+		return nil, err
+	}
+	records, err := inventory.Run(ctx, clients, f.regions, resourceTypes)
+	if err != nil {
+		// Tagging a partial selection would silently miss resources
+		return nil, fmt.Errorf("could not list resources: %w", err)
+	}
+
+	targets := []resource{}
+	for _, record := range records {
+		if expression.Match(recordFields(record)) {
+			targets = append(targets, resource{Type: record.Type, ID: record.ID, Region: record.Region})
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no resources found with the specified filters")
+	}
+	return targets, nil
+}
+
+// recordFields resolves the filter keys of an inventory record
+func recordFields(record inventory.Record) filter.Fields {
+	return func(key string) (string, bool) {
+		if tagKey, ok := filter.TagKey(key); ok {
+			value, ok := record.Tags[tagKey]
+			return value, ok
+		}
+		switch key {
+		case "type":
+			return record.Type, true
+		case "id":
+			return record.ID, true
+		case "name":
+			return record.Name, true
+		case "region":
+			return record.Region, true
+		case "state":
+			return record.State, record.State != ""
+		}
+		return "", false
+	}
+}
+
+// parseTags parses key=value pairs.
+func parseTags(pairs []string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid tag format: %s (expected key=value)", pair)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// checkKeys refuses the keys reserved by AWS, which cannot be changed.
+func checkKeys(keys []string) error {
+	for _, key := range keys {
+		if strings.HasPrefix(key, tagging.ReservedPrefix) {
+			return fmt.Errorf("tag %s cannot be changed: keys starting with %q are reserved by AWS", key, tagging.ReservedPrefix)
+		}
+	}
+	return nil
+}
+
+// applyToTargets changes the tags of targets one at a time. Resources selected
+// by filters are listed for confirmation first, as a filter can match more
+// than expected. planned describes the change of a target for --dry-run.
+func applyToTargets(ctx context.Context, clients *awsclient.AWSClientCollection, targets []resource, selected bool, action, done string, planned func(target resource) string, change func(ctx context.Context, tagger tagging.Tagger, target resource) error) error {
+	labels := []string{}
+	byLabel := map[string]resource{}
+	for _, target := range targets {
+		labels = append(labels, target.String())
+		byLabel[target.String()] = target
+	}
+
+	if selected {
+		if err := confirm.Confirm(ctx, confirm.Request{Action: action, Kind: "resource", Targets: labels}); err != nil {
+			return err
+		}
+	}
+
+	return bulk.Run(ctx, done, "resource", labels, func(ctx context.Context, label string) error {
+		target := byLabel[label]
+		if dryrun.Skip("Would %s", planned(target)) {
+			return nil
+		}
+		tagger, err := target.tagger(clients)
+		if err != nil {
+			return err
+		}
+		err = change(ctx, tagger, target)
+		audit.Record(label, err)
+		return err
+	})
+}
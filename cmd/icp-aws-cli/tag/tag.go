@@ -0,0 +1,27 @@
+package tag
+
+import (
+	"icp-aws-cli/cmd/icp-aws-cli/tag/commands"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/spf13/cobra"
+)
+
+func InitCommands(clients *awsclient.AWSClientCollection) *cobra.Command {
+	tagCmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Commands to manage the tags of any supported resource",
+		Long: `Allows listing, adding, removing and copying the tags of EC2 instances, S3
+buckets, DynamoDB tables, RDS instances and snapshots, AutoScaling groups,
+CloudWatch alarms and log groups. Resources are given by ID, ARN or name, or
+selected with --filter over the inventory (keys: type, id, name, region, state, tag:<key>).`,
+	}
+
+	// Initialize subcommands
+	commands.InitListCommand(clients, tagCmd)
+	commands.InitAddCommand(clients, tagCmd)
+	commands.InitRemoveCommand(clients, tagCmd)
+	commands.InitCopyCommand(clients, tagCmd)
+
+	return tagCmd
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"fmt"
+	"icp-aws-cli/cmd/icp-aws-cli/autoscaling"
+	"icp-aws-cli/cmd/icp-aws-cli/cloudwatch"
+	"icp-aws-cli/cmd/icp-aws-cli/dynamodb"
+	"icp-aws-cli/cmd/icp-aws-cli/ec2"
+	"icp-aws-cli/cmd/icp-aws-cli/rds"
+	"icp-aws-cli/cmd/icp-aws-cli/s3"
+	"icp-aws-cli/pkg/ui"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func newUICommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ui",
+		Short: "Browse resources in a full-screen terminal interface",
+		Long: "Browse resources in a full-screen terminal interface, with a tab per service, the details of the " +
+			"selected resource and keys running the start, stop and delete commands after the usual confirmation.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+				return fmt.Errorf("the ui command requires an interactive terminal")
+			}
+
+			services := []ui.Service{
+				{Name: "EC2", Views: ec2.Views(clients)},
+				{Name: "S3", Views: s3.Views(clients)},
+				{Name: "DynamoDB", Views: dynamodb.Views(clients)},
+				{Name: "RDS", Views: rds.Views(clients)},
+				{Name: "AutoScaling", Views: autoscaling.Views(clients)},
+				{Name: "CloudWatch", Views: cloudwatch.Views(clients)},
+			}
+			// Actions run like shell command lines, keeping the global flags given to ui
+			s := newShell()
+			return ui.Run(services, s.location(), s.dispatch)
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestUIActionsKeepOnlyTheUIFlags(t *testing.T) {
+	runs := addProbe(t)
+	// As given by "icp-aws-cli ui --region eu-west-1"
+	if err := RootCmd.PersistentFlags().Set("region", "eu-west-1"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resetCommand(RootCmd, context.Background()) })
+
+	s := newShell()
+	for _, action := range [][]string{
+		{"probe", "--dry-run", "--name", "web-1"},
+		{"probe"},
+	} {
+		if err := s.dispatch(action); err != nil {
+			t.Fatalf("dispatch(%q) error = %v", action, err)
+		}
+	}
+
+	want := []probed{
+		{Region: "eu-west-1", DryRun: true, Endpoints: map[string]string{}, Name: "web-1", Keys: []string{}},
+		{Region: "eu-west-1", Endpoints: map[string]string{}, Keys: []string{}},
+	}
+	if !reflect.DeepEqual(*runs, want) {
+		t.Errorf("runs = %+v\nwant %+v", *runs, want)
+	}
+}
@@ -0,0 +1,197 @@
+// Package audit keeps a local JSONL log of the mutating operations performed by
+// the CLI. Commands report their targets and the outcome of each call while they
+// run; the entry is written once the command returns.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// EnvPath overrides the location of the audit log.
+const EnvPath = "ICP_AWS_CLI_AUDIT_LOG"
+
+// Outcome statuses.
+const (
+	StatusSucceeded    = "succeeded"
+	StatusFailed       = "failed"
+	StatusNotProcessed = "not-processed"
+)
+
+// Outcome is the result of the operation on a single resource.
+type Outcome struct {
+	Resource string `json:"resource"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Entry is one line of the audit log, describing one command invocation.
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Identity  string    `json:"identity,omitempty"`
+	Account   string    `json:"account,omitempty"`
+	Profile   string    `json:"profile,omitempty"`
+	Region    string    `json:"region,omitempty"`
+	Command   string    `json:"command"`
+	Targets   []string  `json:"targets"`
+	Outcomes  []Outcome `json:"outcomes"`
+}
+
+var (
+	mu       sync.Mutex
+	targets  []string
+	outcomes []Outcome
+)
+
+// Targets records resolved targets before they are processed, so resources left
+// untouched by an interrupted bulk operation still appear in the entry.
+func Targets(resources ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, resource := range resources {
+		addTarget(resource)
+	}
+}
+
+// Record records the outcome of a mutating call on resource.
+func Record(resource string, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	addTarget(resource)
+	outcome := Outcome{Resource: resource, Status: StatusSucceeded}
+	if err != nil {
+		outcome.Status = StatusFailed
+		outcome.Error = err.Error()
+	}
+	outcomes = append(outcomes, outcome)
+}
+
+func addTarget(resource string) {
+	for _, target := range targets {
+		if target == resource {
+			return
+		}
+	}
+	targets = append(targets, resource)
+}
+
+// Command describes cmd for Entry.Command: its path and the names of the flags
+// given on the command line. Arguments and flag values are left out, as they
+// may hold secrets, e.g. the master password in the JSON of rds createInstance.
+func Command(cmd *cobra.Command) string {
+	words := []string{cmd.CommandPath()}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		words = append(words, "--"+flag.Name)
+	})
+	return strings.Join(words, " ")
+}
+
+// Pending reports whether the current command performed any mutating operation.
+func Pending() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(targets) > 0
+}
+
+// Flush completes entry with the recorded targets and outcomes, appends it to
+// the audit log and resets the recorder. Targets without an outcome are logged
+// as not processed.
+func Flush(entry Entry) error {
+	mu.Lock()
+	entry.Targets = targets
+	entry.Outcomes = outcomes
+	targets, outcomes = nil, nil
+	mu.Unlock()
+
+	processed := map[string]bool{}
+	for _, outcome := range entry.Outcomes {
+		processed[outcome.Resource] = true
+	}
+	for _, target := range entry.Targets {
+		if !processed[target] {
+			entry.Outcomes = append(entry.Outcomes, Outcome{Resource: target, Status: StatusNotProcessed})
+		}
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("could not create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("could not open audit log: %w", err)
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not encode audit entry: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not write audit log: %w", err)
+	}
+	return nil
+}
+
+// Path returns the audit log location: $ICP_AWS_CLI_AUDIT_LOG or ~/.local/state/icp-aws-cli/audit.jsonl.
+func Path() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not locate the audit log: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "icp-aws-cli", "audit.jsonl"), nil
+}
+
+// Read returns every entry of the audit log, oldest first. A missing log has no entries.
+func Read() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log: %w", err)
+	}
+	defer file.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid audit log entry at %s:%d: %w", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read audit log: %w", err)
+	}
+	return entries, nil
+}
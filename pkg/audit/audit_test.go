@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// tempLog points the audit log at a temporary file.
+func tempLog(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "state", "audit.jsonl")
+	t.Setenv(EnvPath, path)
+	return path
+}
+
+func TestFlushAppends(t *testing.T) {
+	tempLog(t)
+
+	Targets("ec2 instance i-1", "ec2 instance i-2")
+	Record("ec2 instance i-1", nil)
+	if !Pending() {
+		t.Fatalf("Pending() = false after recording an operation")
+	}
+	if err := Flush(Entry{Command: "icp-aws-cli ec2 terminate --yes"}); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if Pending() {
+		t.Errorf("Pending() = true after Flush()")
+	}
+	Record("s3 bucket web-logs", errors.New("BucketNotEmpty"))
+	if err := Flush(Entry{Command: "icp-aws-cli s3 delete"}); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	entries, err := Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Read() = %d entries, want 2", len(entries))
+	}
+	want := []Outcome{
+		{Resource: "ec2 instance i-1", Status: StatusSucceeded},
+		{Resource: "ec2 instance i-2", Status: StatusNotProcessed},
+	}
+	if got := entries[0].Outcomes; !reflect.DeepEqual(got, want) {
+		t.Errorf("first entry outcomes = %+v, want %+v", got, want)
+	}
+	want = []Outcome{{Resource: "s3 bucket web-logs", Status: StatusFailed, Error: "BucketNotEmpty"}}
+	if got := entries[1].Outcomes; !reflect.DeepEqual(got, want) {
+		t.Errorf("second entry outcomes = %+v, want %+v", got, want)
+	}
+	if entries[1].Timestamp.IsZero() || entries[1].Timestamp.Before(entries[0].Timestamp) {
+		t.Errorf("timestamps = %v, %v, want increasing", entries[0].Timestamp, entries[1].Timestamp)
+	}
+}
+
+func TestReadMissingLog(t *testing.T) {
+	tempLog(t)
+	entries, err := Read()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Read() = %v, %v, want no entries", entries, err)
+	}
+}
+
+func TestConcurrentRecords(t *testing.T) {
+	tempLog(t)
+
+	// Bulk operations record their outcomes from several goroutines
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			Record(fmt.Sprintf("ec2 instance i-%d", i), nil)
+		}(i)
+	}
+	wg.Wait()
+	if err := Flush(Entry{Command: "icp-aws-cli ec2 stop"}); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	entries, err := Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 1 || len(entries[0].Outcomes) != 50 || len(entries[0].Targets) != 50 {
+		t.Fatalf("Read() = %+v, want one entry with 50 outcomes", entries)
+	}
+}
+
+func TestConcurrentFlushes(t *testing.T) {
+	tempLog(t)
+
+	// Shell sessions and scripts may append to the same log at once
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = Flush(Entry{Command: fmt.Sprintf("command %02d", i), Timestamp: time.Now()})
+		}(i)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	entries, err := Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v, want every line intact", err)
+	}
+	commands := []string{}
+	for _, entry := range entries {
+		commands = append(commands, entry.Command)
+	}
+	sort.Strings(commands)
+	for i, command := range commands {
+		if want := fmt.Sprintf("command %02d", i); command != want {
+			t.Fatalf("entries = %q, want commands 00 to 19", commands)
+		}
+	}
+	if len(commands) != len(errs) {
+		t.Errorf("Read() = %d entries, want %d", len(commands), len(errs))
+	}
+}
+
+func TestCommand(t *testing.T) {
+	root := &cobra.Command{Use: "icp-aws-cli"}
+	root.PersistentFlags().String("region", "", "")
+	root.PersistentFlags().BoolP("yes", "y", false, "")
+	rds := &cobra.Command{Use: "rds"}
+	var got string
+	create := &cobra.Command{
+		Use: "createInstance",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			got = Command(cmd)
+			return nil
+		},
+	}
+	create.Flags().String("tags", "", "")
+	create.Flags().String("class", "db.t3.micro", "")
+	rds.AddCommand(create)
+	root.AddCommand(rds)
+
+	root.SetArgs([]string{"--region", "eu-west-1", "rds", "createInstance", `{"MasterUserPassword": "hunter2"}`, "--tags=team=db", "-y"})
+	if err := root.ExecuteContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "icp-aws-cli rds createInstance --region --tags --yes"; got != want {
+		t.Errorf("Command() = %q, want %q", got, want)
+	}
+}
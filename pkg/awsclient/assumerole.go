@@ -0,0 +1,153 @@
+package awsclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"icp-aws-cli/pkg/stdin"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"golang.org/x/term"
+)
+
+// roleSessionName identifies the CLI in CloudTrail entries of assumed-role sessions.
+const roleSessionName = "icp-aws-cli"
+
+// sessionExpiryWindow renews cached sessions slightly before they expire, so a
+// command never starts with credentials that lapse mid-way.
+const sessionExpiryWindow = 5 * time.Minute
+
+// assumeRole replaces the credentials of cfg with those of Options.RoleARN. The
+// session is cached on disk until it expires, so consecutive commands neither
+// call STS again nor prompt for another MFA token code.
+func (o Options) assumeRole(cfg *aws.Config, logger *slog.Logger) {
+	client := sts.NewFromConfig(*cfg, func(so *sts.Options) {
+		so.BaseEndpoint = o.endpoint(ServiceSTS, so.BaseEndpoint)
+		so.APIOptions = append(so.APIOptions, o.rateLimit(ServiceSTS)...)
+		so.APIOptions = append(so.APIOptions, callLog(logger)...)
+	})
+	provider := stscreds.NewAssumeRoleProvider(client, o.RoleARN, func(ao *stscreds.AssumeRoleOptions) {
+		ao.RoleSessionName = roleSessionName
+		if o.ExternalID != "" {
+			ao.ExternalID = aws.String(o.ExternalID)
+		}
+		if o.MFASerial != "" {
+			ao.SerialNumber = aws.String(o.MFASerial)
+			ao.TokenProvider = o.MFATokenProvider
+			if ao.TokenProvider == nil {
+				ao.TokenProvider = func() (string, error) {
+					return promptMFAToken(o.MFASerial)
+				}
+			}
+		}
+		if o.SessionDuration > 0 {
+			ao.Duration = o.SessionDuration
+		}
+	})
+
+	key := strings.Join([]string{o.Profile, o.RoleARN, o.ExternalID, o.MFASerial, o.SessionDuration.String(), aws.ToString(o.endpoint(ServiceSTS, cfg.BaseEndpoint))}, "|")
+	session := &cachedSession{key: key, source: cfg.Credentials, provider: provider}
+	cfg.Credentials = aws.NewCredentialsCache(session, func(co *aws.CredentialsCacheOptions) {
+		co.ExpiryWindow = sessionExpiryWindow
+	})
+}
+
+// cachedSession serves assumed-role credentials from the session cache, falling
+// back to STS when there is no valid cached session.
+type cachedSession struct {
+	key string
+	// source provides the credentials the role is assumed with.
+	source   aws.CredentialsProvider
+	provider aws.CredentialsProvider
+}
+
+func (s *cachedSession) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	if s.source == nil {
+		return aws.Credentials{}, fmt.Errorf("could not assume role: no credentials to assume it with")
+	}
+	// A session assumed by other credentials, e.g. after switching
+	// AWS_ACCESS_KEY_ID on a shared runner, must not be reused
+	source, err := s.source.Retrieve(ctx)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("could not assume role: %w", err)
+	}
+
+	path, err := sessionCachePath(s.key + "|" + source.AccessKeyID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: the role session cannot be cached:", err)
+	} else if creds, ok := readSession(path); ok {
+		return creds, nil
+	}
+
+	creds, err := s.provider.Retrieve(ctx)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("could not assume role: %w", err)
+	}
+	if path != "" {
+		// The session is valid anyway, only the next command assumes the role again
+		if err := writeSession(path, creds); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: could not cache the role session:", err)
+		}
+	}
+	return creds, nil
+}
+
+// sessionCachePath returns the cache file of a session. Sessions are keyed by
+// everything that selects the role and by the source access key, so changing
+// any option or the source credentials starts a new session.
+func sessionCachePath(key string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, "icp-aws-cli", "sessions", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func readSession(path string) (aws.Credentials, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return aws.Credentials{}, false
+	}
+	var creds aws.Credentials
+	if err := json.Unmarshal(data, &creds); err != nil || !creds.CanExpire || time.Until(creds.Expires) < sessionExpiryWindow {
+		return aws.Credentials{}, false
+	}
+	return creds, true
+}
+
+// writeSession stores a session in the cache. The file holds secrets, so only
+// the current user may read it.
+func writeSession(path string, creds aws.Credentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// promptMFAToken asks for the token code of the MFA device on stderr, keeping
+// stdout free for command output.
+func promptMFAToken(serial string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("an MFA token code for %s is required but stdin is not a terminal", serial)
+	}
+	fmt.Fprintf(os.Stderr, "MFA token code for %s: ", serial)
+	line, err := stdin.ReadLine(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("could not read MFA token code: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
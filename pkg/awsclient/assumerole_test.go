@@ -0,0 +1,127 @@
+package awsclient
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// countingProvider stands for STS, returning a new session on every call.
+type countingProvider struct {
+	calls   int
+	expires time.Duration
+}
+
+func (p *countingProvider) Retrieve(context.Context) (aws.Credentials, error) {
+	p.calls++
+	return aws.Credentials{
+		AccessKeyID:     "ASIA" + strings.Repeat("X", p.calls),
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+		CanExpire:       true,
+		Expires:         time.Now().Add(p.expires),
+	}, nil
+}
+
+// sessionCache points the session cache at a temporary directory.
+func sessionCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	return dir
+}
+
+func source(accessKey string) aws.CredentialsProvider {
+	return credentials.NewStaticCredentialsProvider(accessKey, "secret", "")
+}
+
+func TestCachedSession(t *testing.T) {
+	sessionCache(t)
+	assumer := &countingProvider{expires: time.Hour}
+	retrieve := func(key, accessKey string) aws.Credentials {
+		t.Helper()
+		session := &cachedSession{key: key, source: source(accessKey), provider: assumer}
+		creds, err := session.Retrieve(context.Background())
+		if err != nil {
+			t.Fatalf("Retrieve() error = %v", err)
+		}
+		return creds
+	}
+
+	first := retrieve("dev|role", "AKID1")
+	if got := retrieve("dev|role", "AKID1"); got.AccessKeyID != first.AccessKeyID || assumer.calls != 1 {
+		t.Errorf("second command assumed the role again (%d calls)", assumer.calls)
+	}
+	// Other source credentials, e.g. after switching AWS_ACCESS_KEY_ID, get their own session
+	if got := retrieve("dev|role", "AKID2"); got.AccessKeyID == first.AccessKeyID || assumer.calls != 2 {
+		t.Errorf("session of another source access key was reused (%d calls)", assumer.calls)
+	}
+	if retrieve("prod|role", "AKID1"); assumer.calls != 3 {
+		t.Errorf("session of another profile was reused (%d calls)", assumer.calls)
+	}
+}
+
+func TestCachedSessionExpiry(t *testing.T) {
+	sessionCache(t)
+	// Sessions about to expire are renewed rather than served from the cache
+	assumer := &countingProvider{expires: sessionExpiryWindow / 2}
+	for i := 1; i <= 2; i++ {
+		session := &cachedSession{key: "dev|role", source: source("AKID1"), provider: assumer}
+		if _, err := session.Retrieve(context.Background()); err != nil {
+			t.Fatalf("Retrieve() error = %v", err)
+		}
+		if assumer.calls != i {
+			t.Errorf("calls = %d after %d commands, want %d", assumer.calls, i, i)
+		}
+	}
+}
+
+func TestCachedSessionWithoutCache(t *testing.T) {
+	dir := sessionCache(t)
+	// A file in place of the cache directory makes every write fail
+	if err := os.WriteFile(filepath.Join(dir, "icp-aws-cli"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	assumer := &countingProvider{expires: time.Hour}
+	session := &cachedSession{key: "dev|role", source: source("AKID1"), provider: assumer}
+	if _, err := session.Retrieve(context.Background()); err != nil {
+		t.Fatalf("Retrieve() error = %v, want the session despite the cache", err)
+	}
+
+	path, err := sessionCachePath("dev|role|AKID1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSession(path, aws.Credentials{}); err == nil {
+		t.Errorf("writeSession() error = nil, want the cache error")
+	}
+}
+
+func TestCachedSessionWithoutSource(t *testing.T) {
+	session := &cachedSession{key: "dev|role", provider: &countingProvider{}}
+	if _, err := session.Retrieve(context.Background()); err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("Retrieve() error = %v, want no credentials", err)
+	}
+}
+
+func TestPromptMFATokenWithoutTerminal(t *testing.T) {
+	file, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	stdin := os.Stdin
+	os.Stdin = file
+	t.Cleanup(func() { os.Stdin = stdin })
+
+	_, err = promptMFAToken("arn:aws:iam::123456789012:mfa/dev")
+	if err == nil || !strings.Contains(err.Error(), "stdin is not a terminal") {
+		t.Errorf("promptMFAToken() error = %v, want stdin is not a terminal", err)
+	}
+}
@@ -3,8 +3,14 @@ package awsclient
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
@@ -12,31 +18,223 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
+// Service names accepted as keys of Options.ServiceEndpoints and Options.ServiceRateLimits.
+const (
+	ServiceS3             = "s3"
+	ServiceEC2            = "ec2"
+	ServiceDynamoDB       = "dynamodb"
+	ServiceAutoScaling    = "autoscaling"
+	ServiceRDS            = "rds"
+	ServiceCloudWatch     = "cloudwatch"
+	ServiceCloudWatchLogs = "logs"
+	ServiceSTS            = "sts"
+)
+
+var services = []string{ServiceS3, ServiceEC2, ServiceDynamoDB, ServiceAutoScaling, ServiceRDS, ServiceCloudWatch, ServiceCloudWatchLogs, ServiceSTS}
+
+// Options controls how the AWS configuration is resolved.
+// Empty fields fall back to the SDK defaults (environment, shared config files).
+type Options struct {
+	Profile     string
+	Region      string
+	EndpointURL string
+	// ServiceEndpoints overrides EndpointURL for individual services, keyed by service name.
+	ServiceEndpoints map[string]string
+	// MaxRetries is the number of retries per request; a negative value keeps the SDK default.
+	MaxRetries int
+	// RetryMode is "standard" or "adaptive"; empty keeps the SDK default.
+	RetryMode string
+	// RateLimit caps the requests per second sent to each service; 0 disables the limit.
+	RateLimit int
+	// ServiceRateLimits overrides RateLimit for individual services, keyed by service name.
+	ServiceRateLimits map[string]int
+	// Verbose logs every API call with its latency.
+	Verbose bool
+	// Debug also logs the SDK requests, responses, retries and signing.
+	Debug bool
+	// LogOutput receives the logs; nil means stderr.
+	LogOutput io.Writer
+	// RoleARN is a role assumed with the resolved credentials; empty uses them directly.
+	RoleARN string
+	// ExternalID is passed to AssumeRole when the role trust policy requires it.
+	ExternalID string
+	// MFASerial is the ARN or serial number of the MFA device required by the role.
+	MFASerial string
+	// SessionDuration of the assumed-role session; 0 keeps the STS default.
+	SessionDuration time.Duration
+	// MFATokenProvider returns the MFA token code; nil prompts on the terminal.
+	MFATokenProvider func() (string, error)
+	// Credentials replace the resolved ones, e.g. a session handed over by
+	// another process; RoleARN is then not assumed again.
+	Credentials *aws.Credentials
+}
+
 type AWSClientCollection struct {
-	S3             *s3.Client
-	EC2            *ec2.Client
-	DynamoDB       *dynamodb.Client
-	AutoScaling    *autoscaling.Client
-	RDS            *rds.Client
-	CloudWatch     *cloudwatch.Client
-	CloudWatchLogs *cloudwatchlogs.Client
+	S3             S3API
+	EC2            EC2API
+	DynamoDB       DynamoDBAPI
+	AutoScaling    AutoScalingAPI
+	RDS            RDSAPI
+	CloudWatch     CloudWatchAPI
+	CloudWatchLogs CloudWatchLogsAPI
+	STS            STSAPI
+	// Region is the region resolved from the flags, environment and shared config.
+	Region string
+
+	config  aws.Config
+	options Options
+	logger  *slog.Logger
 }
 
-func NewAWSClientCollection() (*AWSClientCollection, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+func NewAWSClientCollection(ctx context.Context, opts Options) (*AWSClientCollection, error) {
+	for service := range opts.ServiceEndpoints {
+		if !isKnownService(service) {
+			return nil, fmt.Errorf("unknown service %q in endpoint override (valid values: %s)", service, strings.Join(services, ", "))
+		}
+	}
+	for service := range opts.ServiceRateLimits {
+		if !isKnownService(service) {
+			return nil, fmt.Errorf("unknown service %q in rate limit (valid values: %s)", service, strings.Join(services, ", "))
+		}
+	}
+	if opts.MaxRetries < -1 {
+		return nil, fmt.Errorf("invalid max retries %d: must be -1 (SDK default) or greater", opts.MaxRetries)
+	}
+
+	loadOptions := []func(*config.LoadOptions) error{}
+	if opts.Profile != "" {
+		loadOptions = append(loadOptions, config.WithSharedConfigProfile(opts.Profile))
+	}
+	if opts.Region != "" {
+		loadOptions = append(loadOptions, config.WithRegion(opts.Region))
+	}
+	if opts.EndpointURL != "" {
+		loadOptions = append(loadOptions, config.WithBaseEndpoint(opts.EndpointURL))
+	}
+
+	if opts.MaxRetries >= 0 {
+		// The SDK counts attempts, the first one included
+		loadOptions = append(loadOptions, config.WithRetryMaxAttempts(opts.MaxRetries+1))
+	}
+	if opts.RetryMode != "" {
+		mode, err := aws.ParseRetryMode(opts.RetryMode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry mode: %w", err)
+		}
+		loadOptions = append(loadOptions, config.WithRetryMode(mode))
+	}
+
+	logger := opts.logger()
+	loadOptions = append(loadOptions, opts.loggingOptions(logger)...)
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("error loading AWS config: %w", err)
 	}
+	switch {
+	case opts.Credentials != nil:
+		cfg.Credentials = aws.NewCredentialsCache(credentials.StaticCredentialsProvider{Value: *opts.Credentials})
+	case opts.RoleARN != "":
+		opts.assumeRole(&cfg, logger)
+	case opts.ExternalID != "" || opts.MFASerial != "":
+		return nil, fmt.Errorf("an external ID or MFA device requires a role ARN")
+	}
+
+	return newCollection(cfg, opts, logger), nil
+}
 
+// ForRegion returns a collection for another region. It shares the credentials
+// of c, so an assumed-role session and its MFA prompt are not repeated.
+func (c *AWSClientCollection) ForRegion(region string) *AWSClientCollection {
+	cfg := c.config.Copy()
+	cfg.Region = region
+	return newCollection(cfg, c.options, c.logger)
+}
+
+func newCollection(cfg aws.Config, opts Options, logger *slog.Logger) *AWSClientCollection {
 	return &AWSClientCollection{
-		S3:             s3.NewFromConfig(cfg),
-		EC2:            ec2.NewFromConfig(cfg),
-		DynamoDB:       dynamodb.NewFromConfig(cfg),
-		AutoScaling:    autoscaling.NewFromConfig(cfg),
-		RDS:            rds.NewFromConfig(cfg),
-		CloudWatch:     cloudwatch.NewFromConfig(cfg),
-		CloudWatchLogs: cloudwatchlogs.NewFromConfig(cfg),
-	}, nil
+		S3: s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.BaseEndpoint = opts.endpoint(ServiceS3, o.BaseEndpoint)
+			o.APIOptions = append(o.APIOptions, opts.rateLimit(ServiceS3)...)
+			o.APIOptions = append(o.APIOptions, callLog(logger)...)
+			o.APIOptions = append(o.APIOptions, resourceErrors)
+			// Local emulators such as LocalStack and moto do not resolve virtual-hosted bucket names.
+			o.UsePathStyle = o.BaseEndpoint != nil
+		}),
+		EC2: ec2.NewFromConfig(cfg, func(o *ec2.Options) {
+			o.BaseEndpoint = opts.endpoint(ServiceEC2, o.BaseEndpoint)
+			o.APIOptions = append(o.APIOptions, opts.rateLimit(ServiceEC2)...)
+			o.APIOptions = append(o.APIOptions, callLog(logger)...)
+			o.APIOptions = append(o.APIOptions, resourceErrors)
+		}),
+		DynamoDB: dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+			o.BaseEndpoint = opts.endpoint(ServiceDynamoDB, o.BaseEndpoint)
+			o.APIOptions = append(o.APIOptions, opts.rateLimit(ServiceDynamoDB)...)
+			o.APIOptions = append(o.APIOptions, callLog(logger)...)
+			o.APIOptions = append(o.APIOptions, resourceErrors)
+		}),
+		AutoScaling: autoscaling.NewFromConfig(cfg, func(o *autoscaling.Options) {
+			o.BaseEndpoint = opts.endpoint(ServiceAutoScaling, o.BaseEndpoint)
+			o.APIOptions = append(o.APIOptions, opts.rateLimit(ServiceAutoScaling)...)
+			o.APIOptions = append(o.APIOptions, callLog(logger)...)
+			o.APIOptions = append(o.APIOptions, resourceErrors)
+		}),
+		RDS: rds.NewFromConfig(cfg, func(o *rds.Options) {
+			o.BaseEndpoint = opts.endpoint(ServiceRDS, o.BaseEndpoint)
+			o.APIOptions = append(o.APIOptions, opts.rateLimit(ServiceRDS)...)
+			o.APIOptions = append(o.APIOptions, callLog(logger)...)
+			o.APIOptions = append(o.APIOptions, resourceErrors)
+		}),
+		CloudWatch: cloudwatch.NewFromConfig(cfg, func(o *cloudwatch.Options) {
+			o.BaseEndpoint = opts.endpoint(ServiceCloudWatch, o.BaseEndpoint)
+			o.APIOptions = append(o.APIOptions, opts.rateLimit(ServiceCloudWatch)...)
+			o.APIOptions = append(o.APIOptions, callLog(logger)...)
+			o.APIOptions = append(o.APIOptions, resourceErrors)
+		}),
+		CloudWatchLogs: cloudwatchlogs.NewFromConfig(cfg, func(o *cloudwatchlogs.Options) {
+			o.BaseEndpoint = opts.endpoint(ServiceCloudWatchLogs, o.BaseEndpoint)
+			o.APIOptions = append(o.APIOptions, opts.rateLimit(ServiceCloudWatchLogs)...)
+			o.APIOptions = append(o.APIOptions, callLog(logger)...)
+			o.APIOptions = append(o.APIOptions, resourceErrors)
+		}),
+		STS: sts.NewFromConfig(cfg, func(o *sts.Options) {
+			o.BaseEndpoint = opts.endpoint(ServiceSTS, o.BaseEndpoint)
+			o.APIOptions = append(o.APIOptions, opts.rateLimit(ServiceSTS)...)
+			o.APIOptions = append(o.APIOptions, callLog(logger)...)
+			o.APIOptions = append(o.APIOptions, resourceErrors)
+		}),
+		Region:  cfg.Region,
+		config:  cfg,
+		options: opts,
+		logger:  logger,
+	}
+}
+
+// endpoint returns the override for service, or current when there is none.
+func (o Options) endpoint(service string, current *string) *string {
+	if url, ok := o.ServiceEndpoints[service]; ok && url != "" {
+		return aws.String(url)
+	}
+	return current
+}
+
+func isKnownService(service string) bool {
+	for _, known := range services {
+		if service == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Credentials returns the credentials the clients sign requests with, e.g. to
+// hand an assumed-role session to another process.
+func (c *AWSClientCollection) Credentials(ctx context.Context) (aws.Credentials, error) {
+	if c.config.Credentials == nil {
+		return aws.Credentials{}, fmt.Errorf("no credentials configured")
+	}
+	return c.config.Credentials.Retrieve(ctx)
 }
@@ -0,0 +1,144 @@
+package awsclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// isolate keeps the shared config and credentials of the developer out of the tests.
+func isolate(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_MAX_ATTEMPTS", "")
+	t.Setenv("AWS_RETRY_MODE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+}
+
+func TestInvalidOptions(t *testing.T) {
+	isolate(t)
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{name: "retry mode", opts: Options{MaxRetries: -1, RetryMode: "fast"}, wantErr: "invalid retry mode"},
+		{name: "max retries", opts: Options{MaxRetries: -2}, wantErr: "invalid max retries"},
+		{name: "rate limit service", opts: Options{MaxRetries: -1, ServiceRateLimits: map[string]int{"sqs": 5}}, wantErr: `unknown service "sqs" in rate limit`},
+		{name: "endpoint service", opts: Options{MaxRetries: -1, ServiceEndpoints: map[string]string{"sqs": "http://localhost"}}, wantErr: `unknown service "sqs" in endpoint override`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAWSClientCollection(context.Background(), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryOptions(t *testing.T) {
+	isolate(t)
+	tests := []struct {
+		opts         Options
+		wantAttempts int
+		wantMode     aws.RetryMode
+	}{
+		{opts: Options{MaxRetries: -1}, wantAttempts: 3, wantMode: aws.RetryModeStandard},
+		{opts: Options{MaxRetries: 0}, wantAttempts: 1, wantMode: aws.RetryModeStandard},
+		{opts: Options{MaxRetries: 7, RetryMode: "adaptive"}, wantAttempts: 8, wantMode: aws.RetryModeAdaptive},
+	}
+	for _, tt := range tests {
+		collection, err := NewAWSClientCollection(context.Background(), tt.opts)
+		if err != nil {
+			t.Fatalf("NewAWSClientCollection(%+v) error = %v", tt.opts, err)
+		}
+		options := collection.S3.(*s3.Client).Options()
+		if got := options.Retryer.MaxAttempts(); got != tt.wantAttempts {
+			t.Errorf("%+v: max attempts = %d, want %d", tt.opts, got, tt.wantAttempts)
+		}
+		if options.RetryMode != tt.wantMode {
+			t.Errorf("%+v: retry mode = %q, want %q", tt.opts, options.RetryMode, tt.wantMode)
+		}
+	}
+}
+
+// hasRateLimit reports whether the API options install the RateLimit middleware.
+func hasRateLimit(t *testing.T, apiOptions []func(*middleware.Stack) error) bool {
+	t.Helper()
+	stack := middleware.NewStack("test", nil)
+	for _, option := range apiOptions {
+		if err := option(stack); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, ok := stack.Finalize.Get("RateLimit")
+	return ok
+}
+
+func TestRateLimitPerService(t *testing.T) {
+	isolate(t)
+	collection, err := NewAWSClientCollection(context.Background(), Options{
+		MaxRetries:        -1,
+		ServiceRateLimits: map[string]int{ServiceEC2: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasRateLimit(t, collection.EC2.(*ec2.Client).Options().APIOptions) {
+		t.Error("ec2 client has no rate limit")
+	}
+	if hasRateLimit(t, collection.S3.(*s3.Client).Options().APIOptions) {
+		t.Error("s3 client is rate limited without a limit configured")
+	}
+
+	opts := Options{RateLimit: 10, ServiceRateLimits: map[string]int{ServiceS3: 0}}
+	tests := []struct {
+		service string
+		want    bool
+	}{
+		{service: ServiceEC2, want: true},
+		{service: ServiceCloudWatchLogs, want: true},
+		{service: ServiceS3, want: false},
+	}
+	for _, tt := range tests {
+		if got := hasRateLimit(t, opts.rateLimit(tt.service)); got != tt.want {
+			t.Errorf("rateLimit(%s) installed = %v, want %v", tt.service, got, tt.want)
+		}
+	}
+}
+
+func TestHandedOverCredentials(t *testing.T) {
+	isolate(t)
+	session := aws.Credentials{AccessKeyID: "ASIAEXAMPLE", SecretAccessKey: "secret", SessionToken: "token", CanExpire: true, Expires: time.Now().Add(time.Hour)}
+	collection, err := NewAWSClientCollection(context.Background(), Options{
+		MaxRetries:  -1,
+		RoleARN:     "arn:aws:iam::123456789012:role/admin",
+		MFASerial:   "arn:aws:iam::123456789012:mfa/dev",
+		Credentials: &session,
+		MFATokenProvider: func() (string, error) {
+			t.Error("prompted for an MFA token code")
+			return "", errors.New("no token")
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAWSClientCollection() error = %v", err)
+	}
+	got, err := collection.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("Credentials() error = %v", err)
+	}
+	if got.AccessKeyID != session.AccessKeyID || got.SessionToken != session.SessionToken {
+		t.Errorf("Credentials() = %+v, want the handed over session", got)
+	}
+}
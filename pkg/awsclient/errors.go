@@ -0,0 +1,92 @@
+package awsclient
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ResourceError annotates the error of an API call with the resource named in
+// its input, e.g. a bucket or an instance ID, so that failures can be reported
+// with the resource involved. Its message is the one of Err.
+type ResourceError struct {
+	Resource string
+	Err      error
+}
+
+func (e *ResourceError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ResourceError) Unwrap() error {
+	return e.Err
+}
+
+// resourceFields are the input fields identifying the target of a call, by priority.
+var resourceFields = []string{
+	"Bucket", "TableName", "DBInstanceIdentifier", "DBSnapshotIdentifier",
+	"AutoScalingGroupName", "AlarmName", "LogGroupName", "ResourceArn", "ResourceARN",
+	"ResourceName", "InstanceIds", "AutoScalingGroupNames", "AlarmNames", "Resources", "RoleArn",
+}
+
+// resourceErrors is the API option wrapping failed calls in a ResourceError.
+func resourceErrors(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ResourceError",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if err != nil {
+				if resource := inputResource(in.Parameters); resource != "" {
+					err = &ResourceError{Resource: resource, Err: err}
+				}
+			}
+			return out, metadata, err
+		}), middleware.After)
+}
+
+// inputResource returns the resource named in the input of a call, if any.
+func inputResource(params interface{}) string {
+	value := reflect.ValueOf(params)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+	input := value.Elem()
+	for _, name := range resourceFields {
+		field := input.FieldByName(name)
+		if !field.IsValid() {
+			continue
+		}
+		resource := fieldString(field)
+		if resource == "" {
+			continue
+		}
+		// An object is identified by its bucket and key
+		if key := input.FieldByName("Key"); name == "Bucket" && key.IsValid() {
+			if objectKey := fieldString(key); objectKey != "" {
+				resource += "/" + objectKey
+			}
+		}
+		return resource
+	}
+	return ""
+}
+
+func fieldString(field reflect.Value) string {
+	switch {
+	case field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.String:
+		if field.IsNil() {
+			return ""
+		}
+		return field.Elem().String()
+	case field.Kind() == reflect.String:
+		return field.String()
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		values := make([]string, field.Len())
+		for i := range values {
+			values[i] = field.Index(i).String()
+		}
+		return strings.Join(values, ",")
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package fake
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+)
+
+var _ awsclient.AutoScalingAPI = (*AutoScaling)(nil)
+
+// AutoScaling is a fake awsclient.AutoScalingAPI. Every operation is recorded and delegated to
+// the matching function field; operations without one return an empty output.
+type AutoScaling struct {
+	recorder
+
+	DescribeAutoScalingGroupsFunc func(context.Context, *autoscaling.DescribeAutoScalingGroupsInput) (*autoscaling.DescribeAutoScalingGroupsOutput, error)
+	CreateAutoScalingGroupFunc    func(context.Context, *autoscaling.CreateAutoScalingGroupInput) (*autoscaling.CreateAutoScalingGroupOutput, error)
+	UpdateAutoScalingGroupFunc    func(context.Context, *autoscaling.UpdateAutoScalingGroupInput) (*autoscaling.UpdateAutoScalingGroupOutput, error)
+	DeleteAutoScalingGroupFunc    func(context.Context, *autoscaling.DeleteAutoScalingGroupInput) (*autoscaling.DeleteAutoScalingGroupOutput, error)
+	CreateOrUpdateTagsFunc        func(context.Context, *autoscaling.CreateOrUpdateTagsInput) (*autoscaling.CreateOrUpdateTagsOutput, error)
+	DeleteTagsFunc                func(context.Context, *autoscaling.DeleteTagsInput) (*autoscaling.DeleteTagsOutput, error)
+}
+
+func (f *AutoScaling) DescribeAutoScalingGroups(ctx context.Context, params *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+	f.record("DescribeAutoScalingGroups", params)
+	if f.DescribeAutoScalingGroupsFunc != nil {
+		return f.DescribeAutoScalingGroupsFunc(ctx, params)
+	}
+	return &autoscaling.DescribeAutoScalingGroupsOutput{}, nil
+}
+
+func (f *AutoScaling) CreateAutoScalingGroup(ctx context.Context, params *autoscaling.CreateAutoScalingGroupInput, optFns ...func(*autoscaling.Options)) (*autoscaling.CreateAutoScalingGroupOutput, error) {
+	f.record("CreateAutoScalingGroup", params)
+	if f.CreateAutoScalingGroupFunc != nil {
+		return f.CreateAutoScalingGroupFunc(ctx, params)
+	}
+	return &autoscaling.CreateAutoScalingGroupOutput{}, nil
+}
+
+func (f *AutoScaling) UpdateAutoScalingGroup(ctx context.Context, params *autoscaling.UpdateAutoScalingGroupInput, optFns ...func(*autoscaling.Options)) (*autoscaling.UpdateAutoScalingGroupOutput, error) {
+	f.record("UpdateAutoScalingGroup", params)
+	if f.UpdateAutoScalingGroupFunc != nil {
+		return f.UpdateAutoScalingGroupFunc(ctx, params)
+	}
+	return &autoscaling.UpdateAutoScalingGroupOutput{}, nil
+}
+
+func (f *AutoScaling) DeleteAutoScalingGroup(ctx context.Context, params *autoscaling.DeleteAutoScalingGroupInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DeleteAutoScalingGroupOutput, error) {
+	f.record("DeleteAutoScalingGroup", params)
+	if f.DeleteAutoScalingGroupFunc != nil {
+		return f.DeleteAutoScalingGroupFunc(ctx, params)
+	}
+	return &autoscaling.DeleteAutoScalingGroupOutput{}, nil
+}
+
+func (f *AutoScaling) CreateOrUpdateTags(ctx context.Context, params *autoscaling.CreateOrUpdateTagsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.CreateOrUpdateTagsOutput, error) {
+	f.record("CreateOrUpdateTags", params)
+	if f.CreateOrUpdateTagsFunc != nil {
+		return f.CreateOrUpdateTagsFunc(ctx, params)
+	}
+	return &autoscaling.CreateOrUpdateTagsOutput{}, nil
+}
+
+func (f *AutoScaling) DeleteTags(ctx context.Context, params *autoscaling.DeleteTagsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DeleteTagsOutput, error) {
+	f.record("DeleteTags", params)
+	if f.DeleteTagsFunc != nil {
+		return f.DeleteTagsFunc(ctx, params)
+	}
+	return &autoscaling.DeleteTagsOutput{}, nil
+}
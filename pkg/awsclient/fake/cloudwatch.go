@@ -0,0 +1,89 @@
+package fake
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+)
+
+var _ awsclient.CloudWatchAPI = (*CloudWatch)(nil)
+
+// CloudWatch is a fake awsclient.CloudWatchAPI. Every operation is recorded and delegated to
+// the matching function field; operations without one return an empty output.
+type CloudWatch struct {
+	recorder
+
+	DescribeAlarmsFunc      func(context.Context, *cloudwatch.DescribeAlarmsInput) (*cloudwatch.DescribeAlarmsOutput, error)
+	PutMetricAlarmFunc      func(context.Context, *cloudwatch.PutMetricAlarmInput) (*cloudwatch.PutMetricAlarmOutput, error)
+	DeleteAlarmsFunc        func(context.Context, *cloudwatch.DeleteAlarmsInput) (*cloudwatch.DeleteAlarmsOutput, error)
+	ListMetricsFunc         func(context.Context, *cloudwatch.ListMetricsInput) (*cloudwatch.ListMetricsOutput, error)
+	PutMetricDataFunc       func(context.Context, *cloudwatch.PutMetricDataInput) (*cloudwatch.PutMetricDataOutput, error)
+	ListTagsForResourceFunc func(context.Context, *cloudwatch.ListTagsForResourceInput) (*cloudwatch.ListTagsForResourceOutput, error)
+	TagResourceFunc         func(context.Context, *cloudwatch.TagResourceInput) (*cloudwatch.TagResourceOutput, error)
+	UntagResourceFunc       func(context.Context, *cloudwatch.UntagResourceInput) (*cloudwatch.UntagResourceOutput, error)
+}
+
+func (f *CloudWatch) DescribeAlarms(ctx context.Context, params *cloudwatch.DescribeAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DescribeAlarmsOutput, error) {
+	f.record("DescribeAlarms", params)
+	if f.DescribeAlarmsFunc != nil {
+		return f.DescribeAlarmsFunc(ctx, params)
+	}
+	return &cloudwatch.DescribeAlarmsOutput{}, nil
+}
+
+func (f *CloudWatch) PutMetricAlarm(ctx context.Context, params *cloudwatch.PutMetricAlarmInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricAlarmOutput, error) {
+	f.record("PutMetricAlarm", params)
+	if f.PutMetricAlarmFunc != nil {
+		return f.PutMetricAlarmFunc(ctx, params)
+	}
+	return &cloudwatch.PutMetricAlarmOutput{}, nil
+}
+
+func (f *CloudWatch) DeleteAlarms(ctx context.Context, params *cloudwatch.DeleteAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DeleteAlarmsOutput, error) {
+	f.record("DeleteAlarms", params)
+	if f.DeleteAlarmsFunc != nil {
+		return f.DeleteAlarmsFunc(ctx, params)
+	}
+	return &cloudwatch.DeleteAlarmsOutput{}, nil
+}
+
+func (f *CloudWatch) ListMetrics(ctx context.Context, params *cloudwatch.ListMetricsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.ListMetricsOutput, error) {
+	f.record("ListMetrics", params)
+	if f.ListMetricsFunc != nil {
+		return f.ListMetricsFunc(ctx, params)
+	}
+	return &cloudwatch.ListMetricsOutput{}, nil
+}
+
+func (f *CloudWatch) PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error) {
+	f.record("PutMetricData", params)
+	if f.PutMetricDataFunc != nil {
+		return f.PutMetricDataFunc(ctx, params)
+	}
+	return &cloudwatch.PutMetricDataOutput{}, nil
+}
+
+func (f *CloudWatch) ListTagsForResource(ctx context.Context, params *cloudwatch.ListTagsForResourceInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.ListTagsForResourceOutput, error) {
+	f.record("ListTagsForResource", params)
+	if f.ListTagsForResourceFunc != nil {
+		return f.ListTagsForResourceFunc(ctx, params)
+	}
+	return &cloudwatch.ListTagsForResourceOutput{}, nil
+}
+
+func (f *CloudWatch) TagResource(ctx context.Context, params *cloudwatch.TagResourceInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.TagResourceOutput, error) {
+	f.record("TagResource", params)
+	if f.TagResourceFunc != nil {
+		return f.TagResourceFunc(ctx, params)
+	}
+	return &cloudwatch.TagResourceOutput{}, nil
+}
+
+func (f *CloudWatch) UntagResource(ctx context.Context, params *cloudwatch.UntagResourceInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.UntagResourceOutput, error) {
+	f.record("UntagResource", params)
+	if f.UntagResourceFunc != nil {
+		return f.UntagResourceFunc(ctx, params)
+	}
+	return &cloudwatch.UntagResourceOutput{}, nil
+}
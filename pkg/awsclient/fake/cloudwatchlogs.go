@@ -0,0 +1,116 @@
+package fake
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+var _ awsclient.CloudWatchLogsAPI = (*CloudWatchLogs)(nil)
+
+// CloudWatchLogs is a fake awsclient.CloudWatchLogsAPI. Every operation is recorded and delegated to
+// the matching function field; operations without one return an empty output.
+type CloudWatchLogs struct {
+	recorder
+
+	DescribeLogGroupsFunc     func(context.Context, *cloudwatchlogs.DescribeLogGroupsInput) (*cloudwatchlogs.DescribeLogGroupsOutput, error)
+	CreateLogGroupFunc        func(context.Context, *cloudwatchlogs.CreateLogGroupInput) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	DeleteLogGroupFunc        func(context.Context, *cloudwatchlogs.DeleteLogGroupInput) (*cloudwatchlogs.DeleteLogGroupOutput, error)
+	ListTagsLogGroupFunc      func(context.Context, *cloudwatchlogs.ListTagsLogGroupInput) (*cloudwatchlogs.ListTagsLogGroupOutput, error)
+	ListTagsForResourceFunc   func(context.Context, *cloudwatchlogs.ListTagsForResourceInput) (*cloudwatchlogs.ListTagsForResourceOutput, error)
+	DescribeLogStreamsFunc    func(context.Context, *cloudwatchlogs.DescribeLogStreamsInput) (*cloudwatchlogs.DescribeLogStreamsOutput, error)
+	GetLogEventsFunc          func(context.Context, *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error)
+	PutRetentionPolicyFunc    func(context.Context, *cloudwatchlogs.PutRetentionPolicyInput) (*cloudwatchlogs.PutRetentionPolicyOutput, error)
+	DeleteRetentionPolicyFunc func(context.Context, *cloudwatchlogs.DeleteRetentionPolicyInput) (*cloudwatchlogs.DeleteRetentionPolicyOutput, error)
+	TagResourceFunc           func(context.Context, *cloudwatchlogs.TagResourceInput) (*cloudwatchlogs.TagResourceOutput, error)
+	UntagResourceFunc         func(context.Context, *cloudwatchlogs.UntagResourceInput) (*cloudwatchlogs.UntagResourceOutput, error)
+}
+
+func (f *CloudWatchLogs) DescribeLogGroups(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	f.record("DescribeLogGroups", params)
+	if f.DescribeLogGroupsFunc != nil {
+		return f.DescribeLogGroupsFunc(ctx, params)
+	}
+	return &cloudwatchlogs.DescribeLogGroupsOutput{}, nil
+}
+
+func (f *CloudWatchLogs) CreateLogGroup(ctx context.Context, params *cloudwatchlogs.CreateLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error) {
+	f.record("CreateLogGroup", params)
+	if f.CreateLogGroupFunc != nil {
+		return f.CreateLogGroupFunc(ctx, params)
+	}
+	return &cloudwatchlogs.CreateLogGroupOutput{}, nil
+}
+
+func (f *CloudWatchLogs) DeleteLogGroup(ctx context.Context, params *cloudwatchlogs.DeleteLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+	f.record("DeleteLogGroup", params)
+	if f.DeleteLogGroupFunc != nil {
+		return f.DeleteLogGroupFunc(ctx, params)
+	}
+	return &cloudwatchlogs.DeleteLogGroupOutput{}, nil
+}
+
+func (f *CloudWatchLogs) ListTagsLogGroup(ctx context.Context, params *cloudwatchlogs.ListTagsLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsLogGroupOutput, error) {
+	f.record("ListTagsLogGroup", params)
+	if f.ListTagsLogGroupFunc != nil {
+		return f.ListTagsLogGroupFunc(ctx, params)
+	}
+	return &cloudwatchlogs.ListTagsLogGroupOutput{}, nil
+}
+
+func (f *CloudWatchLogs) ListTagsForResource(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+	f.record("ListTagsForResource", params)
+	if f.ListTagsForResourceFunc != nil {
+		return f.ListTagsForResourceFunc(ctx, params)
+	}
+	return &cloudwatchlogs.ListTagsForResourceOutput{}, nil
+}
+
+func (f *CloudWatchLogs) DescribeLogStreams(ctx context.Context, params *cloudwatchlogs.DescribeLogStreamsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+	f.record("DescribeLogStreams", params)
+	if f.DescribeLogStreamsFunc != nil {
+		return f.DescribeLogStreamsFunc(ctx, params)
+	}
+	return &cloudwatchlogs.DescribeLogStreamsOutput{}, nil
+}
+
+func (f *CloudWatchLogs) GetLogEvents(ctx context.Context, params *cloudwatchlogs.GetLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	f.record("GetLogEvents", params)
+	if f.GetLogEventsFunc != nil {
+		return f.GetLogEventsFunc(ctx, params)
+	}
+	return &cloudwatchlogs.GetLogEventsOutput{}, nil
+}
+
+func (f *CloudWatchLogs) PutRetentionPolicy(ctx context.Context, params *cloudwatchlogs.PutRetentionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutRetentionPolicyOutput, error) {
+	f.record("PutRetentionPolicy", params)
+	if f.PutRetentionPolicyFunc != nil {
+		return f.PutRetentionPolicyFunc(ctx, params)
+	}
+	return &cloudwatchlogs.PutRetentionPolicyOutput{}, nil
+}
+
+func (f *CloudWatchLogs) DeleteRetentionPolicy(ctx context.Context, params *cloudwatchlogs.DeleteRetentionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteRetentionPolicyOutput, error) {
+	f.record("DeleteRetentionPolicy", params)
+	if f.DeleteRetentionPolicyFunc != nil {
+		return f.DeleteRetentionPolicyFunc(ctx, params)
+	}
+	return &cloudwatchlogs.DeleteRetentionPolicyOutput{}, nil
+}
+
+func (f *CloudWatchLogs) TagResource(ctx context.Context, params *cloudwatchlogs.TagResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.TagResourceOutput, error) {
+	f.record("TagResource", params)
+	if f.TagResourceFunc != nil {
+		return f.TagResourceFunc(ctx, params)
+	}
+	return &cloudwatchlogs.TagResourceOutput{}, nil
+}
+
+func (f *CloudWatchLogs) UntagResource(ctx context.Context, params *cloudwatchlogs.UntagResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.UntagResourceOutput, error) {
+	f.record("UntagResource", params)
+	if f.UntagResourceFunc != nil {
+		return f.UntagResourceFunc(ctx, params)
+	}
+	return &cloudwatchlogs.UntagResourceOutput{}, nil
+}
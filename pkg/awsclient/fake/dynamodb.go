@@ -0,0 +1,125 @@
+package fake
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+var _ awsclient.DynamoDBAPI = (*DynamoDB)(nil)
+
+// DynamoDB is a fake awsclient.DynamoDBAPI. Every operation is recorded and delegated to
+// the matching function field; operations without one return an empty output.
+type DynamoDB struct {
+	recorder
+
+	ListTablesFunc         func(context.Context, *dynamodb.ListTablesInput) (*dynamodb.ListTablesOutput, error)
+	DescribeTableFunc      func(context.Context, *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error)
+	CreateTableFunc        func(context.Context, *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error)
+	DeleteTableFunc        func(context.Context, *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error)
+	GetItemFunc            func(context.Context, *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
+	PutItemFunc            func(context.Context, *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
+	DeleteItemFunc         func(context.Context, *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
+	QueryFunc              func(context.Context, *dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+	UpdateTableFunc        func(context.Context, *dynamodb.UpdateTableInput) (*dynamodb.UpdateTableOutput, error)
+	ListTagsOfResourceFunc func(context.Context, *dynamodb.ListTagsOfResourceInput) (*dynamodb.ListTagsOfResourceOutput, error)
+	TagResourceFunc        func(context.Context, *dynamodb.TagResourceInput) (*dynamodb.TagResourceOutput, error)
+	UntagResourceFunc      func(context.Context, *dynamodb.UntagResourceInput) (*dynamodb.UntagResourceOutput, error)
+}
+
+func (f *DynamoDB) ListTables(ctx context.Context, params *dynamodb.ListTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error) {
+	f.record("ListTables", params)
+	if f.ListTablesFunc != nil {
+		return f.ListTablesFunc(ctx, params)
+	}
+	return &dynamodb.ListTablesOutput{}, nil
+}
+
+func (f *DynamoDB) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	f.record("DescribeTable", params)
+	if f.DescribeTableFunc != nil {
+		return f.DescribeTableFunc(ctx, params)
+	}
+	return &dynamodb.DescribeTableOutput{}, nil
+}
+
+func (f *DynamoDB) CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	f.record("CreateTable", params)
+	if f.CreateTableFunc != nil {
+		return f.CreateTableFunc(ctx, params)
+	}
+	return &dynamodb.CreateTableOutput{}, nil
+}
+
+func (f *DynamoDB) DeleteTable(ctx context.Context, params *dynamodb.DeleteTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error) {
+	f.record("DeleteTable", params)
+	if f.DeleteTableFunc != nil {
+		return f.DeleteTableFunc(ctx, params)
+	}
+	return &dynamodb.DeleteTableOutput{}, nil
+}
+
+func (f *DynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	f.record("GetItem", params)
+	if f.GetItemFunc != nil {
+		return f.GetItemFunc(ctx, params)
+	}
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+func (f *DynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.record("PutItem", params)
+	if f.PutItemFunc != nil {
+		return f.PutItemFunc(ctx, params)
+	}
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *DynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	f.record("DeleteItem", params)
+	if f.DeleteItemFunc != nil {
+		return f.DeleteItemFunc(ctx, params)
+	}
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (f *DynamoDB) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	f.record("Query", params)
+	if f.QueryFunc != nil {
+		return f.QueryFunc(ctx, params)
+	}
+	return &dynamodb.QueryOutput{}, nil
+}
+
+func (f *DynamoDB) UpdateTable(ctx context.Context, params *dynamodb.UpdateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTableOutput, error) {
+	f.record("UpdateTable", params)
+	if f.UpdateTableFunc != nil {
+		return f.UpdateTableFunc(ctx, params)
+	}
+	return &dynamodb.UpdateTableOutput{}, nil
+}
+
+func (f *DynamoDB) ListTagsOfResource(ctx context.Context, params *dynamodb.ListTagsOfResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error) {
+	f.record("ListTagsOfResource", params)
+	if f.ListTagsOfResourceFunc != nil {
+		return f.ListTagsOfResourceFunc(ctx, params)
+	}
+	return &dynamodb.ListTagsOfResourceOutput{}, nil
+}
+
+func (f *DynamoDB) TagResource(ctx context.Context, params *dynamodb.TagResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TagResourceOutput, error) {
+	f.record("TagResource", params)
+	if f.TagResourceFunc != nil {
+		return f.TagResourceFunc(ctx, params)
+	}
+	return &dynamodb.TagResourceOutput{}, nil
+}
+
+func (f *DynamoDB) UntagResource(ctx context.Context, params *dynamodb.UntagResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UntagResourceOutput, error) {
+	f.record("UntagResource", params)
+	if f.UntagResourceFunc != nil {
+		return f.UntagResourceFunc(ctx, params)
+	}
+	return &dynamodb.UntagResourceOutput{}, nil
+}
@@ -0,0 +1,98 @@
+package fake
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+var _ awsclient.EC2API = (*EC2)(nil)
+
+// EC2 is a fake awsclient.EC2API. Every operation is recorded and delegated to
+// the matching function field; operations without one return an empty output.
+type EC2 struct {
+	recorder
+
+	DescribeInstancesFunc  func(context.Context, *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error)
+	RunInstancesFunc       func(context.Context, *ec2.RunInstancesInput) (*ec2.RunInstancesOutput, error)
+	StartInstancesFunc     func(context.Context, *ec2.StartInstancesInput) (*ec2.StartInstancesOutput, error)
+	StopInstancesFunc      func(context.Context, *ec2.StopInstancesInput) (*ec2.StopInstancesOutput, error)
+	RebootInstancesFunc    func(context.Context, *ec2.RebootInstancesInput) (*ec2.RebootInstancesOutput, error)
+	TerminateInstancesFunc func(context.Context, *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error)
+	DescribeRegionsFunc    func(context.Context, *ec2.DescribeRegionsInput) (*ec2.DescribeRegionsOutput, error)
+	CreateTagsFunc         func(context.Context, *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error)
+	DeleteTagsFunc         func(context.Context, *ec2.DeleteTagsInput) (*ec2.DeleteTagsOutput, error)
+}
+
+func (f *EC2) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	f.record("DescribeInstances", params)
+	if f.DescribeInstancesFunc != nil {
+		return f.DescribeInstancesFunc(ctx, params)
+	}
+	return &ec2.DescribeInstancesOutput{}, nil
+}
+
+func (f *EC2) RunInstances(ctx context.Context, params *ec2.RunInstancesInput, optFns ...func(*ec2.Options)) (*ec2.RunInstancesOutput, error) {
+	f.record("RunInstances", params)
+	if f.RunInstancesFunc != nil {
+		return f.RunInstancesFunc(ctx, params)
+	}
+	return &ec2.RunInstancesOutput{}, nil
+}
+
+func (f *EC2) StartInstances(ctx context.Context, params *ec2.StartInstancesInput, optFns ...func(*ec2.Options)) (*ec2.StartInstancesOutput, error) {
+	f.record("StartInstances", params)
+	if f.StartInstancesFunc != nil {
+		return f.StartInstancesFunc(ctx, params)
+	}
+	return &ec2.StartInstancesOutput{}, nil
+}
+
+func (f *EC2) StopInstances(ctx context.Context, params *ec2.StopInstancesInput, optFns ...func(*ec2.Options)) (*ec2.StopInstancesOutput, error) {
+	f.record("StopInstances", params)
+	if f.StopInstancesFunc != nil {
+		return f.StopInstancesFunc(ctx, params)
+	}
+	return &ec2.StopInstancesOutput{}, nil
+}
+
+func (f *EC2) RebootInstances(ctx context.Context, params *ec2.RebootInstancesInput, optFns ...func(*ec2.Options)) (*ec2.RebootInstancesOutput, error) {
+	f.record("RebootInstances", params)
+	if f.RebootInstancesFunc != nil {
+		return f.RebootInstancesFunc(ctx, params)
+	}
+	return &ec2.RebootInstancesOutput{}, nil
+}
+
+func (f *EC2) TerminateInstances(ctx context.Context, params *ec2.TerminateInstancesInput, optFns ...func(*ec2.Options)) (*ec2.TerminateInstancesOutput, error) {
+	f.record("TerminateInstances", params)
+	if f.TerminateInstancesFunc != nil {
+		return f.TerminateInstancesFunc(ctx, params)
+	}
+	return &ec2.TerminateInstancesOutput{}, nil
+}
+
+func (f *EC2) DescribeRegions(ctx context.Context, params *ec2.DescribeRegionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error) {
+	f.record("DescribeRegions", params)
+	if f.DescribeRegionsFunc != nil {
+		return f.DescribeRegionsFunc(ctx, params)
+	}
+	return &ec2.DescribeRegionsOutput{}, nil
+}
+
+func (f *EC2) CreateTags(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	f.record("CreateTags", params)
+	if f.CreateTagsFunc != nil {
+		return f.CreateTagsFunc(ctx, params)
+	}
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func (f *EC2) DeleteTags(ctx context.Context, params *ec2.DeleteTagsInput, optFns ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+	f.record("DeleteTags", params)
+	if f.DeleteTagsFunc != nil {
+		return f.DeleteTagsFunc(ctx, params)
+	}
+	return &ec2.DeleteTagsOutput{}, nil
+}
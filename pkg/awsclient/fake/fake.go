@@ -0,0 +1,82 @@
+// Package fake provides in-memory implementations of the awsclient API
+// interfaces so commands can be exercised without calling AWS.
+package fake
+
+import (
+	"icp-aws-cli/pkg/awsclient"
+	"sync"
+)
+
+// Call is a single operation invoked on a fake client.
+type Call struct {
+	Operation string
+	Input     interface{}
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) record(operation string, input interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Operation: operation, Input: input})
+}
+
+// Calls returns the operations invoked so far, in order.
+func (r *recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call{}, r.calls...)
+}
+
+// Operations returns the names of the operations invoked so far, in order.
+func (r *recorder) Operations() []string {
+	calls := r.Calls()
+	operations := make([]string, len(calls))
+	for i, call := range calls {
+		operations[i] = call.Operation
+	}
+	return operations
+}
+
+// Clients groups one fake per service.
+type Clients struct {
+	S3             *S3
+	EC2            *EC2
+	DynamoDB       *DynamoDB
+	AutoScaling    *AutoScaling
+	RDS            *RDS
+	CloudWatch     *CloudWatch
+	CloudWatchLogs *CloudWatchLogs
+	STS            *STS
+}
+
+// New returns fakes for every service with no behaviour configured.
+func New() *Clients {
+	return &Clients{
+		S3:             &S3{},
+		EC2:            &EC2{},
+		DynamoDB:       &DynamoDB{},
+		AutoScaling:    &AutoScaling{},
+		RDS:            &RDS{},
+		CloudWatch:     &CloudWatch{},
+		CloudWatchLogs: &CloudWatchLogs{},
+		STS:            &STS{},
+	}
+}
+
+// Collection returns a client collection backed by the fakes.
+func (c *Clients) Collection() *awsclient.AWSClientCollection {
+	return &awsclient.AWSClientCollection{
+		S3:             c.S3,
+		EC2:            c.EC2,
+		DynamoDB:       c.DynamoDB,
+		AutoScaling:    c.AutoScaling,
+		RDS:            c.RDS,
+		CloudWatch:     c.CloudWatch,
+		CloudWatchLogs: c.CloudWatchLogs,
+		STS:            c.STS,
+	}
+}
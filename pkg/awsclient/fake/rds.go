@@ -0,0 +1,107 @@
+package fake
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+)
+
+var _ awsclient.RDSAPI = (*RDS)(nil)
+
+// RDS is a fake awsclient.RDSAPI. Every operation is recorded and delegated to
+// the matching function field; operations without one return an empty output.
+type RDS struct {
+	recorder
+
+	DescribeDBInstancesFunc    func(context.Context, *rds.DescribeDBInstancesInput) (*rds.DescribeDBInstancesOutput, error)
+	CreateDBInstanceFunc       func(context.Context, *rds.CreateDBInstanceInput) (*rds.CreateDBInstanceOutput, error)
+	DeleteDBInstanceFunc       func(context.Context, *rds.DeleteDBInstanceInput) (*rds.DeleteDBInstanceOutput, error)
+	StartDBInstanceFunc        func(context.Context, *rds.StartDBInstanceInput) (*rds.StartDBInstanceOutput, error)
+	StopDBInstanceFunc         func(context.Context, *rds.StopDBInstanceInput) (*rds.StopDBInstanceOutput, error)
+	DescribeDBSnapshotsFunc    func(context.Context, *rds.DescribeDBSnapshotsInput) (*rds.DescribeDBSnapshotsOutput, error)
+	CreateDBSnapshotFunc       func(context.Context, *rds.CreateDBSnapshotInput) (*rds.CreateDBSnapshotOutput, error)
+	DeleteDBSnapshotFunc       func(context.Context, *rds.DeleteDBSnapshotInput) (*rds.DeleteDBSnapshotOutput, error)
+	AddTagsToResourceFunc      func(context.Context, *rds.AddTagsToResourceInput) (*rds.AddTagsToResourceOutput, error)
+	RemoveTagsFromResourceFunc func(context.Context, *rds.RemoveTagsFromResourceInput) (*rds.RemoveTagsFromResourceOutput, error)
+}
+
+func (f *RDS) DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error) {
+	f.record("DescribeDBInstances", params)
+	if f.DescribeDBInstancesFunc != nil {
+		return f.DescribeDBInstancesFunc(ctx, params)
+	}
+	return &rds.DescribeDBInstancesOutput{}, nil
+}
+
+func (f *RDS) CreateDBInstance(ctx context.Context, params *rds.CreateDBInstanceInput, optFns ...func(*rds.Options)) (*rds.CreateDBInstanceOutput, error) {
+	f.record("CreateDBInstance", params)
+	if f.CreateDBInstanceFunc != nil {
+		return f.CreateDBInstanceFunc(ctx, params)
+	}
+	return &rds.CreateDBInstanceOutput{}, nil
+}
+
+func (f *RDS) DeleteDBInstance(ctx context.Context, params *rds.DeleteDBInstanceInput, optFns ...func(*rds.Options)) (*rds.DeleteDBInstanceOutput, error) {
+	f.record("DeleteDBInstance", params)
+	if f.DeleteDBInstanceFunc != nil {
+		return f.DeleteDBInstanceFunc(ctx, params)
+	}
+	return &rds.DeleteDBInstanceOutput{}, nil
+}
+
+func (f *RDS) StartDBInstance(ctx context.Context, params *rds.StartDBInstanceInput, optFns ...func(*rds.Options)) (*rds.StartDBInstanceOutput, error) {
+	f.record("StartDBInstance", params)
+	if f.StartDBInstanceFunc != nil {
+		return f.StartDBInstanceFunc(ctx, params)
+	}
+	return &rds.StartDBInstanceOutput{}, nil
+}
+
+func (f *RDS) StopDBInstance(ctx context.Context, params *rds.StopDBInstanceInput, optFns ...func(*rds.Options)) (*rds.StopDBInstanceOutput, error) {
+	f.record("StopDBInstance", params)
+	if f.StopDBInstanceFunc != nil {
+		return f.StopDBInstanceFunc(ctx, params)
+	}
+	return &rds.StopDBInstanceOutput{}, nil
+}
+
+func (f *RDS) DescribeDBSnapshots(ctx context.Context, params *rds.DescribeDBSnapshotsInput, optFns ...func(*rds.Options)) (*rds.DescribeDBSnapshotsOutput, error) {
+	f.record("DescribeDBSnapshots", params)
+	if f.DescribeDBSnapshotsFunc != nil {
+		return f.DescribeDBSnapshotsFunc(ctx, params)
+	}
+	return &rds.DescribeDBSnapshotsOutput{}, nil
+}
+
+func (f *RDS) CreateDBSnapshot(ctx context.Context, params *rds.CreateDBSnapshotInput, optFns ...func(*rds.Options)) (*rds.CreateDBSnapshotOutput, error) {
+	f.record("CreateDBSnapshot", params)
+	if f.CreateDBSnapshotFunc != nil {
+		return f.CreateDBSnapshotFunc(ctx, params)
+	}
+	return &rds.CreateDBSnapshotOutput{}, nil
+}
+
+func (f *RDS) DeleteDBSnapshot(ctx context.Context, params *rds.DeleteDBSnapshotInput, optFns ...func(*rds.Options)) (*rds.DeleteDBSnapshotOutput, error) {
+	f.record("DeleteDBSnapshot", params)
+	if f.DeleteDBSnapshotFunc != nil {
+		return f.DeleteDBSnapshotFunc(ctx, params)
+	}
+	return &rds.DeleteDBSnapshotOutput{}, nil
+}
+
+func (f *RDS) AddTagsToResource(ctx context.Context, params *rds.AddTagsToResourceInput, optFns ...func(*rds.Options)) (*rds.AddTagsToResourceOutput, error) {
+	f.record("AddTagsToResource", params)
+	if f.AddTagsToResourceFunc != nil {
+		return f.AddTagsToResourceFunc(ctx, params)
+	}
+	return &rds.AddTagsToResourceOutput{}, nil
+}
+
+func (f *RDS) RemoveTagsFromResource(ctx context.Context, params *rds.RemoveTagsFromResourceInput, optFns ...func(*rds.Options)) (*rds.RemoveTagsFromResourceOutput, error) {
+	f.record("RemoveTagsFromResource", params)
+	if f.RemoveTagsFromResourceFunc != nil {
+		return f.RemoveTagsFromResourceFunc(ctx, params)
+	}
+	return &rds.RemoveTagsFromResourceOutput{}, nil
+}
@@ -0,0 +1,98 @@
+package fake
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var _ awsclient.S3API = (*S3)(nil)
+
+// S3 is a fake awsclient.S3API. Every operation is recorded and delegated to
+// the matching function field; operations without one return an empty output.
+type S3 struct {
+	recorder
+
+	ListBucketsFunc         func(context.Context, *s3.ListBucketsInput) (*s3.ListBucketsOutput, error)
+	ListObjectsV2Func       func(context.Context, *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error)
+	CopyObjectFunc          func(context.Context, *s3.CopyObjectInput) (*s3.CopyObjectOutput, error)
+	CreateBucketFunc        func(context.Context, *s3.CreateBucketInput) (*s3.CreateBucketOutput, error)
+	DeleteBucketFunc        func(context.Context, *s3.DeleteBucketInput) (*s3.DeleteBucketOutput, error)
+	DeleteObjectFunc        func(context.Context, *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error)
+	GetBucketTaggingFunc    func(context.Context, *s3.GetBucketTaggingInput) (*s3.GetBucketTaggingOutput, error)
+	PutBucketTaggingFunc    func(context.Context, *s3.PutBucketTaggingInput) (*s3.PutBucketTaggingOutput, error)
+	DeleteBucketTaggingFunc func(context.Context, *s3.DeleteBucketTaggingInput) (*s3.DeleteBucketTaggingOutput, error)
+}
+
+func (f *S3) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+	f.record("ListBuckets", params)
+	if f.ListBucketsFunc != nil {
+		return f.ListBucketsFunc(ctx, params)
+	}
+	return &s3.ListBucketsOutput{}, nil
+}
+
+func (f *S3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.record("ListObjectsV2", params)
+	if f.ListObjectsV2Func != nil {
+		return f.ListObjectsV2Func(ctx, params)
+	}
+	return &s3.ListObjectsV2Output{}, nil
+}
+
+func (f *S3) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	f.record("CopyObject", params)
+	if f.CopyObjectFunc != nil {
+		return f.CopyObjectFunc(ctx, params)
+	}
+	return &s3.CopyObjectOutput{}, nil
+}
+
+func (f *S3) CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error) {
+	f.record("CreateBucket", params)
+	if f.CreateBucketFunc != nil {
+		return f.CreateBucketFunc(ctx, params)
+	}
+	return &s3.CreateBucketOutput{}, nil
+}
+
+func (f *S3) DeleteBucket(ctx context.Context, params *s3.DeleteBucketInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketOutput, error) {
+	f.record("DeleteBucket", params)
+	if f.DeleteBucketFunc != nil {
+		return f.DeleteBucketFunc(ctx, params)
+	}
+	return &s3.DeleteBucketOutput{}, nil
+}
+
+func (f *S3) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	f.record("DeleteObject", params)
+	if f.DeleteObjectFunc != nil {
+		return f.DeleteObjectFunc(ctx, params)
+	}
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *S3) GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	f.record("GetBucketTagging", params)
+	if f.GetBucketTaggingFunc != nil {
+		return f.GetBucketTaggingFunc(ctx, params)
+	}
+	return &s3.GetBucketTaggingOutput{}, nil
+}
+
+func (f *S3) PutBucketTagging(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error) {
+	f.record("PutBucketTagging", params)
+	if f.PutBucketTaggingFunc != nil {
+		return f.PutBucketTaggingFunc(ctx, params)
+	}
+	return &s3.PutBucketTaggingOutput{}, nil
+}
+
+func (f *S3) DeleteBucketTagging(ctx context.Context, params *s3.DeleteBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketTaggingOutput, error) {
+	f.record("DeleteBucketTagging", params)
+	if f.DeleteBucketTaggingFunc != nil {
+		return f.DeleteBucketTaggingFunc(ctx, params)
+	}
+	return &s3.DeleteBucketTaggingOutput{}, nil
+}
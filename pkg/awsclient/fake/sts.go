@@ -0,0 +1,35 @@
+package fake
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+var _ awsclient.STSAPI = (*STS)(nil)
+
+// STS is a fake awsclient.STSAPI. Every operation is recorded and delegated to
+// the matching function field; operations without one return an empty output.
+type STS struct {
+	recorder
+
+	GetCallerIdentityFunc          func(context.Context, *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error)
+	DecodeAuthorizationMessageFunc func(context.Context, *sts.DecodeAuthorizationMessageInput) (*sts.DecodeAuthorizationMessageOutput, error)
+}
+
+func (f *STS) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	f.record("GetCallerIdentity", params)
+	if f.GetCallerIdentityFunc != nil {
+		return f.GetCallerIdentityFunc(ctx, params)
+	}
+	return &sts.GetCallerIdentityOutput{}, nil
+}
+
+func (f *STS) DecodeAuthorizationMessage(ctx context.Context, params *sts.DecodeAuthorizationMessageInput, optFns ...func(*sts.Options)) (*sts.DecodeAuthorizationMessageOutput, error) {
+	f.record("DecodeAuthorizationMessage", params)
+	if f.DecodeAuthorizationMessageFunc != nil {
+		return f.DecodeAuthorizationMessageFunc(ctx, params)
+	}
+	return &sts.DecodeAuthorizationMessageOutput{}, nil
+}
@@ -0,0 +1,90 @@
+package awsclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// logger returns the structured logger for the configured verbosity, or nil when
+// neither Verbose nor Debug is set. Logs go to LogOutput, stderr by default;
+// anything other than stderr is written as JSON lines.
+func (o Options) logger() *slog.Logger {
+	if !o.Verbose && !o.Debug {
+		return nil
+	}
+
+	level := slog.LevelInfo
+	if o.Debug {
+		level = slog.LevelDebug
+	}
+	handlerOptions := &slog.HandlerOptions{Level: level}
+
+	var w io.Writer = os.Stderr
+	if o.LogOutput != nil {
+		w = o.LogOutput
+	}
+	if w == io.Writer(os.Stderr) {
+		return slog.New(slog.NewTextHandler(w, handlerOptions))
+	}
+	return slog.New(slog.NewJSONHandler(w, handlerOptions))
+}
+
+// loggingOptions routes the SDK client logs (requests, responses, retries and
+// signing) to logger in debug mode.
+func (o Options) loggingOptions(logger *slog.Logger) []func(*config.LoadOptions) error {
+	if logger == nil || !o.Debug {
+		return nil
+	}
+	sdkLogger := logging.LoggerFunc(func(classification logging.Classification, format string, v ...interface{}) {
+		logger.Debug(fmt.Sprintf(format, v...), "source", "sdk", "classification", string(classification))
+	})
+	return []func(*config.LoadOptions) error{
+		config.WithLogger(sdkLogger),
+		config.WithClientLogMode(aws.LogRequest | aws.LogResponse | aws.LogRetries | aws.LogSigning),
+	}
+}
+
+// callLog returns the API option logging every call with its latency, attempts
+// and request ID, or nil without a logger.
+func callLog(logger *slog.Logger) []func(*middleware.Stack) error {
+	if logger == nil {
+		return nil
+	}
+	// Added after the service metadata is registered, and wrapping the retry loop so the latency includes every attempt
+	return []func(*middleware.Stack) error{func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("CallLog",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				start := time.Now()
+				out, metadata, err := next.HandleInitialize(ctx, in)
+
+				attrs := []any{
+					"service", awsmiddleware.GetServiceID(ctx),
+					"operation", awsmiddleware.GetOperationName(ctx),
+					"latency", time.Since(start),
+				}
+				if results, ok := retry.GetAttemptResults(metadata); ok {
+					attrs = append(attrs, "attempts", len(results.Results))
+				}
+				if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+					attrs = append(attrs, "request_id", requestID)
+				}
+				if err != nil {
+					logger.Warn("api call failed", append(attrs, "error", err.Error())...)
+				} else {
+					logger.Info("api call", attrs...)
+				}
+				return out, metadata, err
+			}), middleware.After)
+	}}
+}
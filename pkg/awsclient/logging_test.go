@@ -0,0 +1,67 @@
+package awsclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestCallLog(t *testing.T) {
+	isolate(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-amz-request-id", "REQ123")
+		if strings.Contains(r.URL.Path, "private") {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+			return
+		}
+		w.Write([]byte(`<ListAllMyBucketsResult></ListAllMyBucketsResult>`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	collection, err := NewAWSClientCollection(context.Background(), Options{MaxRetries: 0, EndpointURL: server.URL, Verbose: true, LogOutput: &logs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := collection.S3.ListBuckets(context.Background(), &s3.ListBucketsInput{}); err != nil {
+		t.Fatalf("ListBuckets() error = %v", err)
+	}
+	if _, err := collection.S3.DeleteBucket(context.Background(), &s3.DeleteBucketInput{Bucket: aws.String("private")}); err == nil {
+		t.Fatalf("DeleteBucket() error = nil, want access denied")
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logs = %q, want a line per call", logs.String())
+	}
+	tests := []struct {
+		level, msg, operation string
+	}{
+		{level: "INFO", msg: "api call", operation: "ListBuckets"},
+		{level: "WARN", msg: "api call failed", operation: "DeleteBucket"},
+	}
+	for i, tt := range tests {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", lines[i], err)
+		}
+		if entry["level"] != tt.level || entry["msg"] != tt.msg || entry["service"] != "S3" || entry["operation"] != tt.operation ||
+			entry["attempts"] != 1.0 || entry["request_id"] != "REQ123" || entry["latency"] == nil {
+			t.Errorf("log line = %v, want %s %q for S3 %s", entry, tt.level, tt.msg, tt.operation)
+		}
+	}
+}
+
+func TestNoCallLog(t *testing.T) {
+	if logger := (Options{}).logger(); logger != nil {
+		t.Errorf("logger() without --verbose or --debug = %v, want nil", logger)
+	}
+}
@@ -0,0 +1,33 @@
+package awsclient
+
+import (
+	"context"
+
+	"github.com/aws/smithy-go/middleware"
+	"golang.org/x/time/rate"
+)
+
+// rateLimit returns the API option limiting the requests sent by the client of
+// service, or nil when no limit is configured. Each client gets its own limiter,
+// so bulk paths hitting one service do not slow down the others.
+func (o Options) rateLimit(service string) []func(*middleware.Stack) error {
+	requestsPerSecond := o.RateLimit
+	if limit, ok := o.ServiceRateLimits[service]; ok {
+		requestsPerSecond = limit
+	}
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+
+	limiter := rate.NewLimiter(rate.Limit(requestsPerSecond), requestsPerSecond)
+	// Added at the end of the finalize step, after the retry middleware, so that retried attempts are limited too
+	return []func(*middleware.Stack) error{func(stack *middleware.Stack) error {
+		return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("RateLimit",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				if err := limiter.Wait(ctx); err != nil {
+					return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+				}
+				return next.HandleFinalize(ctx, in)
+			}), middleware.After)
+	}}
+}
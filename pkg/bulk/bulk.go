@@ -0,0 +1,41 @@
+// Package bulk applies an operation to a list of resources one at a time and
+// reports how far it got when it stops early.
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/dryrun"
+	"os"
+	"strings"
+)
+
+// Run calls fn for every name in order. It stops at the first failure or as soon
+// as ctx is cancelled (Ctrl-C, --timeout), and then prints to stderr which
+// resources were processed and which were not. done is the past tense of the
+// operation, e.g. "deleted".
+func Run(ctx context.Context, done, kind string, names []string, fn func(ctx context.Context, name string) error) error {
+	if !dryrun.Enabled() {
+		audit.Targets(names...)
+	}
+	for i, name := range names {
+		if err := ctx.Err(); err != nil {
+			report(done, kind, names, i)
+			return fmt.Errorf("operation interrupted: %w", err)
+		}
+		if err := fn(ctx, name); err != nil {
+			report(done, kind, names, i)
+			return err
+		}
+	}
+	return nil
+}
+
+func report(done, kind string, names []string, processed int) {
+	if len(names) < 2 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d %s(s) %s before stopping\n", processed, len(names), kind, done)
+	fmt.Fprintf(os.Stderr, "Not processed: %s\n", strings.Join(names[processed:], ", "))
+}
@@ -0,0 +1,46 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	errFailed := errors.New("UnauthorizedOperation")
+	tests := []struct {
+		name     string
+		failOn   string
+		cancelOn string
+		want     []string
+		wantErr  error
+	}{
+		{name: "every resource", want: []string{"i-1", "i-2", "i-3"}},
+		{name: "stops at the first failure", failOn: "i-2", want: []string{"i-1", "i-2"}, wantErr: errFailed},
+		{name: "stops once cancelled", cancelOn: "i-1", want: []string{"i-1"}, wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			called := []string{}
+			err := Run(ctx, "stopped", "instance", []string{"i-1", "i-2", "i-3"}, func(ctx context.Context, name string) error {
+				called = append(called, name)
+				if name == tt.cancelOn {
+					cancel()
+				}
+				if name == tt.failOn {
+					return errFailed
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(called, tt.want) {
+				t.Errorf("processed %q, want %q", called, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Package completion suggests resource identifiers for shell completion by
+// calling the relevant Describe/List API, with a short-lived on-disk cache.
+package completion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// CacheTTL is how long listed identifiers are reused between completions.
+const CacheTTL = time.Minute
+
+// Fetch lists candidate identifiers. A candidate may carry a description after a tab.
+type Fetch func(ctx context.Context) ([]string, error)
+
+// Func is the signature cobra expects for argument and flag completion.
+type Func func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+var prepare func(cmd *cobra.Command) (string, error)
+
+// SetPrepare registers how a command gets ready to call AWS before completing.
+// Cobra skips PersistentPreRunE for completion requests, so prepare must resolve
+// the global flags and clients itself. It returns a scope (e.g. profile and
+// region) separating the cache entries.
+func SetPrepare(fn func(cmd *cobra.Command) (string, error)) {
+	prepare = fn
+}
+
+// Resources returns a completion function suggesting the identifiers listed by
+// fetch. kind names the resource type in the cache.
+func Resources(kind string, fetch Fetch) Func {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		scope := ""
+		if prepare != nil {
+			var err error
+			if scope, err = prepare(cmd); err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+		}
+
+		key := kind + "|" + scope
+		candidates, ok := readCache(key)
+		if !ok {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			var err error
+			if candidates, err = fetch(ctx); err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			writeCache(key, candidates)
+		}
+
+		matches := []string{}
+		for _, candidate := range candidates {
+			if strings.HasPrefix(candidate, toComplete) {
+				matches = append(matches, candidate)
+			}
+		}
+		return matches, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// Args completes positional arguments: positions maps an argument index to its
+// completion function; other positions get no suggestion.
+func Args(positions map[int]Func) Func {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if fn, ok := positions[len(args)]; ok {
+			return fn(cmd, args, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+type cacheEntry struct {
+	Time       time.Time `json:"time"`
+	Candidates []string  `json:"candidates"`
+}
+
+func cachePath(key string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, "icp-aws-cli", "completion", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func readCache(key string) ([]string, bool) {
+	path, err := cachePath(key)
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || time.Since(entry.Time) > CacheTTL {
+		return nil, false
+	}
+	return entry.Candidates, true
+}
+
+// writeCache stores candidates on a best-effort basis; completion works without a cache.
+func writeCache(key string, candidates []string) {
+	path, err := cachePath(key)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cacheEntry{Time: time.Now(), Candidates: candidates})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,115 @@
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// counter returns a Fetch listing candidates and the number of times it ran.
+func counter(candidates ...string) (Fetch, *int) {
+	calls := 0
+	return func(context.Context) ([]string, error) {
+		calls++
+		return candidates, nil
+	}, &calls
+}
+
+// age moves the cache entry of key back by d.
+func age(t *testing.T, key string, d time.Duration) {
+	t.Helper()
+	path, err := cachePath(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	entry.Time = entry.Time.Add(-d)
+	if data, err = json.Marshal(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResourcesCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	scope := "default|us-east-1"
+	SetPrepare(func(*cobra.Command) (string, error) { return scope, nil })
+	t.Cleanup(func() { SetPrepare(nil) })
+
+	fetch, calls := counter("web-assets", "web-logs", "api-data")
+	complete := Resources("bucket", fetch)
+
+	got, directive := complete(&cobra.Command{}, nil, "web-")
+	if want := []string{"web-assets", "web-logs"}; !reflect.DeepEqual(got, want) || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Fatalf("complete() = %v, %v, want %v", got, directive, want)
+	}
+	complete(&cobra.Command{}, nil, "")
+	if *calls != 1 {
+		t.Errorf("fetched %d times within the TTL, want 1", *calls)
+	}
+
+	age(t, "bucket|"+scope, CacheTTL-time.Second)
+	complete(&cobra.Command{}, nil, "")
+	if *calls != 1 {
+		t.Errorf("fetched %d times before the TTL expired, want 1", *calls)
+	}
+
+	age(t, "bucket|"+scope, 2*time.Second)
+	complete(&cobra.Command{}, nil, "")
+	if *calls != 2 {
+		t.Errorf("fetched %d times after the TTL expired, want 2", *calls)
+	}
+
+	scope = "prod|eu-west-1"
+	complete(&cobra.Command{}, nil, "")
+	if *calls != 3 {
+		t.Errorf("fetched %d times for a new scope, want 3", *calls)
+	}
+}
+
+func TestResourcesErrors(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { SetPrepare(nil) })
+
+	failing := Resources("table", func(context.Context) ([]string, error) { return nil, errors.New("access denied") })
+	if _, directive := failing(&cobra.Command{}, nil, ""); directive != cobra.ShellCompDirectiveError {
+		t.Errorf("directive on fetch error = %v, want ShellCompDirectiveError", directive)
+	}
+
+	SetPrepare(func(*cobra.Command) (string, error) { return "", errors.New("no credentials") })
+	fetch, calls := counter("orders")
+	if _, directive := Resources("table", fetch)(&cobra.Command{}, nil, ""); directive != cobra.ShellCompDirectiveError || *calls != 0 {
+		t.Errorf("directive on prepare error = %v after %d fetches, want ShellCompDirectiveError and none", directive, *calls)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	fetch, _ := counter("orders")
+	complete := Args(map[int]Func{1: Resources("table", fetch)})
+
+	if got, _ := complete(&cobra.Command{}, []string{"first"}, ""); !reflect.DeepEqual(got, []string{"orders"}) {
+		t.Errorf("second argument completes to %v, want [orders]", got)
+	}
+	if got, directive := complete(&cobra.Command{}, nil, ""); got != nil || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("first argument completes to %v, %v, want nothing", got, directive)
+	}
+}
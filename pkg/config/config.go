@@ -0,0 +1,213 @@
+// Package config loads the user configuration file, which supplies default flag
+// values, named profiles of defaults and command aliases.
+//
+//	default_profile: staging
+//	flags:                      # applied to every command
+//	  output: json
+//	commands:                   # applied to a command path and its subcommands
+//	  autoscaling update:
+//	    group-name: web
+//	  cloudwatch create-alarm:
+//	    tags: [team=platform, env=dev]
+//	profiles:
+//	  prod:
+//	    flags:
+//	      profile: prod
+//	      region: us-east-1
+//	aliases:
+//	  scale-web: autoscaling update --group-name web
+package config
+
+import (
+	"errors"
+	"fmt"
+	"icp-aws-cli/pkg/shellwords"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvPath overrides the location of the configuration file.
+	EnvPath = "ICP_AWS_CLI_CONFIG"
+	// EnvProfile selects the profile of defaults when --config-profile is not given.
+	EnvProfile = "ICP_AWS_CLI_CONFIG_PROFILE"
+)
+
+// Defaults are flag values keyed by flag name, given globally or per command path.
+type Defaults struct {
+	Flags    map[string]interface{}            `yaml:"flags"`
+	Commands map[string]map[string]interface{} `yaml:"commands"`
+}
+
+// Config is the content of the configuration file.
+type Config struct {
+	Defaults       `yaml:",inline"`
+	DefaultProfile string              `yaml:"default_profile"`
+	Profiles       map[string]Defaults `yaml:"profiles"`
+	Aliases        map[string]string   `yaml:"aliases"`
+}
+
+// Path returns the configuration file location: $ICP_AWS_CLI_CONFIG or ~/.config/icp-aws-cli/config.yaml.
+func Path() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not locate the configuration file: %w", err)
+	}
+	return filepath.Join(home, ".config", "icp-aws-cli", "config.yaml"), nil
+}
+
+// Load reads the configuration file. A missing file yields an empty configuration.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read configuration file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ExpandAlias replaces a leading alias in args by its definition, split into
+// words like a shell does, so quoted values stay whole. Built-in commands take
+// precedence over aliases of the same name.
+func (c *Config) ExpandAlias(root *cobra.Command, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	expansion, ok := c.Aliases[args[0]]
+	if !ok {
+		return args, nil
+	}
+	for _, command := range root.Commands() {
+		if command.Name() == args[0] || command.HasAlias(args[0]) {
+			return args, nil
+		}
+	}
+	words, err := shellwords.Split(expansion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alias %s in configuration: %w", args[0], err)
+	}
+	return append(words, args[1:]...), nil
+}
+
+// ApplyDefaults sets the flags of cmd that were not given on the command line from
+// the configuration: global flags, then command paths from the outermost to cmd
+// itself, then the same from the selected profile. Later values win. The flags
+// set are not marked as changed, so commands can still tell defaults from the
+// flags given on the command line.
+func (c *Config) ApplyDefaults(cmd *cobra.Command, profile string) error {
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+	if profile == "" {
+		profile = c.DefaultProfile
+	}
+
+	path := commandPath(cmd)
+	values := map[string]interface{}{}
+	if err := c.Defaults.collect(cmd, path, values); err != nil {
+		return err
+	}
+	if profile != "" {
+		defaults, ok := c.Profiles[profile]
+		if !ok {
+			return fmt.Errorf("unknown configuration profile %q", profile)
+		}
+		if err := defaults.collect(cmd, path, values); err != nil {
+			return fmt.Errorf("profile %s: %w", profile, err)
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := setFlag(flag, values[name]); err != nil {
+			return fmt.Errorf("configuration default for --%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// collect gathers the defaults applying to path into values. Entries for the exact
+// command path must name existing flags, to catch typos.
+func (d Defaults) collect(cmd *cobra.Command, path []string, values map[string]interface{}) error {
+	for name, value := range d.Flags {
+		values[name] = value
+	}
+	for i := 1; i <= len(path); i++ {
+		key := strings.Join(path[:i], " ")
+		for name, value := range d.Commands[key] {
+			if i == len(path) && cmd.Flags().Lookup(name) == nil {
+				return fmt.Errorf("unknown flag --%s in configuration for %q", name, key)
+			}
+			values[name] = value
+		}
+	}
+	return nil
+}
+
+// commandPath returns the names of cmd and its parents, without the root command.
+func commandPath(cmd *cobra.Command) []string {
+	path := []string{}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		path = append([]string{c.Name()}, path...)
+	}
+	return path
+}
+
+// setFlag sets a flag from a YAML value, leaving it unchanged in the eyes of
+// cobra. Lists and maps are set one element at a time so slice and map flags
+// accumulate them.
+func setFlag(flag *pflag.Flag, value interface{}) error {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if err := flag.Value.Set(fmt.Sprint(item)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := flag.Value.Set(fmt.Sprintf("%s=%v", key, v[key])); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := flag.Value.Set(fmt.Sprint(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// newCommands builds "root autoscaling update" with flags like those of the CLI.
+func newCommands() (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "icp-aws-cli"}
+	root.PersistentFlags().String("profile", "", "")
+	root.PersistentFlags().StringP("output", "o", "table", "")
+	group := &cobra.Command{Use: "autoscaling"}
+	update := &cobra.Command{Use: "update", Run: func(*cobra.Command, []string) {}}
+	update.Flags().String("group-name", "", "")
+	update.Flags().Int32("min-size", 0, "")
+	update.Flags().StringSlice("tags", nil, "")
+	update.Flags().StringToString("labels", nil, "")
+	group.AddCommand(update)
+	root.AddCommand(group)
+	return root, update
+}
+
+func TestApplyDefaults(t *testing.T) {
+	cfg := &Config{
+		Defaults: Defaults{
+			Flags: map[string]interface{}{"output": "json", "profile": "dev"},
+			Commands: map[string]map[string]interface{}{
+				"autoscaling":        {"group-name": "api"},
+				"autoscaling update": {"group-name": "web", "tags": []interface{}{"team=platform", "env=dev"}},
+			},
+		},
+		DefaultProfile: "",
+		Profiles: map[string]Defaults{
+			"prod": {
+				Flags:    map[string]interface{}{"profile": "prod"},
+				Commands: map[string]map[string]interface{}{"autoscaling update": {"min-size": 2, "labels": map[string]interface{}{"b": 2, "a": 1}}},
+			},
+			"typo": {
+				Commands: map[string]map[string]interface{}{"autoscaling update": {"min-sise": 2}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		profile    string
+		envProfile string
+		want       map[string]string
+		wantErr    string
+	}{
+		{
+			name: "global and command defaults",
+			want: map[string]string{"output": "json", "profile": "dev", "group-name": "web", "tags": "[team=platform,env=dev]", "min-size": "0"},
+		},
+		{
+			name: "command line wins",
+			args: []string{"--output", "yaml", "--group-name", "api", "--tags", "a=b"},
+			want: map[string]string{"output": "yaml", "group-name": "api", "tags": "[a=b]"},
+		},
+		{
+			name:    "profile overrides",
+			profile: "prod",
+			want:    map[string]string{"profile": "prod", "min-size": "2", "labels": "[a=1,b=2]", "group-name": "web"},
+		},
+		{
+			name:       "profile from the environment",
+			envProfile: "prod",
+			want:       map[string]string{"profile": "prod", "min-size": "2"},
+		},
+		{name: "unknown profile", profile: "staging", wantErr: `unknown configuration profile "staging"`},
+		{name: "unknown flag", profile: "typo", wantErr: `unknown flag --min-sise in configuration for "autoscaling update"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvProfile, tt.envProfile)
+			_, update := newCommands()
+			if err := update.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			err := cfg.ApplyDefaults(update, tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ApplyDefaults() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyDefaults() error = %v", err)
+			}
+			for name, want := range tt.want {
+				if got := update.Flags().Lookup(name).Value.String(); got != want {
+					t.Errorf("--%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyDefaultsKeepsFlagsUnchanged(t *testing.T) {
+	cfg := &Config{Defaults: Defaults{Flags: map[string]interface{}{"profile": "dev"}}}
+	_, update := newCommands()
+	if err := update.ParseFlags([]string{"--output", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ApplyDefaults(update, ""); err != nil {
+		t.Fatal(err)
+	}
+	// A default profile must not pass for --profile given by the user
+	if update.Flags().Changed("profile") {
+		t.Error("Changed(profile) = true for a configuration default")
+	}
+	if !update.Flags().Changed("output") {
+		t.Error("Changed(output) = false for a flag of the command line")
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	cfg := &Config{Aliases: map[string]string{
+		"web":         `ec2 list --filter "tag:Name=my app" --state running`,
+		"autoscaling": "ec2 list",
+		"broken":      `ec2 list --filter "tag:Name=my app`,
+	}}
+	root, _ := newCommands()
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "no arguments", args: []string{}, want: []string{}},
+		{name: "not an alias", args: []string{"ec2", "list"}, want: []string{"ec2", "list"}},
+		{name: "quoted value", args: []string{"web", "-o", "json"}, want: []string{"ec2", "list", "--filter", "tag:Name=my app", "--state", "running", "-o", "json"}},
+		{name: "built-in command wins", args: []string{"autoscaling", "update"}, want: []string{"autoscaling", "update"}},
+		{name: "unterminated quote", args: []string{"broken"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cfg.ExpandAlias(root, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandAlias() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandAlias() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
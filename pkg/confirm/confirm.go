@@ -0,0 +1,95 @@
+// Package confirm asks the user to approve destructive operations once their
+// targets have been resolved.
+package confirm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/stdin"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// LargeBatch is the number of targets from which the user must type the target
+// count instead of "yes".
+const LargeBatch = 5
+
+// ErrCancelled is returned when the user declines the prompt.
+var ErrCancelled = errors.New("action cancelled by user")
+
+var assumeYes bool
+
+// SetAssumeYes skips every prompt, as requested with --yes.
+func SetAssumeYes(value bool) {
+	assumeYes = value
+}
+
+// Request describes a destructive action awaiting confirmation.
+type Request struct {
+	// Action is the verb shown to the user, e.g. "terminate".
+	Action string
+	// Kind names the resources, e.g. "EC2 instance".
+	Kind    string
+	Targets []string
+	// Strict makes the user type the resource name even for a single target,
+	// for deletions that cannot be undone such as buckets or tables.
+	Strict bool
+}
+
+// Confirm lists the targets of req and waits for the user's approval. It returns
+// nil without prompting under --yes or --dry-run, and refuses to prompt when
+// stdin is not interactive. Cancelling ctx while waiting for an answer declines the prompt.
+func Confirm(ctx context.Context, req Request) error {
+	if assumeYes || dryrun.Enabled() || len(req.Targets) == 0 {
+		return nil
+	}
+	if !isInteractive() {
+		return fmt.Errorf("refusing to %s %d %s(s) without confirmation: stdin is not a terminal (pass --yes to confirm)", req.Action, len(req.Targets), req.Kind)
+	}
+
+	fmt.Fprintf(os.Stderr, "About to %s %d %s(s):\n", req.Action, len(req.Targets), req.Kind)
+	for _, target := range req.Targets {
+		fmt.Fprintf(os.Stderr, "  - %s\n", target)
+	}
+
+	expected := "yes"
+	switch {
+	case len(req.Targets) >= LargeBatch:
+		expected = strconv.Itoa(len(req.Targets))
+		fmt.Fprintf(os.Stderr, "Type the number of targets (%s) to confirm: ", expected)
+	case req.Strict && len(req.Targets) == 1:
+		expected = req.Targets[0]
+		fmt.Fprintf(os.Stderr, "Type the %s name (%s) to confirm: ", req.Kind, expected)
+	default:
+		fmt.Fprint(os.Stderr, "Are you sure? (yes/no): ")
+	}
+
+	response, err := readLine(ctx)
+	if err != nil {
+		// Cancelled while waiting, or end of input
+		fmt.Fprintln(os.Stderr)
+		return ErrCancelled
+	}
+
+	response = strings.TrimSpace(response)
+	if expected == "yes" {
+		response = strings.ToLower(response)
+	}
+	if response != expected {
+		return ErrCancelled
+	}
+	return nil
+}
+
+// readLine reads the answer; tests replace it.
+var readLine = stdin.ReadLine
+
+// isInteractive reports whether stdin is a terminal; tests replace it.
+var isInteractive = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
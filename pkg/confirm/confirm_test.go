@@ -0,0 +1,89 @@
+package confirm
+
+import (
+	"context"
+	"errors"
+	"icp-aws-cli/pkg/dryrun"
+	"io"
+	"strings"
+	"testing"
+)
+
+// restorePrompt undoes the replacement of readLine and isInteractive by a test.
+func restorePrompt(t *testing.T) {
+	originalRead, originalInteractive := readLine, isInteractive
+	t.Cleanup(func() {
+		readLine, isInteractive = originalRead, originalInteractive
+	})
+}
+
+func TestConfirm(t *testing.T) {
+	restorePrompt(t)
+	one := []string{"i-0abc (web-1)"}
+	five := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		name        string
+		req         Request
+		yes         bool
+		dryRun      bool
+		interactive bool
+		answer      string
+		answerErr   error
+		wantPrompt  bool
+		wantErr     string
+	}{
+		{name: "--yes", req: Request{Action: "terminate", Kind: "EC2 instance", Targets: one}, yes: true},
+		{name: "--dry-run", req: Request{Action: "terminate", Kind: "EC2 instance", Targets: one}, dryRun: true},
+		{name: "no targets", req: Request{Action: "terminate", Kind: "EC2 instance"}},
+		{
+			name:    "stdin is not a terminal",
+			req:     Request{Action: "terminate", Kind: "EC2 instance", Targets: one},
+			wantErr: "refusing to terminate 1 EC2 instance(s) without confirmation",
+		},
+		{name: "yes", req: Request{Action: "stop", Kind: "EC2 instance", Targets: one}, interactive: true, answer: " YES ", wantPrompt: true},
+		{name: "no", req: Request{Action: "stop", Kind: "EC2 instance", Targets: one}, interactive: true, answer: "no", wantPrompt: true, wantErr: ErrCancelled.Error()},
+		{name: "end of input", req: Request{Action: "stop", Kind: "EC2 instance", Targets: one}, interactive: true, answerErr: io.EOF, wantPrompt: true, wantErr: ErrCancelled.Error()},
+		{name: "count typed", req: Request{Action: "stop", Kind: "EC2 instance", Targets: five}, interactive: true, answer: "5", wantPrompt: true},
+		{name: "yes instead of the count", req: Request{Action: "stop", Kind: "EC2 instance", Targets: five}, interactive: true, answer: "yes", wantPrompt: true, wantErr: ErrCancelled.Error()},
+		{name: "strict name typed", req: Request{Action: "delete", Kind: "bucket", Targets: []string{"web-assets"}, Strict: true}, interactive: true, answer: "web-assets", wantPrompt: true},
+		{name: "strict yes", req: Request{Action: "delete", Kind: "bucket", Targets: []string{"web-assets"}, Strict: true}, interactive: true, answer: "yes", wantPrompt: true, wantErr: ErrCancelled.Error()},
+		{name: "strict name in another case", req: Request{Action: "delete", Kind: "bucket", Targets: []string{"web-assets"}, Strict: true}, interactive: true, answer: "WEB-ASSETS", wantPrompt: true, wantErr: ErrCancelled.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetAssumeYes(tt.yes)
+			dryrun.SetEnabled(tt.dryRun)
+			defer SetAssumeYes(false)
+			defer dryrun.SetEnabled(false)
+			isInteractive = func() bool { return tt.interactive }
+			prompted := false
+			readLine = func(context.Context) (string, error) {
+				prompted = true
+				return tt.answer, tt.answerErr
+			}
+
+			err := Confirm(context.Background(), tt.req)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Confirm() error = %v, want %q", err, tt.wantErr)
+			}
+			if prompted != tt.wantPrompt {
+				t.Errorf("prompted = %v, want %v", prompted, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestConfirmCancelled(t *testing.T) {
+	restorePrompt(t)
+	isInteractive = func() bool { return true }
+	readLine = func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Confirm(ctx, Request{Action: "stop", Kind: "EC2 instance", Targets: []string{"i-0abc"}})
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("Confirm() error = %v, want %v", err, ErrCancelled)
+	}
+}
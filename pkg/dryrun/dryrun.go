@@ -0,0 +1,49 @@
+// Package dryrun holds the global --dry-run switch. Mutating commands resolve
+// their targets as usual and then report the planned operations instead of
+// calling the mutating API.
+package dryrun
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/smithy-go"
+)
+
+var enabled bool
+
+// SetEnabled turns dry-run mode on or off.
+func SetEnabled(value bool) {
+	enabled = value
+}
+
+// Enabled reports whether dry-run mode is on.
+func Enabled() bool {
+	return enabled
+}
+
+// Skip reports whether the mutating call described by format must be skipped.
+// In dry-run mode it prints the planned operation instead.
+func Skip(format string, args ...interface{}) bool {
+	if !enabled {
+		return false
+	}
+	Plan(format, args...)
+	return true
+}
+
+// Plan prints a planned operation.
+func Plan(format string, args ...interface{}) {
+	fmt.Printf("[dry-run] "+format+"\n", args...)
+}
+
+// CheckEC2 interprets the error of an EC2 call sent with DryRun set. EC2 answers
+// DryRunOperation when the request, including its IAM permissions, would have
+// succeeded; any other error (e.g. UnauthorizedOperation) is returned unchanged.
+func CheckEC2(err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "DryRunOperation" {
+		return nil
+	}
+	return err
+}
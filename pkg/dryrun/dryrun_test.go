@@ -0,0 +1,43 @@
+package dryrun
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestCheckEC2(t *testing.T) {
+	unauthorized := &smithy.GenericAPIError{Code: "UnauthorizedOperation"}
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "would succeed", err: &smithy.GenericAPIError{Code: "DryRunOperation"}, want: nil},
+		{name: "wrapped", err: fmt.Errorf("operation error: %w", &smithy.GenericAPIError{Code: "DryRunOperation"}), want: nil},
+		{name: "denied", err: unauthorized, want: unauthorized},
+		{name: "no error", err: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckEC2(tt.err); !errors.Is(got, tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("CheckEC2() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkip(t *testing.T) {
+	t.Cleanup(func() { SetEnabled(false) })
+
+	SetEnabled(false)
+	if Skip("Would delete %s", "web") {
+		t.Error("Skip() = true with dry-run off")
+	}
+	SetEnabled(true)
+	if !Skip("Would delete %s", "web") {
+		t.Error("Skip() = false with dry-run on")
+	}
+}
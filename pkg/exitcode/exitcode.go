@@ -0,0 +1,263 @@
+// Package exitcode classifies the error of a command into a documented exit
+// code, so that scripts can tell a missing resource from a denied, throttled or
+// invalid request, and describes it for machine-readable error output.
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/confirm"
+	"icp-aws-cli/pkg/tagging"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+)
+
+// Exit codes. They are part of the interface of the CLI: do not renumber them.
+const (
+	OK = 0
+	// General is any failure not classified below.
+	General = 1
+	// Usage is an invalid command line: unknown command or flag, wrong arguments.
+	Usage = 2
+	// NotFound is a resource that does not exist.
+	NotFound = 3
+	// AccessDenied is a request refused by IAM or a resource policy.
+	AccessDenied = 4
+	// Throttled is a request rejected for exceeding a rate limit, after retries.
+	Throttled = 5
+	// Conflict is a resource in a state that prevents the operation, e.g. in use,
+	// not empty or already existing.
+	Conflict = 6
+	// InvalidRequest is a request rejected by AWS for its parameters.
+	InvalidRequest = 7
+	// Credentials are missing, invalid or expired credentials, or a missing region.
+	Credentials = 8
+	// Cancelled is a declined confirmation prompt or an interruption (Ctrl-C).
+	Cancelled = 9
+	// Timeout is a command that exceeded --timeout.
+	Timeout = 10
+	// Unavailable is a service error or an endpoint that could not be reached.
+	Unavailable = 11
+)
+
+// names are the stable identifiers of the exit codes in JSON error output.
+var names = map[int]string{
+	General:        "error",
+	Usage:          "usage",
+	NotFound:       "not_found",
+	AccessDenied:   "access_denied",
+	Throttled:      "throttled",
+	Conflict:       "conflict",
+	InvalidRequest: "invalid_request",
+	Credentials:    "credentials",
+	Cancelled:      "cancelled",
+	Timeout:        "timeout",
+	Unavailable:    "unavailable",
+}
+
+// awsCodes maps the error codes of the AWS APIs that do not follow the naming
+// patterns recognised by classifyCode.
+var awsCodes = map[string]int{
+	"AccessDenied":                           AccessDenied,
+	"AccessDeniedException":                  AccessDenied,
+	"UnauthorizedOperation":                  AccessDenied,
+	"UnauthorizedAccess":                     AccessDenied,
+	"AuthorizationError":                     AccessDenied,
+	"AllAccessDisabled":                      AccessDenied,
+	"Forbidden":                              AccessDenied,
+	"OptInRequired":                          AccessDenied,
+	"ExpiredToken":                           Credentials,
+	"ExpiredTokenException":                  Credentials,
+	"InvalidClientTokenId":                   Credentials,
+	"UnrecognizedClientException":            Credentials,
+	"InvalidAccessKeyId":                     Credentials,
+	"InvalidToken":                           Credentials,
+	"SignatureDoesNotMatch":                  Credentials,
+	"InvalidSignatureException":              Credentials,
+	"AuthFailure":                            Credentials,
+	"RequestExpired":                         Credentials,
+	"MissingAuthenticationToken":             Credentials,
+	"RequestLimitExceeded":                   Throttled,
+	"SlowDown":                               Throttled,
+	"ProvisionedThroughputExceededException": Throttled,
+	"RequestThrottled":                       Throttled,
+	"BucketNotEmpty":                         Conflict,
+	"ResourceInUseException":                 Conflict,
+	"ResourceInUse":                          Conflict,
+	"ConditionalCheckFailedException":        Conflict,
+	"TransactionConflictException":           Conflict,
+	"OperationAborted":                       Conflict,
+	"IncorrectState":                         Conflict,
+	"IncorrectInstanceState":                 Conflict,
+	"ScalingActivityInProgress":              Conflict,
+	"ConflictException":                      Conflict,
+	"DependencyViolation":                    Conflict,
+	"InternalError":                          Unavailable,
+	"InternalFailure":                        Unavailable,
+	"InternalServerError":                    Unavailable,
+	"ServiceUnavailable":                     Unavailable,
+	"ServiceUnavailableException":            Unavailable,
+	"Unavailable":                            Unavailable,
+}
+
+// Details describes a classified error, e.g. for the JSON error output.
+type Details struct {
+	ExitCode int    `json:"exit_code"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	// AWSCode is the error code returned by the AWS API, if any.
+	AWSCode   string `json:"aws_error_code,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	Service   string `json:"service,omitempty"`
+	Operation string `json:"operation,omitempty"`
+	// Resource is the resource named in the failed request.
+	Resource string `json:"resource,omitempty"`
+}
+
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// UsageError marks err as an invalid command line. It returns nil for a nil err.
+func UsageError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &usageError{err}
+}
+
+// Of returns the exit code for err, OK when err is nil.
+func Of(err error) int {
+	if err == nil {
+		return OK
+	}
+	return Classify(err).ExitCode
+}
+
+// Classify describes err and the exit code it maps to.
+func Classify(err error) Details {
+	details := Details{Message: err.Error()}
+
+	var operationErr *smithy.OperationError
+	if errors.As(err, &operationErr) {
+		details.Service = operationErr.ServiceID
+		details.Operation = operationErr.OperationName
+	}
+	var resourceErr *awsclient.ResourceError
+	if errors.As(err, &resourceErr) {
+		details.Resource = resourceErr.Resource
+	}
+	var requestErr interface{ ServiceRequestID() string }
+	if errors.As(err, &requestErr) {
+		details.RequestID = requestErr.ServiceRequestID()
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		details.AWSCode = apiErr.ErrorCode()
+	}
+
+	details.ExitCode = classify(err, details.AWSCode)
+	details.Code = names[details.ExitCode]
+	if details.Code == "" {
+		// A status of its own, e.g. from a plugin
+		details.Code = names[General]
+	}
+	return details
+}
+
+func classify(err error, awsCode string) int {
+	var usageErr *usageError
+	var exitErr interface{ ExitCode() int }
+	var missingRegion *aws.MissingRegionError
+	switch {
+	case errors.As(err, &usageErr):
+		return Usage
+	case errors.As(err, &exitErr) && exitErr.ExitCode() > 0:
+		// The exit status of a plugin, which uses the same codes
+		return exitErr.ExitCode()
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.Is(err, context.Canceled), errors.Is(err, confirm.ErrCancelled):
+		return Cancelled
+	case errors.Is(err, tagging.ErrNotFound):
+		return NotFound
+	case errors.As(err, &missingRegion):
+		return Credentials
+	}
+
+	if awsCode != "" {
+		if code := classifyCode(awsCode); code != General {
+			return code
+		}
+	}
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		if code := classifyStatus(statusErr.HTTPStatusCode()); code != General {
+			return code
+		}
+	}
+
+	// The SDK reports these before sending the request, without a typed error
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "get identity: "), strings.Contains(message, "Missing Region"):
+		return Credentials
+	case strings.Contains(message, "request send failed"):
+		return Unavailable
+	}
+	return General
+}
+
+// classifyCode maps an AWS error code, first by name and then by the naming
+// conventions of the services, e.g. InvalidInstanceID.NotFound or NoSuchBucket.
+func classifyCode(code string) int {
+	if exitCode, ok := awsCodes[code]; ok {
+		return exitCode
+	}
+	switch {
+	case strings.HasPrefix(code, "NoSuch"), strings.HasSuffix(code, "NotFound"),
+		strings.HasSuffix(code, "NotFoundException"), strings.HasSuffix(code, "NotFoundFault"):
+		return NotFound
+	case strings.Contains(code, "Throttl"), strings.HasPrefix(code, "TooManyRequests"):
+		return Throttled
+	case strings.Contains(code, "AlreadyExists"), strings.Contains(code, "AlreadyOwned"),
+		strings.HasPrefix(code, "Invalid") && strings.HasSuffix(code, "State"),
+		strings.HasPrefix(code, "Invalid") && strings.HasSuffix(code, "StateFault"):
+		return Conflict
+	case strings.HasPrefix(code, "Validation"), strings.HasPrefix(code, "Invalid"),
+		strings.HasPrefix(code, "Missing"), strings.HasSuffix(code, ".Malformed"):
+		return InvalidRequest
+	}
+	return General
+}
+
+func classifyStatus(status int) int {
+	switch {
+	case status == 401:
+		return Credentials
+	case status == 403:
+		return AccessDenied
+	case status == 404:
+		return NotFound
+	case status == 409:
+		return Conflict
+	case status == 429:
+		return Throttled
+	case status == 400:
+		return InvalidRequest
+	case status >= 500:
+		return Unavailable
+	}
+	return General
+}
@@ -0,0 +1,142 @@
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/confirm"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+)
+
+func TestClassifyCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want int
+	}{
+		{code: "AccessDenied", want: AccessDenied},
+		{code: "UnauthorizedOperation", want: AccessDenied},
+		{code: "ExpiredToken", want: Credentials},
+		{code: "AuthFailure", want: Credentials},
+		{code: "InvalidSignatureException", want: Credentials},
+		{code: "NoSuchBucket", want: NotFound},
+		{code: "InvalidInstanceID.NotFound", want: NotFound},
+		{code: "ResourceNotFoundException", want: NotFound},
+		{code: "DBInstanceNotFoundFault", want: NotFound},
+		{code: "Throttling", want: Throttled},
+		{code: "ThrottlingException", want: Throttled},
+		{code: "TooManyRequestsException", want: Throttled},
+		{code: "RequestLimitExceeded", want: Throttled},
+		{code: "BucketAlreadyOwnedByYou", want: Conflict},
+		{code: "AlreadyExistsFault", want: Conflict},
+		{code: "InvalidDBInstanceState", want: Conflict},
+		{code: "InvalidDBInstanceStateFault", want: Conflict},
+		{code: "BucketNotEmpty", want: Conflict},
+		{code: "ValidationException", want: InvalidRequest},
+		{code: "InvalidParameterValue", want: InvalidRequest},
+		{code: "MissingParameter", want: InvalidRequest},
+		{code: "InvalidAMIID.Malformed", want: InvalidRequest},
+		{code: "ServiceUnavailable", want: Unavailable},
+		{code: "InternalError", want: Unavailable},
+		{code: "SomethingElse", want: General},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := classifyCode(tt.code); got != tt.want {
+				t.Errorf("classifyCode(%q) = %d, want %d", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+// statusError is an error carrying an HTTP status without an AWS error code.
+type statusError int
+
+func (e statusError) Error() string       { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatusCode() int { return int(e) }
+
+// exitError is an error carrying the exit status of a child process.
+type exitError int
+
+func (e exitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+func (e exitError) ExitCode() int { return int(e) }
+
+func TestClassify(t *testing.T) {
+	apiErr := &smithy.OperationError{
+		ServiceID:     "S3",
+		OperationName: "DeleteBucket",
+		Err:           &smithy.GenericAPIError{Code: "BucketNotEmpty", Message: "The bucket you tried to delete is not empty"},
+	}
+	tests := []struct {
+		name string
+		err  error
+		want Details
+	}{
+		{
+			name: "usage",
+			err:  UsageError(errors.New(`unknown flag: --bogus`)),
+			want: Details{ExitCode: Usage, Code: "usage", Message: "unknown flag: --bogus"},
+		},
+		{
+			name: "API error",
+			err:  fmt.Errorf("could not delete bucket: %w", &awsclient.ResourceError{Resource: "web-assets", Err: apiErr}),
+			want: Details{
+				ExitCode: Conflict, Code: "conflict", Message: "could not delete bucket: " + apiErr.Error(),
+				AWSCode: "BucketNotEmpty", Service: "S3", Operation: "DeleteBucket", Resource: "web-assets",
+			},
+		},
+		{
+			name: "HTTP status only",
+			err:  fmt.Errorf("could not get object: %w", statusError(403)),
+			want: Details{ExitCode: AccessDenied, Code: "access_denied", Message: "could not get object: status 403"},
+		},
+		{
+			name: "unknown code falls back to the status",
+			err:  statusError(503),
+			want: Details{ExitCode: Unavailable, Code: "unavailable", Message: "status 503"},
+		},
+		{
+			name: "timeout",
+			err:  fmt.Errorf("error describing instances: %w", context.DeadlineExceeded),
+			want: Details{ExitCode: Timeout, Code: "timeout", Message: "error describing instances: context deadline exceeded"},
+		},
+		{
+			name: "declined prompt",
+			err:  confirm.ErrCancelled,
+			want: Details{ExitCode: Cancelled, Code: "cancelled", Message: confirm.ErrCancelled.Error()},
+		},
+		{
+			name: "missing region",
+			err:  &aws.MissingRegionError{},
+			want: Details{ExitCode: Credentials, Code: "credentials", Message: (&aws.MissingRegionError{}).Error()},
+		},
+		{
+			name: "plugin exit status",
+			err:  fmt.Errorf("plugin report: %w", exitError(NotFound)),
+			want: Details{ExitCode: NotFound, Code: "not_found", Message: "plugin report: exit status 3"},
+		},
+		{
+			name: "plugin exit status without a name",
+			err:  exitError(42),
+			want: Details{ExitCode: 42, Code: "error", Message: "exit status 42"},
+		},
+		{
+			name: "general",
+			err:  errors.New("no instances found with the specified filters"),
+			want: Details{ExitCode: General, Code: "error", Message: "no instances found with the specified filters"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if got := Of(nil); got != OK {
+		t.Errorf("Of(nil) = %d, want %d", got, OK)
+	}
+}
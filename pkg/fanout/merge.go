@@ -0,0 +1,41 @@
+package fanout
+
+import (
+	"icp-aws-cli/pkg/output"
+)
+
+// merger combines the results of several queries, prefixing each record with
+// labels identifying where it came from, e.g. its region.
+type merger struct {
+	labels []string
+	result *output.Result
+}
+
+func newMerger(labels ...string) *merger {
+	return &merger{labels: labels}
+}
+
+// add appends the records of result with the label values given in order.
+func (m *merger) add(result *output.Result, values ...interface{}) {
+	if m.result == nil {
+		m.result = output.NewResult(append(append([]string{}, m.labels...), result.Columns...)...)
+	}
+	for _, record := range result.Records {
+		labelled := output.Record{}
+		for i, label := range m.labels {
+			labelled[label] = values[i]
+		}
+		for key, value := range record {
+			labelled[key] = value
+		}
+		m.result.AddRecord(labelled)
+	}
+}
+
+// print renders the merged records, if any query succeeded.
+func (m *merger) print() error {
+	if m.result == nil {
+		return nil
+	}
+	return output.Print(m.result)
+}
@@ -0,0 +1,306 @@
+package fanout
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/cobra"
+)
+
+// readOnlyAnnotation marks the commands that only read resources and may
+// therefore run across several profiles.
+const readOnlyAnnotation = "icp-aws-cli/read-only"
+
+// ReadOnly returns the annotations of a read-only command.
+func ReadOnly() map[string]string {
+	return map[string]string{readOnlyAnnotation: "true"}
+}
+
+// IsReadOnly reports whether cmd was annotated with ReadOnly.
+func IsReadOnly(cmd *cobra.Command) bool {
+	return cmd.Annotations[readOnlyAnnotation] == "true"
+}
+
+// Profiles resolves --profiles values. Names are used as given; values with
+// *, ? or [ are globs matched against the profiles of the shared config and
+// credentials files.
+func Profiles(patterns []string) ([]string, error) {
+	var known []string
+	seen := map[string]bool{}
+	profiles := []string{}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if !strings.ContainsAny(pattern, "*?[") {
+			if !seen[pattern] {
+				seen[pattern] = true
+				profiles = append(profiles, pattern)
+			}
+			continue
+		}
+
+		if known == nil {
+			known = sharedProfiles()
+		}
+		matched := false
+		for _, profile := range known {
+			ok, err := path.Match(pattern, profile)
+			if err != nil {
+				return nil, fmt.Errorf("invalid profile pattern %q: %w", pattern, err)
+			}
+			if ok {
+				matched = true
+				if !seen[profile] {
+					seen[profile] = true
+					profiles = append(profiles, profile)
+				}
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no profile matches %q", pattern)
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles to query")
+	}
+	return profiles, nil
+}
+
+// sharedProfiles lists the profiles defined in the shared config and credentials
+// files, honouring AWS_CONFIG_FILE and AWS_SHARED_CREDENTIALS_FILE.
+func sharedProfiles() []string {
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = config.DefaultSharedConfigFilename()
+	}
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = config.DefaultSharedCredentialsFilename()
+	}
+
+	seen := map[string]bool{}
+	for _, name := range sectionNames(configFile) {
+		// The config file prefixes every profile but the default one
+		if name == "default" {
+			seen[name] = true
+		} else if profile, ok := strings.CutPrefix(name, "profile "); ok {
+			seen[strings.TrimSpace(profile)] = true
+		}
+	}
+	for _, name := range sectionNames(credentialsFile) {
+		seen[name] = true
+	}
+
+	profiles := make([]string, 0, len(seen))
+	for profile := range seen {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// sectionNames returns the [section] names of an INI file; a missing file has none.
+func sectionNames(file string) []string {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	names := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			names = append(names, strings.TrimSpace(line[1:len(line)-1]))
+		}
+	}
+	return names
+}
+
+// EnvSession hands the credentials resolved for a profile to the command run
+// with it, as JSON, so the command neither assumes a role again nor prompts for
+// an MFA token code.
+const EnvSession = "ICP_AWS_CLI_SESSION"
+
+// Session returns the credentials handed over in EnvSession, or nil.
+func Session() (*aws.Credentials, error) {
+	value := os.Getenv(EnvSession)
+	if value == "" {
+		return nil, nil
+	}
+	var creds aws.Credentials
+	if err := json.Unmarshal([]byte(value), &creds); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvSession, err)
+	}
+	return &creds, nil
+}
+
+// profileRun is the outcome of the command for one profile.
+type profileRun struct {
+	account string
+	creds   aws.Credentials
+	result  *output.Result
+	stderr  []byte
+	err     error
+}
+
+// RunProfiles runs the command line args once per profile, concurrently, and
+// prints the merged results with Profile and Account columns. Each profile runs
+// in its own process, so commands need no changes to support it. A failing
+// profile is reported on stderr without aborting the others; RunProfiles fails
+// once the results are printed.
+func RunProfiles(ctx context.Context, opts awsclient.Options, profiles []string, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not locate the CLI executable: %w", err)
+	}
+
+	// Credentials are resolved one profile at a time, as assuming a role may
+	// prompt for an MFA token code on the terminal
+	runs := make([]profileRun, len(profiles))
+	for i, profile := range profiles {
+		runs[i] = resolveProfile(ctx, opts, profile)
+	}
+
+	sem := make(chan struct{}, Concurrency)
+	var wg sync.WaitGroup
+	for i, profile := range profiles {
+		if runs[i].err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, profile string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			runProfile(ctx, executable, profile, args, &runs[i])
+		}(i, profile)
+	}
+	wg.Wait()
+
+	merged := newMerger("Profile", "Account")
+	failed := 0
+	for i, profile := range profiles {
+		for _, line := range strings.Split(strings.TrimSpace(string(runs[i].stderr)), "\n") {
+			if line != "" {
+				fmt.Fprintf(os.Stderr, "[%s] %s\n", profile, line)
+			}
+		}
+		if runs[i].err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Error in profile %s: %v\n", profile, runs[i].err)
+			continue
+		}
+		merged.add(runs[i].result, profile, runs[i].account)
+	}
+
+	if err := merged.print(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d profiles failed", failed, len(profiles))
+	}
+	return nil
+}
+
+// resolveProfile resolves the credentials and the account of profile.
+func resolveProfile(ctx context.Context, opts awsclient.Options, profile string) profileRun {
+	opts.Profile = profile
+	clients, err := awsclient.NewAWSClientCollection(ctx, opts)
+	if err != nil {
+		return profileRun{err: err}
+	}
+	identity, err := clients.STS.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return profileRun{err: fmt.Errorf("could not resolve the account: %w", err)}
+	}
+	// Served from the credentials cache, the role is not assumed again
+	creds, err := clients.Credentials(ctx)
+	if err != nil {
+		return profileRun{err: fmt.Errorf("could not resolve the credentials: %w", err)}
+	}
+	return profileRun{account: aws.ToString(identity.Account), creds: creds}
+}
+
+// runProfile runs the command for profile with the credentials resolved in run.
+// The flags appended to args override any earlier --profile, --output and
+// --error-format. The command gets no stdin, as it has nothing to prompt for.
+func runProfile(ctx context.Context, executable, profile string, args []string, run *profileRun) {
+	session, err := json.Marshal(run.creds)
+	if err != nil {
+		run.err = fmt.Errorf("could not hand over the credentials: %w", err)
+		return
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, executable, append(append([]string{}, args...), "--profile", profile, "--output", string(output.FormatResult), "--error-format", "json")...)
+	cmd.Env = append(os.Environ(), EnvSession+"="+string(session))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		run.err = err
+		run.stderr = stderr.Bytes()
+		// Keep the error the command reported rather than its exit status
+		if message, rest, ok := childError(stderr.Bytes()); ok {
+			run.err = errors.New(message)
+			run.stderr = rest
+		}
+		return
+	}
+	run.stderr = stderr.Bytes()
+
+	run.result, err = output.DecodeResult(&stdout)
+	if err != nil {
+		run.err = fmt.Errorf("could not read the command output: %w", err)
+	}
+}
+
+// childError extracts the message of the JSON error object that a command run
+// with --error-format json writes last on stderr, and the output before it.
+func childError(stderr []byte) (string, []byte, bool) {
+	stderr = bytes.TrimRight(stderr, "\n")
+	start := bytes.LastIndexByte(stderr, '\n') + 1
+	var report struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(stderr[start:], &report); err != nil || report.Error.Message == "" {
+		return "", nil, false
+	}
+	return report.Error.Message, stderr[:start], true
+}
+
+// StripFlag removes every occurrence of the long flag name and its value from args.
+func StripFlag(args []string, name string) []string {
+	flag := "--" + name
+	stripped := []string{}
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == flag:
+			i++
+		case strings.HasPrefix(args[i], flag+"="):
+		default:
+			stripped = append(stripped, args[i])
+		}
+	}
+	return stripped
+}
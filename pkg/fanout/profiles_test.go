@@ -0,0 +1,216 @@
+package fanout
+
+import (
+	"bytes"
+	"encoding/json"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// sharedFiles points the SDK at a temporary config and credentials file.
+func sharedFiles(t *testing.T, config, credentials string) {
+	t.Helper()
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	credentialsFile := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(configFile, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credentialsFile, []byte(credentials), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+}
+
+const testConfig = `[default]
+region = us-east-1
+
+[profile prod-eu]
+region = eu-west-1
+# [profile commented-out]
+
+[ profile prod-us ]
+region = us-east-1
+
+[sso-session corp]
+sso_region = us-east-1
+`
+
+const testCredentials = `[dev]
+aws_access_key_id = AKIDEXAMPLE
+
+[prod-eu]
+aws_access_key_id = AKIDEXAMPLE
+`
+
+func TestSharedProfiles(t *testing.T) {
+	sharedFiles(t, testConfig, testCredentials)
+	want := []string{"default", "dev", "prod-eu", "prod-us"}
+	if got := sharedProfiles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sharedProfiles() = %v, want %v", got, want)
+	}
+
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "missing"))
+	if got, want := sharedProfiles(), []string{"dev", "prod-eu"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sharedProfiles() without a config file = %v, want %v", got, want)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	sharedFiles(t, testConfig, testCredentials)
+	tests := []struct {
+		patterns []string
+		want     []string
+		wantErr  string
+	}{
+		{patterns: []string{"prod-*"}, want: []string{"prod-eu", "prod-us"}},
+		{patterns: []string{"dev", " prod-eu", "prod-u?", "dev"}, want: []string{"dev", "prod-eu", "prod-us"}},
+		// Plain names are used as given, even when no file defines them
+		{patterns: []string{"staging"}, want: []string{"staging"}},
+		{patterns: []string{"*"}, want: []string{"default", "dev", "prod-eu", "prod-us"}},
+		{patterns: []string{"corp*"}, wantErr: `no profile matches "corp*"`},
+		{patterns: []string{"prod-[eu"}, wantErr: "invalid profile pattern"},
+		{patterns: []string{"", " "}, wantErr: "no profiles to query"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.patterns, ","), func(t *testing.T) {
+			got, err := Profiles(tt.patterns)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Profiles() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Profiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Profiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"ec2", "list", "--all"}, want: []string{"ec2", "list", "--all"}},
+		{args: []string{"ec2", "list", "--profiles", "prod-*", "--all"}, want: []string{"ec2", "list", "--all"}},
+		{args: []string{"--profiles=prod-*", "s3", "list", "--profiles", "dev"}, want: []string{"s3", "list"}},
+		// Other flags sharing the prefix are kept
+		{args: []string{"s3", "list", "--profiles-file", "x"}, want: []string{"s3", "list", "--profiles-file", "x"}},
+		{args: []string{"s3", "list", "--profiles"}, want: []string{"s3", "list"}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			if got := StripFlag(tt.args, "profiles"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StripFlag() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerger(t *testing.T) {
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+
+	empty := newMerger("Profile", "Account")
+	if err := empty.print(); err != nil || buf.Len() != 0 {
+		t.Errorf("print() without results = %q, %v, want nothing", buf.String(), err)
+	}
+
+	merged := newMerger("Profile", "Account")
+	dev := output.NewResult("Name", "State")
+	dev.Add("web-1", "running")
+	merged.add(dev, "dev", "111111111111")
+	prod := output.NewResult("Name", "State")
+	prod.Add("web-2", "stopped")
+	prod.Add("api-1", "running")
+	merged.add(prod, "prod", "222222222222")
+
+	if want := []string{"Profile", "Account", "Name", "State"}; !reflect.DeepEqual(merged.result.Columns, want) {
+		t.Errorf("columns = %v, want %v", merged.result.Columns, want)
+	}
+	if err := merged.print(); err != nil {
+		t.Fatal(err)
+	}
+	want := "dev\t111111111111\tweb-1\trunning\n" +
+		"prod\t222222222222\tweb-2\tstopped\n" +
+		"prod\t222222222222\tapi-1\trunning\n"
+	if buf.String() != want {
+		t.Errorf("print() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestChildError(t *testing.T) {
+	tests := []struct {
+		name        string
+		stderr      string
+		wantMessage string
+		wantRest    string
+		wantOK      bool
+	}{
+		{
+			name:        "error only",
+			stderr:      `{"error":{"code":"not_found","message":"bucket web-logs not found"}}` + "\n",
+			wantMessage: "bucket web-logs not found",
+			wantOK:      true,
+		},
+		{
+			name:        "after warnings",
+			stderr:      "Warning: 1 of 2 regions failed\n" + `{"error":{"code":"error","message":"no instances found"}}` + "\n",
+			wantMessage: "no instances found",
+			wantRest:    "Warning: 1 of 2 regions failed\n",
+			wantOK:      true,
+		},
+		{name: "plain text", stderr: "Error: unknown flag: --bogus\n"},
+		{name: "JSON without an error", stderr: `{"warning":"x"}` + "\n"},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, rest, ok := childError([]byte(tt.stderr))
+			if ok != tt.wantOK || message != tt.wantMessage || string(rest) != tt.wantRest {
+				t.Errorf("childError() = %q, %q, %v, want %q, %q, %v", message, rest, ok, tt.wantMessage, tt.wantRest, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSession(t *testing.T) {
+	t.Setenv(EnvSession, "")
+	if got, err := Session(); got != nil || err != nil {
+		t.Errorf("Session() without %s = %v, %v, want nil", EnvSession, got, err)
+	}
+
+	want := aws.Credentials{AccessKeyID: "ASIAEXAMPLE", SecretAccessKey: "secret", SessionToken: "token", CanExpire: true, Expires: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvSession, string(data))
+	got, err := Session()
+	if err != nil || got == nil || *got != want {
+		t.Errorf("Session() = %+v, %v, want %+v", got, err, want)
+	}
+
+	t.Setenv(EnvSession, "ASIAEXAMPLE")
+	if _, err := Session(); err == nil {
+		t.Errorf("Session() with an invalid value succeeded")
+	}
+}
@@ -0,0 +1,132 @@
+// Package fanout runs read-only queries across several regions concurrently
+// and merges their results into one output.
+package fanout
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/spf13/cobra"
+)
+
+// Concurrency bounds the number of regions queried at once.
+const Concurrency = 8
+
+// AllRegions selects every region enabled for the account.
+const AllRegions = "all"
+
+// Query lists resources with the clients of one region. token is the paging
+// token to resume from when --max-items truncated the listing, "" otherwise.
+type Query func(ctx context.Context, clients *awsclient.AWSClientCollection) (result *output.Result, token string, err error)
+
+// AddRegionsFlag registers --regions on cmd.
+func AddRegionsFlag(cmd *cobra.Command, regions *[]string) {
+	cmd.Flags().StringSliceVar(regions, "regions", nil, `Query several regions concurrently, e.g. us-east-1,eu-west-1, or "all" enabled regions`)
+}
+
+// Run prints the result of query. Without regions it queries the region of
+// clients; otherwise it queries every region concurrently and merges the
+// results with a Region column. A failing region does not hide the others:
+// its error is reported on stderr and Run fails once the results are printed.
+func Run(ctx context.Context, clients *awsclient.AWSClientCollection, regions []string, pagingOpts paging.Options, query Query) error {
+	if len(regions) == 0 {
+		result, token, err := query(ctx, clients)
+		if err != nil {
+			return err
+		}
+		paging.PrintNextToken(token)
+		return output.Print(result)
+	}
+
+	if pagingOpts.StartingToken != "" {
+		return fmt.Errorf("--starting-token cannot be combined with --regions")
+	}
+	regions, err := ResolveRegions(ctx, clients, regions)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*output.Result, len(regions))
+	errs := make([]error, len(regions))
+	truncated := make([]bool, len(regions))
+	sem := make(chan struct{}, Concurrency)
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		wg.Add(1)
+		go func(i int, region string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var token string
+			results[i], token, errs[i] = query(ctx, clients.ForRegion(region))
+			truncated[i] = token != ""
+		}(i, region)
+	}
+	wg.Wait()
+
+	merged := newMerger("Region")
+	failed := 0
+	for i, region := range regions {
+		if errs[i] != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Error in region %s: %v\n", region, errs[i])
+			continue
+		}
+		if truncated[i] {
+			fmt.Fprintf(os.Stderr, "Region %s: more items are available beyond --max-items\n", region)
+		}
+		merged.add(results[i], region)
+	}
+
+	if err := merged.print(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d regions failed", failed, len(regions))
+	}
+	return nil
+}
+
+// ResolveRegions expands "all" into the regions enabled for the account and
+// removes duplicates.
+func ResolveRegions(ctx context.Context, clients *awsclient.AWSClientCollection, regions []string) ([]string, error) {
+	if len(regions) == 1 && strings.EqualFold(regions[0], AllRegions) {
+		described, err := clients.EC2.DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+		if err != nil {
+			return nil, fmt.Errorf("could not list enabled regions: %w", err)
+		}
+		regions = []string{}
+		for _, region := range described.Regions {
+			regions = append(regions, aws.ToString(region.RegionName))
+		}
+		sort.Strings(regions)
+	}
+
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, region := range regions {
+		region = strings.TrimSpace(region)
+		if region == "" || seen[region] {
+			continue
+		}
+		if strings.EqualFold(region, AllRegions) {
+			return nil, fmt.Errorf(`"all" cannot be combined with other regions`)
+		}
+		seen[region] = true
+		unique = append(unique, region)
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("no regions to query")
+	}
+	return unique, nil
+}
@@ -0,0 +1,123 @@
+package fanout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/paging"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestResolveRegions(t *testing.T) {
+	clients := fake.New()
+	clients.EC2.DescribeRegionsFunc = func(context.Context, *ec2.DescribeRegionsInput) (*ec2.DescribeRegionsOutput, error) {
+		return &ec2.DescribeRegionsOutput{Regions: []types.Region{
+			{RegionName: aws.String("us-east-1")}, {RegionName: aws.String("eu-west-1")},
+		}}, nil
+	}
+	tests := []struct {
+		regions []string
+		want    []string
+		wantErr bool
+	}{
+		{regions: []string{"all"}, want: []string{"eu-west-1", "us-east-1"}},
+		{regions: []string{"ALL"}, want: []string{"eu-west-1", "us-east-1"}},
+		{regions: []string{"us-east-1", " eu-west-1", "us-east-1", ""}, want: []string{"us-east-1", "eu-west-1"}},
+		{regions: []string{"us-east-1", "all"}, wantErr: true},
+		{regions: []string{" ", ""}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.regions, ","), func(t *testing.T) {
+			got, err := ResolveRegions(context.Background(), clients.Collection(), tt.regions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveRegions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveRegions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// regionOf returns the region the clients of a fanned-out query were built for.
+func regionOf(clients *awsclient.AWSClientCollection) string {
+	return clients.EC2.(*ec2.Client).Options().Region
+}
+
+func TestRunMergesRegions(t *testing.T) {
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+
+	query := func(ctx context.Context, clients *awsclient.AWSClientCollection) (*output.Result, string, error) {
+		region := regionOf(clients)
+		if region == "ap-south-1" {
+			return nil, "", errors.New("access denied")
+		}
+		result := output.NewResult("Name")
+		result.Add("web-" + region)
+		return result, "", nil
+	}
+
+	err := Run(context.Background(), fake.New().Collection(), []string{"us-east-1", "ap-south-1", "eu-west-1"}, paging.Options{}, query)
+	if err == nil || err.Error() != "1 of 3 regions failed" {
+		t.Errorf("Run() error = %v, want 1 of 3 regions failed", err)
+	}
+	if want := "us-east-1\tweb-us-east-1\neu-west-1\tweb-eu-west-1\n"; buf.String() != want {
+		t.Errorf("Run() printed %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	output.SetFormat(output.FormatTable)
+	if err := Run(context.Background(), fake.New().Collection(), []string{"us-east-1"}, paging.Options{}, query); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "Region") {
+		t.Errorf("merged header = %q, want a leading Region column", strings.SplitN(buf.String(), "\n", 2)[0])
+	}
+}
+
+func TestRunWithoutRegions(t *testing.T) {
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatText)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+
+	clients := fake.New().Collection()
+	query := func(ctx context.Context, got *awsclient.AWSClientCollection) (*output.Result, string, error) {
+		if got != clients {
+			t.Error("query did not get the current clients")
+		}
+		result := output.NewResult("Name")
+		result.Add("web")
+		return result, "", nil
+	}
+	if err := Run(context.Background(), clients, nil, paging.Options{}, query); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if buf.String() != "web\n" {
+		t.Errorf("Run() printed %q, want no Region column", buf.String())
+	}
+
+	err := Run(context.Background(), clients, []string{"us-east-1"}, paging.Options{StartingToken: "abc"}, query)
+	if err == nil || !strings.Contains(err.Error(), "--starting-token") {
+		t.Errorf("Run() error = %v, want --starting-token rejected", err)
+	}
+}
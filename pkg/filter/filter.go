@@ -0,0 +1,203 @@
+// Package filter implements the selection expressions shared by the list and
+// bulk commands. An expression is a list of conditions that must all hold:
+//
+//	key=value          key equals value (* and ? are wildcards)
+//	key=v1,v2          key equals any of the values
+//	key!=value         key equals none of the values (missing keys match)
+//	key~regex          key matches the regular expression
+//	key!~regex         key does not match the regular expression
+//
+// Keys depend on the resource type, e.g. name, id or state; tag:K selects the
+// value of tag K. Each service translates what it can into server-side filters
+// and evaluates the remaining conditions client-side.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Operator is the comparison of a condition.
+type Operator int
+
+const (
+	Equals Operator = iota
+	NotEquals
+	Matches
+	NotMatches
+)
+
+// TagPrefix starts the keys selecting a tag value.
+const TagPrefix = "tag:"
+
+// Condition is a single key/operator/values comparison.
+type Condition struct {
+	Key      string
+	Operator Operator
+	Values   []string
+	patterns []*regexp.Regexp
+}
+
+// Expression is a conjunction of conditions.
+type Expression []Condition
+
+// Fields resolves the value of a key for one resource. ok is false when the
+// resource has no such value, e.g. a missing tag.
+type Fields func(key string) (value string, ok bool)
+
+// Parse parses each argument as a condition.
+func Parse(args ...string) (Expression, error) {
+	expression := Expression{}
+	for _, arg := range args {
+		condition, err := ParseCondition(arg)
+		if err != nil {
+			return nil, err
+		}
+		expression = append(expression, condition)
+	}
+	return expression, nil
+}
+
+// ParseCondition parses a single key=value, key!=value, key~regex or key!~regex condition.
+func ParseCondition(arg string) (Condition, error) {
+	i := strings.IndexAny(arg, "=~")
+	if i <= 0 {
+		return Condition{}, fmt.Errorf("invalid filter %q: expected key=value, key!=value, key~regex or key!~regex", arg)
+	}
+
+	key, value := arg[:i], arg[i+1:]
+	negated := strings.HasSuffix(key, "!")
+	key = strings.TrimSuffix(key, "!")
+	if key == "" {
+		return Condition{}, fmt.Errorf("invalid filter %q: missing key", arg)
+	}
+
+	condition := Condition{Key: key}
+	if arg[i] == '=' {
+		condition.Operator = Equals
+		if negated {
+			condition.Operator = NotEquals
+		}
+		condition.Values = strings.Split(value, ",")
+		for _, v := range condition.Values {
+			condition.patterns = append(condition.patterns, glob(v))
+		}
+		return condition, nil
+	}
+
+	condition.Operator = Matches
+	if negated {
+		condition.Operator = NotMatches
+	}
+	// Regular expressions are not split on commas, which may be part of the pattern
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return Condition{}, fmt.Errorf("invalid filter %q: %w", arg, err)
+	}
+	condition.Values = []string{value}
+	condition.patterns = []*regexp.Regexp{re}
+	return condition, nil
+}
+
+// glob compiles a value with * and ? wildcards into an anchored regular expression.
+func glob(value string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(value)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.MustCompile("^" + quoted + "$")
+}
+
+// Match reports whether a resource whose key resolves to value (ok) satisfies the condition.
+func (c Condition) Match(value string, ok bool) bool {
+	matched := false
+	if ok {
+		for _, pattern := range c.patterns {
+			if pattern.MatchString(value) {
+				matched = true
+				break
+			}
+		}
+	}
+	if c.Operator == NotEquals || c.Operator == NotMatches {
+		return !matched
+	}
+	return matched
+}
+
+// Exact reports whether the condition is a plain equality without wildcards,
+// which services can usually evaluate server-side.
+func (c Condition) Exact() bool {
+	return c.Operator == Equals && !strings.ContainsAny(strings.Join(c.Values, ""), "*?")
+}
+
+// Prefix returns the prefix selected by an equality on a single value whose only
+// wildcard is a trailing *, e.g. name=prod-*.
+func (c Condition) Prefix() (string, bool) {
+	if c.Operator != Equals || len(c.Values) != 1 {
+		return "", false
+	}
+	prefix, found := strings.CutSuffix(c.Values[0], "*")
+	if !found || prefix == "" || strings.ContainsAny(prefix, "*?") {
+		return "", false
+	}
+	return prefix, true
+}
+
+// IsTag reports whether the condition selects a tag value, and returns the tag key.
+func (c Condition) IsTag() (string, bool) {
+	return TagKey(c.Key)
+}
+
+// TagKey returns the tag selected by a tag:K key.
+func TagKey(key string) (string, bool) {
+	return strings.CutPrefix(key, TagPrefix)
+}
+
+func (c Condition) String() string {
+	operator := map[Operator]string{Equals: "=", NotEquals: "!=", Matches: "~", NotMatches: "!~"}[c.Operator]
+	return c.Key + operator + strings.Join(c.Values, ",")
+}
+
+// Match reports whether every condition holds for the resource described by fields.
+func (e Expression) Match(fields Fields) bool {
+	for _, condition := range e {
+		value, ok := fields(condition.Key)
+		if !condition.Match(value, ok) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasTags reports whether evaluating the expression needs the resource tags,
+// so services can skip the extra tag lookups otherwise.
+func (e Expression) HasTags() bool {
+	for _, condition := range e {
+		if _, ok := condition.IsTag(); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckKeys returns an error for the first condition whose key is neither a tag
+// nor one of keys, since it could not be evaluated client-side.
+func (e Expression) CheckKeys(keys ...string) error {
+	for _, condition := range e {
+		if _, ok := condition.IsTag(); ok {
+			continue
+		}
+		known := false
+		for _, key := range keys {
+			if condition.Key == key {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unsupported filter %q (supported keys: %s, tag:<key>)", condition.String(), strings.Join(keys, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    Condition
+		wantErr bool
+	}{
+		{arg: "state=running", want: Condition{Key: "state", Operator: Equals, Values: []string{"running"}}},
+		{arg: "state=running,stopped", want: Condition{Key: "state", Operator: Equals, Values: []string{"running", "stopped"}}},
+		{arg: "tag:Env!=prod", want: Condition{Key: "tag:Env", Operator: NotEquals, Values: []string{"prod"}}},
+		{arg: "name~^web-(a|b),c$", want: Condition{Key: "name", Operator: Matches, Values: []string{"^web-(a|b),c$"}}},
+		{arg: "name!~test", want: Condition{Key: "name", Operator: NotMatches, Values: []string{"test"}}},
+		{arg: "name=", want: Condition{Key: "name", Operator: Equals, Values: []string{""}}},
+		{arg: "running", wantErr: true},
+		{arg: "=running", wantErr: true},
+		{arg: "!=running", wantErr: true},
+		{arg: "name~(", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := ParseCondition(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got.patterns = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCondition() = %+v, want %+v", got, tt.want)
+			}
+			if got.String() != tt.arg {
+				t.Errorf("String() = %q, want %q", got.String(), tt.arg)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	fields := func(values map[string]string) Fields {
+		return func(key string) (string, bool) {
+			value, ok := values[key]
+			return value, ok
+		}
+	}
+	web := fields(map[string]string{"name": "web-1", "state": "running", "tag:Env": "prod"})
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: nil, want: true},
+		{args: []string{"state=running"}, want: true},
+		{args: []string{"state=stopped,running"}, want: true},
+		{args: []string{"state=stopped"}, want: false},
+		{args: []string{"name=web-*"}, want: true},
+		{args: []string{"name=web-?"}, want: true},
+		{args: []string{"name=web"}, want: false},
+		{args: []string{"name=WEB-1"}, want: false},
+		{args: []string{"name=web.1"}, want: false},
+		{args: []string{"name~^web-[0-9]+$"}, want: true},
+		{args: []string{"name!~^web"}, want: false},
+		{args: []string{"tag:Env=prod"}, want: true},
+		{args: []string{"tag:Team=web"}, want: false},
+		{args: []string{"tag:Team!=web"}, want: true},
+		{args: []string{"tag:Team!~web"}, want: true},
+		{args: []string{"state=running", "tag:Env!=prod"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.args), func(t *testing.T) {
+			expression, err := Parse(tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := expression.Match(web); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerSide(t *testing.T) {
+	tests := []struct {
+		arg      string
+		exact    bool
+		prefix   string
+		isPrefix bool
+		tag      string
+		isTag    bool
+	}{
+		{arg: "name=web", exact: true},
+		{arg: "name=web,api", exact: true},
+		{arg: "name=web-*", prefix: "web-", isPrefix: true},
+		{arg: "name=web-*,api-*"},
+		{arg: "name=*-web"},
+		{arg: "name=w?b-*"},
+		{arg: "name=*"},
+		{arg: "name!=web"},
+		{arg: "name~^web"},
+		{arg: "tag:Env=prod", exact: true, tag: "Env", isTag: true},
+		{arg: "tag:Env!=prod", tag: "Env", isTag: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			condition, err := ParseCondition(tt.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := condition.Exact(); got != tt.exact {
+				t.Errorf("Exact() = %v, want %v", got, tt.exact)
+			}
+			if prefix, ok := condition.Prefix(); prefix != tt.prefix || ok != tt.isPrefix {
+				t.Errorf("Prefix() = %q, %v, want %q, %v", prefix, ok, tt.prefix, tt.isPrefix)
+			}
+			if tag, ok := condition.IsTag(); ok != tt.isTag || ok && tag != tt.tag {
+				t.Errorf("IsTag() = %q, %v, want %q, %v", tag, ok, tt.tag, tt.isTag)
+			}
+		})
+	}
+}
+
+func TestCheckKeys(t *testing.T) {
+	expression, err := Parse("name=web", "tag:Env=prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := expression.CheckKeys("name", "state"); err != nil {
+		t.Errorf("CheckKeys() error = %v", err)
+	}
+	if !expression.HasTags() {
+		t.Error("HasTags() = false, want true")
+	}
+	expression, err = Parse("size=large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := expression.CheckKeys("name", "state"); err == nil {
+		t.Error("CheckKeys() error = nil, want an unsupported filter")
+	}
+}
+
+func TestSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "all", args: []string{"--all"}, want: []string{}},
+		{name: "filters and shorthands", args: []string{"-f", "name=web-*", "--state", "running", "-k", "Env", "-v", "prod"}, want: []string{"name=web-*", "state=running", "tag:Env=prod"}},
+		{name: "nothing selected", args: []string{}, wantErr: true},
+		{name: "all with a filter", args: []string{"--all", "--state", "running"}, wantErr: true},
+		{name: "tag key without value", args: []string{"--tag-key", "Env"}, wantErr: true},
+		{name: "invalid filter", args: []string{"-f", "running"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "list"}
+			selector := NewSelector(cmd, "instances")
+			selector.Shorthand(cmd, "state", "s", "State", Is("state"))
+			selector.TagFlags(cmd)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			expression, err := selector.Expression()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := []string{}
+			for _, condition := range expression {
+				got = append(got, condition.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expression() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package filter
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Selector registers the selection flags shared by list and bulk commands:
+// --all, the repeatable --filter and shorthand flags such as --state or
+// --tag-key/--tag-value, each translated into a condition.
+type Selector struct {
+	noun       string
+	all        bool
+	filters    []string
+	shorthands []shorthand
+	pairs      []*pair
+}
+
+type shorthand struct {
+	value     *string
+	condition func(value string) string
+}
+
+type pair struct {
+	keyFlag, valueFlag string
+	prefix             string
+	key, value         string
+}
+
+// NewSelector registers --all and --filter on cmd. noun names the resources in help texts.
+func NewSelector(cmd *cobra.Command, noun string) *Selector {
+	s := &Selector{noun: noun}
+	cmd.Flags().BoolVarP(&s.all, "all", "a", false, fmt.Sprintf("Select all %s", noun))
+	cmd.Flags().StringArrayVarP(&s.filters, "filter", "f", nil, "Filter expression, repeatable: key=v1,v2, key!=value, key~regex, key!~regex or tag:Key=value")
+	return s
+}
+
+// Shorthand registers a flag whose value is turned into a condition by condition,
+// e.g. --state running into state=running.
+func (s *Selector) Shorthand(cmd *cobra.Command, name, short, usage string, condition func(value string) string) {
+	value := new(string)
+	cmd.Flags().StringVarP(value, name, short, "", usage)
+	s.shorthands = append(s.shorthands, shorthand{value: value, condition: condition})
+}
+
+// Is returns a shorthand condition builder for key=value.
+func Is(key string) func(string) string {
+	return func(value string) string {
+		return key + "=" + value
+	}
+}
+
+// Pair registers two flags that must be given together, selecting prefix+key=value.
+func (s *Selector) Pair(cmd *cobra.Command, keyFlag, keyShort, valueFlag, valueShort, prefix, what string) {
+	p := &pair{keyFlag: keyFlag, valueFlag: valueFlag, prefix: prefix}
+	cmd.Flags().StringVarP(&p.key, keyFlag, keyShort, "", fmt.Sprintf("%s key to filter %s", what, s.noun))
+	cmd.Flags().StringVarP(&p.value, valueFlag, valueShort, "", fmt.Sprintf("%s value to filter %s", what, s.noun))
+	s.pairs = append(s.pairs, p)
+}
+
+// TagFlags registers --tag-key and --tag-value, selecting tag:Key=value.
+func (s *Selector) TagFlags(cmd *cobra.Command) {
+	s.Pair(cmd, "tag-key", "k", "tag-value", "v", TagPrefix, "Tag")
+}
+
+// Expression validates the flags and returns the combined expression. --all
+// selects everything and cannot be combined with filters; otherwise at least
+// one filter is required, so bulk commands never act on everything by accident.
+func (s *Selector) Expression() (Expression, error) {
+	args := append([]string{}, s.filters...)
+	for _, sh := range s.shorthands {
+		if *sh.value != "" {
+			args = append(args, sh.condition(*sh.value))
+		}
+	}
+	for _, p := range s.pairs {
+		if p.key == "" && p.value == "" {
+			continue
+		}
+		if p.key == "" || p.value == "" {
+			return nil, fmt.Errorf("--%s and --%s must be specified together", p.keyFlag, p.valueFlag)
+		}
+		args = append(args, p.prefix+p.key+"="+p.value)
+	}
+
+	if s.all {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("the --all flag cannot be combined with other filters")
+		}
+		return Expression{}, nil
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("at least one filter must be specified (or --all to select all %s)", s.noun)
+	}
+	return Parse(args...)
+}
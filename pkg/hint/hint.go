@@ -0,0 +1,179 @@
+// Package hint translates common failures into a one-line explanation and a
+// command of this CLI to run next.
+package hint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+// Hint explains a failure.
+type Hint struct {
+	Explanation string
+	// Suggestion is a command line of this CLI to run next, e.g. to inspect the
+	// resource involved. Placeholders are written <like-this>.
+	Suggestion string
+}
+
+// Command is the command line that failed, from which suggestions are built.
+type Command struct {
+	// Name is the name of the CLI.
+	Name string
+	Args []string
+	// Profile is the shared config profile in use, if any.
+	Profile string
+}
+
+// line returns the command line of name followed by args.
+func (c Command) line(args ...string) string {
+	return commandLine(append([]string{c.Name}, args...))
+}
+
+// commandLine joins words into a command line, quoting the words a shell would split.
+func commandLine(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		if word == "" || strings.ContainsAny(word, " \t\"'") {
+			word = strconv.Quote(word)
+		}
+		quoted[i] = word
+	}
+	return strings.Join(quoted, " ")
+}
+
+// encodedMessagePrefix precedes the encoded details of EC2 authorization failures.
+const encodedMessagePrefix = "Encoded authorization failure message: "
+
+// For returns the hint for err, or nil when the failure is not recognised.
+// stsClient decodes the details of EC2 authorization failures; it may be nil.
+func For(ctx context.Context, stsClient awsclient.STSAPI, err error, command Command) *Hint {
+	var resource string
+	var resourceErr *awsclient.ResourceError
+	if errors.As(err, &resourceErr) {
+		resource = resourceErr.Resource
+	}
+	var code, message string
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code, message = apiErr.ErrorCode(), apiErr.ErrorMessage()
+	}
+
+	switch {
+	case code == "ExpiredToken" || code == "ExpiredTokenException" || expiredSSOSession(err):
+		profile := command.Profile
+		if profile == "" {
+			profile = "<profile>"
+		}
+		return &Hint{
+			Explanation: "The AWS credentials have expired: renew them (e.g. aws sso login) or use another profile.",
+			Suggestion:  command.line(append([]string{"--profile", profile}, withoutFlag(command.Args, "profile")...)...),
+		}
+	case missingRegion(err):
+		return &Hint{
+			Explanation: "No AWS region is configured: pass --region, set AWS_REGION or set the region of the profile.",
+			Suggestion:  command.line(append([]string{"--region", "<region>"}, command.Args...)...),
+		}
+	case code == "UnauthorizedOperation":
+		return &Hint{
+			Explanation: unauthorized(ctx, stsClient, message),
+			Suggestion:  command.line(append([]string{"--role-arn", "<role-arn>"}, withoutFlag(command.Args, "role-arn")...)...),
+		}
+	case code == "BucketNotEmpty":
+		return &Hint{
+			Explanation: fmt.Sprintf("Bucket %s still contains objects, which must be deleted before the bucket.", orPlaceholder(resource, "<bucket>")),
+			Suggestion:  command.line("s3", "listObjects", orPlaceholder(resource, "<bucket>")),
+		}
+	case code == "ResourceInUseException":
+		return &Hint{
+			Explanation: fmt.Sprintf("Table %s is being created, updated or deleted, or already exists: retry once its status is ACTIVE.", orPlaceholder(resource, "<table>")),
+			Suggestion:  command.line("dynamodb", "describe", orPlaceholder(resource, "<table>")),
+		}
+	case code == "InvalidDBInstanceState":
+		return &Hint{
+			Explanation: fmt.Sprintf("DB instance %s is not in a state that allows this operation, e.g. it is stopped, starting or being modified.", orPlaceholder(resource, "<db-instance>")),
+			Suggestion:  command.line("rds", "list"),
+		}
+	}
+	return nil
+}
+
+func orPlaceholder(value, placeholder string) string {
+	if value == "" {
+		return placeholder
+	}
+	return value
+}
+
+// expiredSSOSession reports whether the SSO token of the profile has expired,
+// which the SDK reports before sending the request, without an API error.
+func expiredSSOSession(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "refresh cached SSO token failed") ||
+		strings.Contains(message, "SSO session has expired")
+}
+
+func missingRegion(err error) bool {
+	var regionErr *aws.MissingRegionError
+	return errors.As(err, &regionErr) || strings.Contains(err.Error(), "Missing Region")
+}
+
+// authorizationMessage is the decoded message of an authorization failure.
+type authorizationMessage struct {
+	ExplicitDeny bool `json:"explicitDeny"`
+	Context      struct {
+		Principal struct {
+			ARN string `json:"arn"`
+		} `json:"principal"`
+		Action   string `json:"action"`
+		Resource string `json:"resource"`
+	} `json:"context"`
+}
+
+// unauthorized explains an EC2 authorization failure from its encoded message,
+// which names the denied action and resource once decoded by STS.
+func unauthorized(ctx context.Context, stsClient awsclient.STSAPI, message string) string {
+	const fallback = "You are not allowed to perform this operation: use a role or profile that grants it."
+	_, encoded, ok := strings.Cut(message, encodedMessagePrefix)
+	if !ok || stsClient == nil {
+		return fallback
+	}
+	out, err := stsClient.DecodeAuthorizationMessage(ctx, &sts.DecodeAuthorizationMessageInput{EncodedMessage: aws.String(strings.TrimSpace(encoded))})
+	if err != nil {
+		return fallback + " (decoding the details requires sts:DecodeAuthorizationMessage)"
+	}
+	var decoded authorizationMessage
+	if err := json.Unmarshal([]byte(aws.ToString(out.DecodedMessage)), &decoded); err != nil || decoded.Context.Action == "" {
+		return fallback
+	}
+
+	principal := orPlaceholder(decoded.Context.Principal.ARN, "The caller")
+	explanation := fmt.Sprintf("%s is not allowed to perform %s on %s", principal, decoded.Context.Action, orPlaceholder(decoded.Context.Resource, "the resource"))
+	if decoded.ExplicitDeny {
+		return explanation + ": a policy explicitly denies it."
+	}
+	return explanation + ": no policy allows it."
+}
+
+// withoutFlag removes --name and its value from args.
+func withoutFlag(args []string, name string) []string {
+	kept := []string{}
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--"+name:
+			i++
+		case strings.HasPrefix(args[i], "--"+name+"="):
+		default:
+			kept = append(kept, args[i])
+		}
+	}
+	return kept
+}
@@ -0,0 +1,137 @@
+package hint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+func apiError(code, message, resource string) error {
+	var err error = &smithy.OperationError{ServiceID: "EC2", OperationName: "Call", Err: &smithy.GenericAPIError{Code: code, Message: message}}
+	if resource != "" {
+		err = &awsclient.ResourceError{Resource: resource, Err: err}
+	}
+	return fmt.Errorf("error managing resources: %w", err)
+}
+
+func TestFor(t *testing.T) {
+	decoded := func(message string, err error) *fake.STS {
+		return &fake.STS{DecodeAuthorizationMessageFunc: func(context.Context, *sts.DecodeAuthorizationMessageInput) (*sts.DecodeAuthorizationMessageOutput, error) {
+			return &sts.DecodeAuthorizationMessageOutput{DecodedMessage: aws.String(message)}, err
+		}}
+	}
+	denied := "You are not authorized to perform this operation. Encoded authorization failure message: abc123"
+	tests := []struct {
+		name    string
+		sts     *fake.STS
+		err     error
+		command Command
+		want    *Hint
+	}{
+		{
+			name:    "expired token",
+			err:     apiError("ExpiredToken", "The security token included in the request is expired", ""),
+			command: Command{Name: "icp-aws-cli", Args: []string{"ec2", "list", "--profile=dev", "--all"}, Profile: "dev"},
+			want: &Hint{
+				Explanation: "The AWS credentials have expired: renew them (e.g. aws sso login) or use another profile.",
+				Suggestion:  "icp-aws-cli --profile dev ec2 list --all",
+			},
+		},
+		{
+			name:    "expired SSO session",
+			err:     errors.New("get identity: get credentials: failed to refresh cached credentials, refresh cached SSO token failed"),
+			command: Command{Name: "icp-aws-cli", Args: []string{"s3", "listBuckets"}},
+			want: &Hint{
+				Explanation: "The AWS credentials have expired: renew them (e.g. aws sso login) or use another profile.",
+				Suggestion:  "icp-aws-cli --profile <profile> s3 listBuckets",
+			},
+		},
+		{
+			name:    "missing region",
+			err:     fmt.Errorf("error describing instances: %w", &aws.MissingRegionError{}),
+			command: Command{Name: "icp-aws-cli", Args: []string{"ec2", "list", "-f", "name=web server"}},
+			want: &Hint{
+				Explanation: "No AWS region is configured: pass --region, set AWS_REGION or set the region of the profile.",
+				Suggestion:  `icp-aws-cli --region <region> ec2 list -f "name=web server"`,
+			},
+		},
+		{
+			name:    "unauthorized with decoded details",
+			sts:     decoded(`{"allowed":false,"explicitDeny":true,"context":{"principal":{"arn":"arn:aws:sts::1:assumed-role/dev/me"},"action":"ec2:StopInstances","resource":"arn:aws:ec2:us-east-1:1:instance/i-1"}}`, nil),
+			err:     apiError("UnauthorizedOperation", denied, ""),
+			command: Command{Name: "icp-aws-cli", Args: []string{"ec2", "stop", "--role-arn", "arn:old", "-i", "i-1"}},
+			want: &Hint{
+				Explanation: "arn:aws:sts::1:assumed-role/dev/me is not allowed to perform ec2:StopInstances on arn:aws:ec2:us-east-1:1:instance/i-1: a policy explicitly denies it.",
+				Suggestion:  "icp-aws-cli --role-arn <role-arn> ec2 stop -i i-1",
+			},
+		},
+		{
+			name:    "unauthorized without permission to decode",
+			sts:     decoded("", apiError("AccessDenied", "not allowed", "")),
+			err:     apiError("UnauthorizedOperation", denied, ""),
+			command: Command{Name: "icp-aws-cli", Args: []string{"ec2", "list", "--all"}},
+			want: &Hint{
+				Explanation: "You are not allowed to perform this operation: use a role or profile that grants it. (decoding the details requires sts:DecodeAuthorizationMessage)",
+				Suggestion:  "icp-aws-cli --role-arn <role-arn> ec2 list --all",
+			},
+		},
+		{
+			name:    "unauthorized without an encoded message",
+			err:     apiError("UnauthorizedOperation", "You are not authorized to perform this operation.", ""),
+			command: Command{Name: "icp-aws-cli", Args: []string{"ec2", "list", "--all"}},
+			want: &Hint{
+				Explanation: "You are not allowed to perform this operation: use a role or profile that grants it.",
+				Suggestion:  "icp-aws-cli --role-arn <role-arn> ec2 list --all",
+			},
+		},
+		{
+			name:    "bucket not empty",
+			err:     apiError("BucketNotEmpty", "The bucket you tried to delete is not empty", "web-assets"),
+			command: Command{Name: "icp-aws-cli", Args: []string{"s3", "deleteBucket", "web-assets"}},
+			want: &Hint{
+				Explanation: "Bucket web-assets still contains objects, which must be deleted before the bucket.",
+				Suggestion:  "icp-aws-cli s3 listObjects web-assets",
+			},
+		},
+		{
+			name:    "table in use without resource",
+			err:     apiError("ResourceInUseException", "Table is being created", ""),
+			command: Command{Name: "icp-aws-cli"},
+			want: &Hint{
+				Explanation: "Table <table> is being created, updated or deleted, or already exists: retry once its status is ACTIVE.",
+				Suggestion:  "icp-aws-cli dynamodb describe <table>",
+			},
+		},
+		{
+			name:    "unrecognised",
+			err:     apiError("NoSuchBucket", "The specified bucket does not exist", "web-assets"),
+			command: Command{Name: "icp-aws-cli"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stsClient awsclient.STSAPI
+			if tt.sts != nil {
+				stsClient = tt.sts
+			}
+			got := For(context.Background(), stsClient, tt.err, tt.command)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("For() = %+v, want %+v", got, tt.want)
+			}
+			if tt.sts != nil {
+				calls := tt.sts.Calls()
+				if len(calls) != 1 || aws.ToString(calls[0].Input.(*sts.DecodeAuthorizationMessageInput).EncodedMessage) != "abc123" {
+					t.Errorf("calls = %+v, want the encoded message decoded once", calls)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+)
+
+// collectGroups reports groups being deleted with their status; other groups
+// have none.
+func collectGroups(ctx context.Context, clients *awsclient.AWSClientCollection) ([]Record, error) {
+	records := []Record{}
+	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(clients.AutoScaling, &autoscaling.DescribeAutoScalingGroupsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not list AutoScaling groups: %w", err)
+		}
+		for _, group := range page.AutoScalingGroups {
+			tags := map[string]string{}
+			for _, tag := range group.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			records = append(records, Record{
+				Type:  TypeAutoScalingGroup,
+				ID:    aws.ToString(group.AutoScalingGroupName),
+				Name:  nameTag(tags),
+				State: aws.ToString(group.Status),
+				Capacity: fmt.Sprintf("min %d / desired %d / max %d",
+					aws.ToInt32(group.MinSize), aws.ToInt32(group.DesiredCapacity), aws.ToInt32(group.MaxSize)),
+				Tags:    tags,
+				Created: timePtr(group.CreatedTime),
+			})
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,90 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+// collectAlarms lists metric and composite alarms. CloudWatch does not expose
+// their creation time.
+func collectAlarms(ctx context.Context, clients *awsclient.AWSClientCollection) ([]Record, error) {
+	records := []Record{}
+	paginator := cloudwatch.NewDescribeAlarmsPaginator(clients.CloudWatch, &cloudwatch.DescribeAlarmsInput{
+		AlarmTypes: []types.AlarmType{types.AlarmTypeMetricAlarm, types.AlarmTypeCompositeAlarm},
+	})
+	add := func(name, arn *string, state types.StateValue) {
+		var tags map[string]string
+		out, err := clients.CloudWatch.ListTagsForResource(ctx, &cloudwatch.ListTagsForResourceInput{ResourceARN: arn})
+		if err != nil {
+			tags = untagged(clients, fmt.Errorf("could not get tags of alarm %s: %w", aws.ToString(name), err))
+		} else {
+			tags = map[string]string{}
+			for _, tag := range out.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+		}
+		records = append(records, Record{
+			Type:           TypeAlarm,
+			ID:             aws.ToString(name),
+			Name:           nameTag(tags),
+			State:          string(state),
+			Tags:           tags,
+			TagsUnreadable: err != nil,
+		})
+	}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not describe alarms: %w", err)
+		}
+		for _, alarm := range page.MetricAlarms {
+			add(alarm.AlarmName, alarm.AlarmArn, alarm.StateValue)
+		}
+		for _, alarm := range page.CompositeAlarms {
+			add(alarm.AlarmName, alarm.AlarmArn, alarm.StateValue)
+		}
+	}
+	return records, nil
+}
+
+func collectLogGroups(ctx context.Context, clients *awsclient.AWSClientCollection) ([]Record, error) {
+	records := []Record{}
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(clients.CloudWatchLogs, &cloudwatchlogs.DescribeLogGroupsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not describe log groups: %w", err)
+		}
+		for _, group := range page.LogGroups {
+			name := aws.ToString(group.LogGroupName)
+			var tags map[string]string
+			out, err := clients.CloudWatchLogs.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{ResourceArn: tagging.LogGroupARN(group)})
+			if err != nil {
+				tags = untagged(clients, fmt.Errorf("could not get tags of log group %s: %w", name, err))
+			} else {
+				tags = out.Tags
+			}
+			record := Record{
+				Type:           TypeLogGroup,
+				ID:             name,
+				Name:           nameTag(tags),
+				Tags:           tags,
+				TagsUnreadable: err != nil,
+			}
+			if group.CreationTime != nil {
+				created := time.UnixMilli(*group.CreationTime).UTC()
+				record.Created = &created
+			}
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,59 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func collectTables(ctx context.Context, clients *awsclient.AWSClientCollection) ([]Record, error) {
+	tagger := tagging.Tables(clients.DynamoDB)
+	records := []Record{}
+	paginator := dynamodb.NewListTablesPaginator(clients.DynamoDB, &dynamodb.ListTablesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not list tables: %w", err)
+		}
+		for _, name := range page.TableNames {
+			out, err := clients.DynamoDB.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
+			if err != nil {
+				return nil, fmt.Errorf("could not describe table %s: %w", name, err)
+			}
+			tags, err := tagger.Get(ctx, name)
+			unreadable := err != nil
+			if unreadable {
+				tags = untagged(clients, err)
+			}
+			records = append(records, Record{
+				Type:           TypeDynamoDBTable,
+				ID:             name,
+				Name:           nameTag(tags),
+				State:          string(out.Table.TableStatus),
+				Capacity:       tableCapacity(out.Table),
+				Tags:           tags,
+				Created:        timePtr(out.Table.CreationDateTime),
+				TagsUnreadable: unreadable,
+			})
+		}
+	}
+	return records, nil
+}
+
+// tableCapacity describes the billing mode and provisioned throughput. Tables
+// created before on-demand billing existed have no billing summary.
+func tableCapacity(table *types.TableDescription) string {
+	if table.BillingModeSummary != nil && table.BillingModeSummary.BillingMode == types.BillingModePayPerRequest {
+		return "on-demand"
+	}
+	if table.ProvisionedThroughput == nil {
+		return ""
+	}
+	return fmt.Sprintf("read %d / write %d",
+		aws.ToInt64(table.ProvisionedThroughput.ReadCapacityUnits), aws.ToInt64(table.ProvisionedThroughput.WriteCapacityUnits))
+}
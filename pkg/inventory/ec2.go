@@ -0,0 +1,42 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+func collectInstances(ctx context.Context, clients *awsclient.AWSClientCollection) ([]Record, error) {
+	records := []Record{}
+	paginator := ec2.NewDescribeInstancesPaginator(clients.EC2, &ec2.DescribeInstancesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing instances: %w", err)
+		}
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				tags := map[string]string{}
+				for _, tag := range instance.Tags {
+					tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+				}
+				record := Record{
+					Type:    TypeEC2Instance,
+					ID:      aws.ToString(instance.InstanceId),
+					Name:    nameTag(tags),
+					Size:    string(instance.InstanceType),
+					Tags:    tags,
+					Created: timePtr(instance.LaunchTime),
+				}
+				if instance.State != nil {
+					record.State = string(instance.State.Name)
+				}
+				records = append(records, record)
+			}
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,260 @@
+// Package inventory enumerates the resources of several services and normalises
+// them into a common record.
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resource types, in the order they are listed.
+const (
+	TypeEC2Instance      = "ec2:instance"
+	TypeS3Bucket         = "s3:bucket"
+	TypeDynamoDBTable    = "dynamodb:table"
+	TypeRDSInstance      = "rds:instance"
+	TypeRDSSnapshot      = "rds:snapshot"
+	TypeAutoScalingGroup = "autoscaling:group"
+	TypeAlarm            = "cloudwatch:alarm"
+	TypeLogGroup         = "logs:log-group"
+)
+
+// Record is a resource of any type.
+type Record struct {
+	Type string `json:"type"`
+	// ID identifies the resource in the API of its service, e.g. an instance ID
+	// or a table name.
+	ID string `json:"id"`
+	// Name is the Name tag when set, the ID otherwise.
+	Name   string `json:"name"`
+	Region string `json:"region"`
+	State  string `json:"state,omitempty"`
+	// Size is the configured size, e.g. the instance type or allocated storage.
+	Size string `json:"size,omitempty"`
+	// Capacity is the configured capacity, e.g. of a table or an AutoScaling group.
+	Capacity string            `json:"capacity,omitempty"`
+	Tags     map[string]string `json:"tags"`
+	// TagsUnreadable is set when the tags could not be read, so that their
+	// absence is not taken for a change.
+	TagsUnreadable bool       `json:"tags_unreadable,omitempty"`
+	Created        *time.Time `json:"created,omitempty"`
+}
+
+// collector lists the resources of one type in the region of clients.
+type collector func(ctx context.Context, clients *awsclient.AWSClientCollection) ([]Record, error)
+
+var collectors = map[string]collector{
+	TypeEC2Instance:      collectInstances,
+	TypeS3Bucket:         collectBuckets,
+	TypeDynamoDBTable:    collectTables,
+	TypeRDSInstance:      collectDBInstances,
+	TypeRDSSnapshot:      collectDBSnapshots,
+	TypeAutoScalingGroup: collectGroups,
+	TypeAlarm:            collectAlarms,
+	TypeLogGroup:         collectLogGroups,
+}
+
+// Types lists the supported resource types.
+var Types = []string{
+	TypeEC2Instance, TypeS3Bucket, TypeDynamoDBTable, TypeRDSInstance,
+	TypeRDSSnapshot, TypeAutoScalingGroup, TypeAlarm, TypeLogGroup,
+}
+
+// ParseTypes validates a list of types; an empty list selects every type.
+func ParseTypes(values []string) ([]string, error) {
+	if len(values) == 0 {
+		return Types, nil
+	}
+	types := []string{}
+	for _, value := range values {
+		if _, ok := collectors[value]; !ok {
+			return nil, fmt.Errorf("unknown resource type %q (valid values: %s)", value, strings.Join(Types, ", "))
+		}
+		types = append(types, value)
+	}
+	return types, nil
+}
+
+// Collect lists the resources of types in the region of clients, one type at a
+// time concurrently. A type that cannot be listed, e.g. for lack of permission,
+// does not hide the others: its error is returned along with the records. A
+// resource whose tags cannot be read is listed without them.
+func Collect(ctx context.Context, clients *awsclient.AWSClientCollection, types []string) ([]Record, []error) {
+	results := make([][]Record, len(types))
+	errs := make([]error, len(types))
+	var wg sync.WaitGroup
+	for i, resourceType := range types {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = collectors[resourceType](ctx, clients)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("%s: %w", resourceType, errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	records := []Record{}
+	failures := []error{}
+	for i := range types {
+		if errs[i] != nil {
+			failures = append(failures, errs[i])
+			continue
+		}
+		for _, record := range results[i] {
+			record.Region = clients.Region
+			if record.Name == "" {
+				record.Name = record.ID
+			}
+			if record.Tags == nil {
+				record.Tags = map[string]string{}
+			}
+			records = append(records, record)
+		}
+	}
+	return records, failures
+}
+
+// Run collects types in the region of clients or, when regions are given, in
+// each of them concurrently. Failures are reported on stderr without hiding the
+// other resources, which are returned sorted along with an error counting them.
+func Run(ctx context.Context, clients *awsclient.AWSClientCollection, regions []string, types []string) ([]Record, error) {
+	targets := []*awsclient.AWSClientCollection{clients}
+	if len(regions) > 0 {
+		resolved, err := fanout.ResolveRegions(ctx, clients, regions)
+		if err != nil {
+			return nil, err
+		}
+		targets = []*awsclient.AWSClientCollection{}
+		for _, region := range resolved {
+			targets = append(targets, clients.ForRegion(region))
+		}
+	}
+
+	results := make([][]Record, len(targets))
+	errs := make([][]error, len(targets))
+	sem := make(chan struct{}, fanout.Concurrency)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = Collect(ctx, target, types)
+		}()
+	}
+	wg.Wait()
+
+	records := []Record{}
+	failed := 0
+	for i, target := range targets {
+		for _, err := range errs[i] {
+			failed++
+			fmt.Fprintf(os.Stderr, "Error in region %s: %v\n", target.Region, err)
+		}
+		records = append(records, results[i]...)
+	}
+	Sort(records)
+	if failed > 0 {
+		return records, fmt.Errorf("%d of %d resource listings failed", failed, len(targets)*len(types))
+	}
+	return records, nil
+}
+
+// Sort orders records by type, region and ID.
+func Sort(records []Record) {
+	order := map[string]int{}
+	for i, resourceType := range Types {
+		order[resourceType] = i
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Type != b.Type {
+			return order[a.Type] < order[b.Type]
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.ID < b.ID
+	})
+}
+
+// Result returns one row per resource.
+func Result(records []Record) *output.Result {
+	result := output.NewResult("Type", "ID", "Name", "Region", "State", "Size", "Capacity", "Tags", "Created")
+	for _, record := range records {
+		var created interface{}
+		if record.Created != nil {
+			created = *record.Created
+		}
+		result.Add(record.Type, record.ID, record.Name, record.Region, record.State, record.Size, record.Capacity, record.Tags, created)
+	}
+	return result
+}
+
+// Summary returns the number of resources per type and region, with the count
+// of each state.
+func Summary(records []Record) *output.Result {
+	type group struct {
+		resourceType, region string
+		count                int
+		states               map[string]int
+	}
+	groups := []*group{}
+	index := map[string]*group{}
+	for _, record := range records {
+		key := record.Type + "/" + record.Region
+		g, ok := index[key]
+		if !ok {
+			g = &group{resourceType: record.Type, region: record.Region, states: map[string]int{}}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.count++
+		if record.State != "" {
+			g.states[record.State]++
+		}
+	}
+
+	result := output.NewResult("Type", "Region", "Count", "States")
+	for _, g := range groups {
+		states := []string{}
+		for state, count := range g.states {
+			states = append(states, fmt.Sprintf("%s=%d", state, count))
+		}
+		sort.Strings(states)
+		result.Add(g.resourceType, g.region, g.count, strings.Join(states, ","))
+	}
+	return result
+}
+
+// untagged reports a resource whose tags could not be read, e.g. for lack of
+// permission, and returns the empty tags it is listed with, so that it does not
+// fail the listing of its whole type.
+func untagged(clients *awsclient.AWSClientCollection, err error) map[string]string {
+	fmt.Fprintf(os.Stderr, "Warning: region %s: %v; listed without tags\n", clients.Region, err)
+	return map[string]string{}
+}
+
+// nameTag returns the value of the Name tag, if any.
+func nameTag(tags map[string]string) string {
+	return tags["Name"]
+}
+
+// timePtr returns nil for a missing or zero timestamp.
+func timePtr(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t
+}
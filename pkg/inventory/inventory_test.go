@@ -0,0 +1,61 @@
+package inventory
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+var accessDenied = &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"}
+
+func TestCollectUnreadableTags(t *testing.T) {
+	clients := fake.New()
+	clients.S3.ListBucketsFunc = func(context.Context, *s3.ListBucketsInput) (*s3.ListBucketsOutput, error) {
+		return &s3.ListBucketsOutput{Buckets: []s3types.Bucket{{Name: aws.String("locked")}, {Name: aws.String("web-assets")}}}, nil
+	}
+	clients.S3.GetBucketTaggingFunc = func(_ context.Context, in *s3.GetBucketTaggingInput) (*s3.GetBucketTaggingOutput, error) {
+		if aws.ToString(in.Bucket) == "locked" {
+			return nil, accessDenied
+		}
+		return &s3.GetBucketTaggingOutput{TagSet: []s3types.Tag{{Key: aws.String("Name"), Value: aws.String("Web assets")}}}, nil
+	}
+	clients.CloudWatch.DescribeAlarmsFunc = func(context.Context, *cloudwatch.DescribeAlarmsInput) (*cloudwatch.DescribeAlarmsOutput, error) {
+		return &cloudwatch.DescribeAlarmsOutput{MetricAlarms: []cwtypes.MetricAlarm{
+			{AlarmName: aws.String("web-5xx"), AlarmArn: aws.String("arn:alarm:web-5xx"), StateValue: cwtypes.StateValueOk},
+		}}, nil
+	}
+	clients.CloudWatch.ListTagsForResourceFunc = func(context.Context, *cloudwatch.ListTagsForResourceInput) (*cloudwatch.ListTagsForResourceOutput, error) {
+		return nil, accessDenied
+	}
+	collection := clients.Collection()
+	collection.Region = "us-east-1"
+
+	records, errs := Collect(context.Background(), collection, []string{TypeS3Bucket, TypeAlarm})
+	if len(errs) > 0 {
+		t.Fatalf("Collect() errors = %v", errs)
+	}
+	want := []Record{
+		{Type: TypeS3Bucket, ID: "locked", Name: "locked", Region: "us-east-1", Tags: map[string]string{}, TagsUnreadable: true},
+		{Type: TypeS3Bucket, ID: "web-assets", Name: "Web assets", Region: "us-east-1", Tags: map[string]string{"Name": "Web assets"}},
+		{Type: TypeAlarm, ID: "web-5xx", Name: "web-5xx", Region: "us-east-1", State: "OK", Tags: map[string]string{}, TagsUnreadable: true},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("Collect() = %+v, want %+v", records, want)
+	}
+}
+
+func TestDiffUnreadableTags(t *testing.T) {
+	old := []Record{{Type: TypeS3Bucket, ID: "web-assets", Region: "us-east-1", Tags: map[string]string{"Team": "web"}}}
+	current := []Record{{Type: TypeS3Bucket, ID: "web-assets", Region: "us-east-1", Tags: map[string]string{}, TagsUnreadable: true}}
+	if differences := Diff(old, current); len(differences) != 0 {
+		t.Errorf("Diff() = %+v, want no difference", differences)
+	}
+}
@@ -0,0 +1,67 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+func rdsTags(tagList []types.Tag) map[string]string {
+	tags := map[string]string{}
+	for _, tag := range tagList {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags
+}
+
+func collectDBInstances(ctx context.Context, clients *awsclient.AWSClientCollection) ([]Record, error) {
+	records := []Record{}
+	paginator := rds.NewDescribeDBInstancesPaginator(clients.RDS, &rds.DescribeDBInstancesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not describe DB instances: %w", err)
+		}
+		for _, instance := range page.DBInstances {
+			tags := rdsTags(instance.TagList)
+			records = append(records, Record{
+				Type:    TypeRDSInstance,
+				ID:      aws.ToString(instance.DBInstanceIdentifier),
+				Name:    nameTag(tags),
+				State:   aws.ToString(instance.DBInstanceStatus),
+				Size:    fmt.Sprintf("%s %d GiB", aws.ToString(instance.DBInstanceClass), aws.ToInt32(instance.AllocatedStorage)),
+				Tags:    tags,
+				Created: timePtr(instance.InstanceCreateTime),
+			})
+		}
+	}
+	return records, nil
+}
+
+func collectDBSnapshots(ctx context.Context, clients *awsclient.AWSClientCollection) ([]Record, error) {
+	records := []Record{}
+	paginator := rds.NewDescribeDBSnapshotsPaginator(clients.RDS, &rds.DescribeDBSnapshotsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not describe DB snapshots: %w", err)
+		}
+		for _, snapshot := range page.DBSnapshots {
+			tags := rdsTags(snapshot.TagList)
+			records = append(records, Record{
+				Type:    TypeRDSSnapshot,
+				ID:      aws.ToString(snapshot.DBSnapshotIdentifier),
+				Name:    nameTag(tags),
+				State:   aws.ToString(snapshot.Status),
+				Size:    fmt.Sprintf("%d GiB", aws.ToInt32(snapshot.AllocatedStorage)),
+				Tags:    tags,
+				Created: timePtr(snapshot.SnapshotCreateTime),
+			})
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,42 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// collectBuckets only lists the buckets located in the region of clients, so
+// that a multi-region inventory lists every bucket once.
+func collectBuckets(ctx context.Context, clients *awsclient.AWSClientCollection) ([]Record, error) {
+	tagger := tagging.Buckets(clients.S3)
+	records := []Record{}
+	paginator := s3.NewListBucketsPaginator(clients.S3, &s3.ListBucketsInput{BucketRegion: aws.String(clients.Region)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not list buckets: %w", err)
+		}
+		for _, bucket := range page.Buckets {
+			name := aws.ToString(bucket.Name)
+			tags, err := tagger.Get(ctx, name)
+			unreadable := err != nil
+			if unreadable {
+				tags = untagged(clients, err)
+			}
+			records = append(records, Record{
+				Type:           TypeS3Bucket,
+				ID:             name,
+				Name:           nameTag(tags),
+				Tags:           tags,
+				Created:        timePtr(bucket.CreationDate),
+				TagsUnreadable: unreadable,
+			})
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,240 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/fanout"
+	"icp-aws-cli/pkg/output"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot file format.
+const SnapshotVersion = 1
+
+// Snapshot is an inventory saved to compare it later with another one. Types
+// and Regions record what was collected, so that a later collection covers the
+// same scope.
+type Snapshot struct {
+	Version   int       `json:"version"`
+	Exported  time.Time `json:"exported"`
+	Types     []string  `json:"types"`
+	Regions   []string  `json:"regions"`
+	Resources []Record  `json:"resources"`
+}
+
+// Take collects a snapshot of types in regions, or in the region of clients
+// when regions is empty. It fails if any resource listing fails, as a partial
+// snapshot would show the missing resources as removed.
+func Take(ctx context.Context, clients *awsclient.AWSClientCollection, regions, types []string) (*Snapshot, error) {
+	if len(regions) == 0 {
+		regions = []string{clients.Region}
+	}
+	regions, err := fanout.ResolveRegions(ctx, clients, regions)
+	if err != nil {
+		return nil, err
+	}
+	records, err := Run(ctx, clients, regions, types)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{
+		Version:   SnapshotVersion,
+		Exported:  time.Now().UTC(),
+		Types:     types,
+		Regions:   regions,
+		Resources: records,
+	}, nil
+}
+
+// Write saves the snapshot as indented JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(s)
+}
+
+// ReadSnapshot loads a snapshot written by Write.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read snapshot: %w", err)
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d in %s", snapshot.Version, path)
+	}
+	return snapshot, nil
+}
+
+// Kinds of differences.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Difference is a resource present in only one inventory, or a field of a
+// resource whose value changed between them.
+type Difference struct {
+	Change string
+	Type   string
+	ID     string
+	Region string
+	// Field is state, size, capacity or tag:<key> for a changed resource.
+	Field string
+	Old   string
+	New   string
+}
+
+func recordKey(record Record) string {
+	return record.Type + "|" + record.Region + "|" + record.ID
+}
+
+// Compare diffs the resources of two snapshots within the types and regions
+// covered by both, which it returns along with the differences.
+func Compare(old, current *Snapshot) (differences []Difference, types, regions []string) {
+	types = intersect(old.Types, current.Types)
+	regions = intersect(old.Regions, current.Regions)
+	return Diff(filter(old.Resources, types, regions), filter(current.Resources, types, regions)), types, regions
+}
+
+func intersect(a, b []string) []string {
+	common := []string{}
+	for _, value := range a {
+		for _, other := range b {
+			if value == other {
+				common = append(common, value)
+				break
+			}
+		}
+	}
+	return common
+}
+
+func filter(records []Record, types, regions []string) []Record {
+	included := map[string]bool{}
+	for _, resourceType := range types {
+		for _, region := range regions {
+			included[resourceType+"|"+region] = true
+		}
+	}
+	filtered := []Record{}
+	for _, record := range records {
+		if included[record.Type+"|"+record.Region] {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered
+}
+
+// Diff compares two inventories, matching resources by type, region and ID.
+func Diff(old, current []Record) []Difference {
+	oldRecords := map[string]Record{}
+	for _, record := range old {
+		oldRecords[recordKey(record)] = record
+	}
+	currentRecords := map[string]Record{}
+	for _, record := range current {
+		currentRecords[recordKey(record)] = record
+	}
+
+	differences := []Difference{}
+	all := append(append([]Record{}, old...), current...)
+	Sort(all)
+	seen := map[string]bool{}
+	for _, record := range all {
+		key := recordKey(record)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		before, inOld := oldRecords[key]
+		after, inNew := currentRecords[key]
+		switch {
+		case !inNew:
+			differences = append(differences, Difference{Change: Removed, Type: record.Type, ID: record.ID, Region: record.Region, Old: record.State})
+		case !inOld:
+			differences = append(differences, Difference{Change: Added, Type: record.Type, ID: record.ID, Region: record.Region, New: record.State})
+		default:
+			differences = append(differences, changes(before, after)...)
+		}
+	}
+	return differences
+}
+
+// changes returns a difference per field that changed between before and after.
+func changes(before, after Record) []Difference {
+	differences := []Difference{}
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			differences = append(differences, Difference{
+				Change: Changed, Type: after.Type, ID: after.ID, Region: after.Region,
+				Field: field, Old: oldValue, New: newValue,
+			})
+		}
+	}
+	add("state", before.State, after.State)
+	add("size", before.Size, after.Size)
+	add("capacity", before.Capacity, after.Capacity)
+	if before.TagsUnreadable || after.TagsUnreadable {
+		return differences
+	}
+
+	keys := []string{}
+	for key := range before.Tags {
+		keys = append(keys, key)
+	}
+	for key := range after.Tags {
+		if _, ok := before.Tags[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		oldValue, inBefore := before.Tags[key]
+		newValue, inAfter := after.Tags[key]
+		if inBefore != inAfter && oldValue == newValue {
+			// A tag with an empty value was added or removed
+			differences = append(differences, Difference{
+				Change: Changed, Type: after.Type, ID: after.ID, Region: after.Region,
+				Field: "tag:" + key,
+			})
+			continue
+		}
+		add("tag:"+key, oldValue, newValue)
+	}
+	return differences
+}
+
+// DiffResult returns one row per difference.
+func DiffResult(differences []Difference) *output.Result {
+	result := output.NewResult("Change", "Type", "ID", "Region", "Field", "Old", "New")
+	for _, d := range differences {
+		result.Add(d.Change, d.Type, d.ID, d.Region, d.Field, d.Old, d.New)
+	}
+	return result
+}
+
+// DiffSummary counts the added, removed and changed resources.
+func DiffSummary(differences []Difference) string {
+	counts := map[string]int{}
+	changed := map[string]bool{}
+	for _, d := range differences {
+		if d.Change == Changed {
+			changed[d.Type+"|"+d.Region+"|"+d.ID] = true
+			continue
+		}
+		counts[d.Change]++
+	}
+	return fmt.Sprintf("%d added, %d removed, %d changed", counts[Added], counts[Removed], len(changed))
+}
@@ -0,0 +1,132 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	web := Record{Type: TypeEC2Instance, ID: "i-1", Region: "us-east-1", State: "running", Size: "t3.micro", Tags: map[string]string{"Team": "web", "Backup": ""}}
+	tests := []struct {
+		name    string
+		old     []Record
+		current []Record
+		want    []Difference
+	}{
+		{name: "unchanged", old: []Record{web}, current: []Record{web}, want: []Difference{}},
+		{
+			name:    "added and removed",
+			old:     []Record{web},
+			current: []Record{{Type: TypeEC2Instance, ID: "i-2", Region: "us-east-1", State: "pending"}},
+			want: []Difference{
+				{Change: Removed, Type: TypeEC2Instance, ID: "i-1", Region: "us-east-1", Old: "running"},
+				{Change: Added, Type: TypeEC2Instance, ID: "i-2", Region: "us-east-1", New: "pending"},
+			},
+		},
+		{
+			name:    "same ID in another region",
+			old:     []Record{web},
+			current: []Record{web, {Type: TypeEC2Instance, ID: "i-1", Region: "eu-west-1"}},
+			want:    []Difference{{Change: Added, Type: TypeEC2Instance, ID: "i-1", Region: "eu-west-1"}},
+		},
+		{
+			name: "fields and tags changed",
+			old:  []Record{web},
+			current: []Record{{Type: TypeEC2Instance, ID: "i-1", Region: "us-east-1", State: "stopped", Size: "t3.large",
+				Tags: map[string]string{"Team": "api", "Env": "prod"}}},
+			want: []Difference{
+				{Change: Changed, Type: TypeEC2Instance, ID: "i-1", Region: "us-east-1", Field: "state", Old: "running", New: "stopped"},
+				{Change: Changed, Type: TypeEC2Instance, ID: "i-1", Region: "us-east-1", Field: "size", Old: "t3.micro", New: "t3.large"},
+				{Change: Changed, Type: TypeEC2Instance, ID: "i-1", Region: "us-east-1", Field: "tag:Backup"},
+				{Change: Changed, Type: TypeEC2Instance, ID: "i-1", Region: "us-east-1", Field: "tag:Env", New: "prod"},
+				{Change: Changed, Type: TypeEC2Instance, ID: "i-1", Region: "us-east-1", Field: "tag:Team", Old: "web", New: "api"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.old, tt.current)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffSummary(t *testing.T) {
+	differences := []Difference{
+		{Change: Added, Type: TypeS3Bucket, ID: "a"},
+		{Change: Removed, Type: TypeS3Bucket, ID: "b"},
+		{Change: Changed, Type: TypeS3Bucket, ID: "c", Field: "tag:Team"},
+		{Change: Changed, Type: TypeS3Bucket, ID: "c", Field: "tag:Env"},
+		{Change: Changed, Type: TypeDynamoDBTable, ID: "c", Field: "state"},
+	}
+	if got, want := DiffSummary(differences), "1 added, 1 removed, 2 changed"; got != want {
+		t.Errorf("DiffSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	old := &Snapshot{
+		Types:   []string{TypeEC2Instance, TypeS3Bucket},
+		Regions: []string{"us-east-1", "eu-west-1"},
+		Resources: []Record{
+			{Type: TypeEC2Instance, ID: "i-1", Region: "eu-west-1"},
+			{Type: TypeS3Bucket, ID: "logs", Region: "us-east-1"},
+		},
+	}
+	current := &Snapshot{
+		Types:   []string{TypeEC2Instance},
+		Regions: []string{"us-east-1"},
+		Resources: []Record{
+			{Type: TypeEC2Instance, ID: "i-2", Region: "us-east-1"},
+		},
+	}
+	// Resources outside the scope of either snapshot are not reported missing
+	differences, types, regions := Compare(old, current)
+	want := []Difference{{Change: Added, Type: TypeEC2Instance, ID: "i-2", Region: "us-east-1"}}
+	if !reflect.DeepEqual(differences, want) {
+		t.Errorf("Compare() = %+v, want %+v", differences, want)
+	}
+	if !reflect.DeepEqual(types, []string{TypeEC2Instance}) || !reflect.DeepEqual(regions, []string{"us-east-1"}) {
+		t.Errorf("Compare() scope = %v, %v", types, regions)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	snapshot := &Snapshot{
+		Version:   SnapshotVersion,
+		Exported:  created,
+		Types:     []string{TypeS3Bucket},
+		Regions:   []string{"us-east-1"},
+		Resources: []Record{{Type: TypeS3Bucket, ID: "logs", Name: "logs", Region: "us-east-1", Tags: map[string]string{"Team": "ops"}, Created: &created}},
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshot.Write(file); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	read, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error = %v", err)
+	}
+	if !reflect.DeepEqual(read, snapshot) {
+		t.Errorf("ReadSnapshot() = %+v, want %+v", read, snapshot)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"version": 2}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadSnapshot(path); err == nil {
+		t.Error("ReadSnapshot() error = nil, want an unsupported version")
+	}
+}
@@ -0,0 +1,364 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies how command results are rendered.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+	FormatText  Format = "text"
+	FormatCSV   Format = "csv"
+)
+
+// Formats lists the values accepted by the --output flag.
+var Formats = []Format{FormatTable, FormatJSON, FormatYAML, FormatText, FormatCSV}
+
+// FormatResult serializes the Result itself, columns included, so another
+// process can merge it with DecodeResult. It is accepted but not advertised.
+const FormatResult Format = "result"
+
+const timeLayout = "2006-01-02 15:04:05"
+
+var current = FormatTable
+
+var writer io.Writer = os.Stdout
+
+// ParseFormat validates an --output value.
+func ParseFormat(value string) (Format, error) {
+	for _, format := range append(Formats, FormatResult) {
+		if strings.EqualFold(value, string(format)) {
+			return format, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return "", fmt.Errorf("invalid output format %q (valid values: %s)", value, strings.Join(names, ", "))
+}
+
+// SetFormat sets the format used by Print.
+func SetFormat(format Format) {
+	current = format
+}
+
+// CurrentFormat returns the format used by Print.
+func CurrentFormat() Format {
+	return current
+}
+
+// SetWriter redirects Print, e.g. to capture command output.
+func SetWriter(w io.Writer) {
+	writer = w
+}
+
+// Record is a single structured row, keyed by column name.
+type Record map[string]interface{}
+
+// Result is the set of records produced by a list or describe command.
+// Columns fixes the order used by the table and text renderers.
+type Result struct {
+	Columns []string
+	Records []Record
+}
+
+// NewResult creates an empty result with the given columns.
+func NewResult(columns ...string) *Result {
+	return &Result{Columns: columns, Records: []Record{}}
+}
+
+// Add appends a record whose values are given in column order.
+// Zero timestamps are stored as nil so they render as null rather than year 1.
+func (r *Result) Add(values ...interface{}) {
+	record := Record{}
+	for i, column := range r.Columns {
+		if i >= len(values) {
+			break
+		}
+		if t, ok := values[i].(time.Time); ok && t.IsZero() {
+			record[column] = nil
+			continue
+		}
+		record[column] = values[i]
+	}
+	r.Records = append(r.Records, record)
+}
+
+// AddRecord appends a record, adding any unknown keys as new columns in sorted order.
+func (r *Result) AddRecord(record Record) {
+	known := map[string]bool{}
+	for _, column := range r.Columns {
+		known[column] = true
+	}
+	extra := []string{}
+	for key := range record {
+		if !known[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	r.Columns = append(r.Columns, extra...)
+	r.Records = append(r.Records, record)
+}
+
+// Print renders the result using the format set by SetFormat.
+func Print(result *Result) error {
+	return Render(writer, current, result)
+}
+
+// Render writes the result to w in the given format.
+func Render(w io.Writer, format Format, result *Result) error {
+	switch format {
+	case FormatResult:
+		return json.NewEncoder(w).Encode(resultEnvelope{Columns: result.Columns, Records: result.Records, TimeColumns: result.timeColumns()})
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(result.Records)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(result.Records); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case FormatText:
+		for _, record := range result.Records {
+			fmt.Fprintln(w, strings.Join(recordValues(result.Columns, record), "\t"))
+		}
+		return nil
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(result.Columns); err != nil {
+			return err
+		}
+		for _, record := range result.Records {
+			if err := cw.Write(recordValues(result.Columns, record)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatTable:
+		if len(result.Records) == 0 {
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(result.Columns, "\t"))
+		for _, record := range result.Records {
+			fmt.Fprintln(tw, strings.Join(recordValues(result.Columns, record), "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// resultEnvelope is the FormatResult encoding of a Result. JSON has no
+// timestamps, so TimeColumns names the columns holding them rather than
+// leaving the reader to guess from strings that look like one.
+type resultEnvelope struct {
+	Columns     []string
+	Records     []Record
+	TimeColumns []string `json:",omitempty"`
+}
+
+// timeColumns returns the columns holding timestamps.
+func (r *Result) timeColumns() []string {
+	columns := []string{}
+	for _, column := range r.Columns {
+		for _, record := range r.Records {
+			if _, ok := record[column].(time.Time); ok {
+				columns = append(columns, column)
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// DecodeResult reads a result written in FormatResult. Timestamps and string
+// maps, which JSON turns into strings and generic maps, are restored so the
+// result renders as it would have in the original process.
+func DecodeResult(r io.Reader) (*Result, error) {
+	var envelope resultEnvelope
+	if err := json.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, err
+	}
+	timeColumns := map[string]bool{}
+	for _, column := range envelope.TimeColumns {
+		timeColumns[column] = true
+	}
+	for _, record := range envelope.Records {
+		for key, value := range record {
+			record[key] = restoreValue(value, timeColumns[key])
+		}
+	}
+	return &Result{Columns: envelope.Columns, Records: envelope.Records}, nil
+}
+
+// ParseJSON reads a JSON array of objects, e.g. the output of a plugin, keeping
+// the order in which keys first appear as the column order. Strings are kept as
+// they are, timestamps included. A result written in FormatResult is accepted
+// too, with its timestamps.
+func ParseJSON(data []byte) (*Result, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		result, err := DecodeResult(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if result.Columns == nil {
+			return nil, fmt.Errorf("not a result: missing columns")
+		}
+		return result, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := expectDelim(decoder, '['); err != nil {
+		return nil, err
+	}
+	result := NewResult()
+	for decoder.More() {
+		if err := expectDelim(decoder, '{'); err != nil {
+			return nil, err
+		}
+		record := Record{}
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := token.(string)
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				return nil, err
+			}
+			if _, seen := record[key]; !seen {
+				result.addColumn(key)
+			}
+			record[key] = restoreValue(value, false)
+		}
+		if err := expectDelim(decoder, '}'); err != nil {
+			return nil, err
+		}
+		result.Records = append(result.Records, record)
+	}
+	if err := expectDelim(decoder, ']'); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON array")
+	}
+	return result, nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %q, found %v", delim, token)
+	}
+	return nil
+}
+
+// addColumn appends column unless it is already known.
+func (r *Result) addColumn(column string) {
+	for _, known := range r.Columns {
+		if known == column {
+			return
+		}
+	}
+	r.Columns = append(r.Columns, column)
+}
+
+// restoreValue undoes the JSON encoding of a value: a timestamp when the value
+// is one, and a map of strings such as tags.
+func restoreValue(value interface{}, timestamp bool) interface{} {
+	switch v := value.(type) {
+	case string:
+		if !timestamp {
+			return v
+		}
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+	case map[string]interface{}:
+		values := map[string]string{}
+		for key, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return v
+			}
+			values[key] = s
+		}
+		return values
+	}
+	return value
+}
+
+func recordValues(columns []string, record Record) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = FormatValue(record[column])
+	}
+	return values
+}
+
+// FormatValue converts a record value to the string shown by the table and text renderers.
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(timeLayout)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		return strings.Join(v, ",")
+	case map[string]string:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = key + "=" + v[key]
+		}
+		return strings.Join(pairs, ",")
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,186 @@
+package output
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func sampleResult() *Result {
+	result := NewResult("Name", "Size", "Tags", "Created")
+	result.Add("web-1", 2, map[string]string{"Team": "web", "Env": "prod"}, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	result.Add("db, primary", 0.5, map[string]string{}, time.Time{})
+	return result
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: FormatTable,
+			want: "Name         Size  Tags               Created\n" +
+				"web-1        2     Env=prod,Team=web  2026-01-02 03:04:05\n" +
+				"db, primary  0.5                      \n",
+		},
+		{
+			format: FormatText,
+			want:   "web-1\t2\tEnv=prod,Team=web\t2026-01-02 03:04:05\ndb, primary\t0.5\t\t\n",
+		},
+		{
+			format: FormatCSV,
+			want:   "Name,Size,Tags,Created\nweb-1,2,\"Env=prod,Team=web\",2026-01-02 03:04:05\n\"db, primary\",0.5,,\n",
+		},
+		{
+			format: FormatJSON,
+			want: `[
+  {
+    "Created": "2026-01-02T03:04:05Z",
+    "Name": "web-1",
+    "Size": 2,
+    "Tags": {
+      "Env": "prod",
+      "Team": "web"
+    }
+  },
+  {
+    "Created": null,
+    "Name": "db, primary",
+    "Size": 0.5,
+    "Tags": {}
+  }
+]
+`,
+		},
+		{
+			format: FormatYAML,
+			want: `- Created: 2026-01-02T03:04:05Z
+  Name: web-1
+  Size: 2
+  Tags:
+    Env: prod
+    Team: web
+- Created: null
+  Name: db, primary
+  Size: 0.5
+  Tags: {}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, tt.format, sampleResult()); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Render() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{format: FormatTable, want: ""},
+		{format: FormatText, want: ""},
+		{format: FormatCSV, want: "Name\n"},
+		{format: FormatJSON, want: "[]\n"},
+		{format: FormatYAML, want: "[]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, tt.format, NewResult("Name")); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, value := range []string{"table", "JSON", "yaml", "text", "csv", "result"} {
+		if _, err := ParseFormat(value); err != nil {
+			t.Errorf("ParseFormat(%q) error = %v", value, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error(`ParseFormat("xml") error = nil, want an invalid format`)
+	}
+}
+
+func TestResultRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatResult, sampleResult()); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeResult(&buf)
+	if err != nil {
+		t.Fatalf("DecodeResult() error = %v", err)
+	}
+	var want, got bytes.Buffer
+	Render(&want, FormatTable, sampleResult())
+	Render(&got, FormatTable, decoded)
+	if got.String() != want.String() {
+		t.Errorf("decoded result renders as\n%s\nwant\n%s", got.String(), want.String())
+	}
+}
+
+func TestResultRoundTripTypes(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	result := NewResult("Name", "Created")
+	// A string that only looks like a timestamp must come back as a string
+	result.Add("2026-01-02T03:04:05Z", created)
+	result.Add("backup", time.Time{})
+
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatResult, result); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeResult(&buf)
+	if err != nil {
+		t.Fatalf("DecodeResult() error = %v", err)
+	}
+	want := []Record{
+		{"Name": "2026-01-02T03:04:05Z", "Created": created},
+		{"Name": "backup", "Created": nil},
+	}
+	if !reflect.DeepEqual(decoded.Records, want) {
+		t.Errorf("decoded records = %#v, want %#v", decoded.Records, want)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	result, err := ParseJSON([]byte(`[{"b": 1, "a": "x"}, {"c": true, "a": "y"}]`))
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	var buf bytes.Buffer
+	Render(&buf, FormatCSV, result)
+	if want := "b,a,c\n1,x,\n,y,true\n"; buf.String() != want {
+		t.Errorf("ParseJSON() renders as %q, want %q", buf.String(), want)
+	}
+
+	// Without column types, timestamps are not guessed from strings
+	result, err = ParseJSON([]byte(`[{"a": "2026-01-02T03:04:05Z"}]`))
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	if got := result.Records[0]["a"]; got != "2026-01-02T03:04:05Z" {
+		t.Errorf("ParseJSON() value = %#v, want the string", got)
+	}
+
+	for _, data := range []string{`{"a": 1}`, `[1, 2]`, `[{"a": 1}] trailing`, `not json`} {
+		if _, err := ParseJSON([]byte(data)); err == nil {
+			t.Errorf("ParseJSON(%s) error = nil, want an error", data)
+		}
+	}
+}
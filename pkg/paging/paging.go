@@ -0,0 +1,187 @@
+package paging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// Options are the user controls shared by every listing command.
+type Options struct {
+	MaxItems      int32
+	PageSize      int32
+	StartingToken string
+}
+
+// Range is the page size accepted by an API. Max 0 means no upper bound.
+type Range struct {
+	Min, Max int32
+}
+
+func (r Range) String() string {
+	if r.Max == 0 {
+		return fmt.Sprintf("at least %d", r.Min)
+	}
+	return fmt.Sprintf("%d to %d", r.Min, r.Max)
+}
+
+// AddFlags registers --max-items, --page-size and --starting-token on cmd.
+// --page-size is checked against pageSizes while the flags are parsed, as the
+// APIs only reject an invalid value once the request is sent.
+func AddFlags(cmd *cobra.Command, opts *Options, pageSizes Range) {
+	AddTokenFlags(cmd, opts)
+	cmd.Flags().Var(&pageSizeValue{size: &opts.PageSize, allowed: pageSizes}, "page-size", fmt.Sprintf("Number of items requested per API call, %s (0 uses the service default)", pageSizes))
+}
+
+// pageSizeValue is the value of --page-size.
+type pageSizeValue struct {
+	size    *int32
+	allowed Range
+}
+
+func (v *pageSizeValue) Set(value string) error {
+	size, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("must be a number")
+	}
+	if size != 0 && (int32(size) < v.allowed.Min || v.allowed.Max != 0 && int32(size) > v.allowed.Max) {
+		return fmt.Errorf("must be %s", v.allowed)
+	}
+	*v.size = int32(size)
+	return nil
+}
+
+func (v *pageSizeValue) String() string {
+	return strconv.Itoa(int(*v.size))
+}
+
+func (v *pageSizeValue) Type() string {
+	return "int32"
+}
+
+// AddTokenFlags registers --max-items and --starting-token only, for APIs with a fixed page size.
+func AddTokenFlags(cmd *cobra.Command, opts *Options) {
+	cmd.Flags().Int32Var(&opts.MaxItems, "max-items", 0, "Maximum number of items to return (0 returns every item)")
+	cmd.Flags().StringVar(&opts.StartingToken, "starting-token", "", "Token returned by a previous truncated call to resume listing from")
+}
+
+// Token marks where a truncated listing stopped: the service token of the page
+// being read and how many items of that page were already returned.
+type Token struct {
+	Next string `json:"next,omitempty"`
+	Skip int    `json:"skip,omitempty"`
+}
+
+// Encode returns the opaque form printed to the user.
+func (t Token) Encode() string {
+	encoded, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// DecodeToken parses a token produced by Encode. An empty string is the start of the listing.
+func DecodeToken(value string) (Token, error) {
+	var token Token
+	if value == "" {
+		return token, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return token, fmt.Errorf("invalid starting token: %w", err)
+	}
+	if err := json.Unmarshal(decoded, &token); err != nil {
+		return token, fmt.Errorf("invalid starting token: %w", err)
+	}
+	return token, nil
+}
+
+// Collector accumulates the items of successive pages until MaxItems is reached,
+// remembering the token needed to resume afterwards.
+type Collector[T any] struct {
+	opts    Options
+	current string
+	skip    int
+	items   []T
+	next    *Token
+}
+
+// NewCollector creates a collector positioned at opts.StartingToken.
+func NewCollector[T any](opts Options) (*Collector[T], error) {
+	start, err := DecodeToken(opts.StartingToken)
+	if err != nil {
+		return nil, err
+	}
+	return &Collector[T]{opts: opts, current: start.Next, skip: start.Skip, items: []T{}}, nil
+}
+
+// StartToken returns the service token the first request must use, or nil.
+func (c *Collector[T]) StartToken() *string {
+	if c.current == "" {
+		return nil
+	}
+	token := c.current
+	return &token
+}
+
+// Add records a page of items. nextToken is the service token for the following page.
+func (c *Collector[T]) Add(items []T, nextToken *string) {
+	offset := c.skip
+	c.skip = 0
+	if offset > len(items) {
+		offset = len(items)
+	}
+
+	for i, item := range items[offset:] {
+		if c.full() {
+			c.next = &Token{Next: c.current, Skip: offset + i}
+			return
+		}
+		c.items = append(c.items, item)
+	}
+
+	c.current = ""
+	if nextToken != nil {
+		c.current = *nextToken
+	}
+	if c.full() && c.current != "" {
+		c.next = &Token{Next: c.current}
+	}
+}
+
+// Done reports whether no further pages should be requested.
+func (c *Collector[T]) Done() bool {
+	return c.next != nil || c.full()
+}
+
+// Items returns the collected items.
+func (c *Collector[T]) Items() []T {
+	return c.items
+}
+
+// NextToken returns the token to resume from, or "" when the listing is complete.
+func (c *Collector[T]) NextToken() string {
+	if c.next == nil {
+		return ""
+	}
+	return c.next.Encode()
+}
+
+// PrintNextToken tells the user how to resume a truncated listing. It writes to
+// stderr so structured output on stdout stays parseable.
+func (c *Collector[T]) PrintNextToken() {
+	PrintNextToken(c.NextToken())
+}
+
+// PrintNextToken prints a token returned by Collector.NextToken, if any.
+func PrintNextToken(token string) {
+	if token != "" {
+		fmt.Fprintf(os.Stderr, "NextToken: %s (pass it to --starting-token to continue)\n", token)
+	}
+}
+
+func (c *Collector[T]) full() bool {
+	return c.opts.MaxItems > 0 && int32(len(c.items)) >= c.opts.MaxItems
+}
@@ -0,0 +1,148 @@
+package paging
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestPageSizeFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed Range
+		value   string
+		want    int32
+		wantErr bool
+	}{
+		{name: "service default", allowed: Range{Min: 5, Max: 1000}, value: "0", want: 0},
+		{name: "lower bound", allowed: Range{Min: 5, Max: 1000}, value: "5", want: 5},
+		{name: "upper bound", allowed: Range{Min: 5, Max: 1000}, value: "1000", want: 1000},
+		{name: "below minimum", allowed: Range{Min: 5, Max: 1000}, value: "4", wantErr: true},
+		{name: "above maximum", allowed: Range{Min: 20, Max: 100}, value: "101", wantErr: true},
+		{name: "negative", allowed: Range{Min: 1, Max: 100}, value: "-1", wantErr: true},
+		{name: "no upper bound", allowed: Range{Min: 1}, value: "50000", want: 50000},
+		{name: "not a number", allowed: Range{Min: 1}, value: "ten", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "list"}
+			opts := Options{}
+			AddFlags(cmd, &opts, tt.allowed)
+			err := cmd.ParseFlags([]string{"--page-size", tt.value})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && opts.PageSize != tt.want {
+				t.Errorf("PageSize = %d, want %d", opts.PageSize, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Token
+		wantErr bool
+	}{
+		{name: "empty", value: "", want: Token{}},
+		{name: "round trip", value: Token{Next: "abc", Skip: 3}.Encode(), want: Token{Next: "abc", Skip: 3}},
+		{name: "not base64", value: "!!!", wantErr: true},
+		{name: "not json", value: "bm90IGpzb24", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeToken(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("DecodeToken() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// page is a page of a listing and the service token of the next one.
+type page struct {
+	items []string
+	next  string
+}
+
+// collect reads pages the way the list commands do, starting at the page
+// designated by the start token.
+func collect(t *testing.T, pages map[string]page, opts Options) ([]string, string, int) {
+	t.Helper()
+	collector, err := NewCollector[string](opts)
+	if err != nil {
+		t.Fatalf("NewCollector() error = %v", err)
+	}
+	token := ""
+	if start := collector.StartToken(); start != nil {
+		token = *start
+	}
+	requests := 0
+	for !collector.Done() {
+		current := pages[token]
+		requests++
+		var next *string
+		if current.next != "" {
+			next = &current.next
+		}
+		collector.Add(current.items, next)
+		if next == nil {
+			break
+		}
+		token = *next
+	}
+	return collector.Items(), collector.NextToken(), requests
+}
+
+func TestCollector(t *testing.T) {
+	pages := map[string]page{
+		"":   {items: []string{"a", "b", "c"}, next: "p2"},
+		"p2": {items: []string{"d", "e", "f"}, next: "p3"},
+		"p3": {items: []string{"g"}},
+	}
+	tests := []struct {
+		name         string
+		opts         Options
+		want         []string
+		wantNext     *Token
+		wantRequests int
+	}{
+		{name: "everything", opts: Options{}, want: []string{"a", "b", "c", "d", "e", "f", "g"}, wantRequests: 3},
+		{name: "within a page", opts: Options{MaxItems: 2}, want: []string{"a", "b"}, wantNext: &Token{Skip: 2}, wantRequests: 1},
+		{name: "page boundary", opts: Options{MaxItems: 3}, want: []string{"a", "b", "c"}, wantNext: &Token{Next: "p2"}, wantRequests: 1},
+		{name: "across pages", opts: Options{MaxItems: 5}, want: []string{"a", "b", "c", "d", "e"}, wantNext: &Token{Next: "p2", Skip: 2}, wantRequests: 2},
+		{name: "exact total", opts: Options{MaxItems: 7}, want: []string{"a", "b", "c", "d", "e", "f", "g"}, wantRequests: 3},
+		{name: "resume within a page", opts: Options{MaxItems: 2, StartingToken: Token{Next: "p2", Skip: 2}.Encode()}, want: []string{"f", "g"}, wantRequests: 2},
+		{name: "resume at first page", opts: Options{StartingToken: Token{Skip: 1}.Encode()}, want: []string{"b", "c", "d", "e", "f", "g"}, wantRequests: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, next, requests := collect(t, pages, tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Items() = %v, want %v", got, tt.want)
+			}
+			wantNext := ""
+			if tt.wantNext != nil {
+				wantNext = tt.wantNext.Encode()
+			}
+			if next != wantNext {
+				t.Errorf("NextToken() = %q, want %q", next, wantNext)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestNewCollectorInvalidToken(t *testing.T) {
+	if _, err := NewCollector[string](Options{StartingToken: "%%%"}); err == nil {
+		t.Error("NewCollector() error = nil, want an invalid token error")
+	}
+}
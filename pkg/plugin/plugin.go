@@ -0,0 +1,205 @@
+// Package plugin runs external subcommands: executables named icp-aws-cli-<name>
+// on PATH become `icp-aws-cli <name>`, git-style. Plugins receive the resolved
+// settings through the environment, and their JSON output is rendered with the
+// --output format like the built-in commands.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"icp-aws-cli/pkg/output"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Prefix starts the file name of every plugin executable.
+const Prefix = "icp-aws-cli-"
+
+// Environment variables set for plugins, next to the standard AWS_PROFILE,
+// AWS_REGION and AWS_ENDPOINT_URL read by the AWS SDKs.
+const (
+	EnvProfile     = "ICP_AWS_CLI_PROFILE"
+	EnvRegion      = "ICP_AWS_CLI_REGION"
+	EnvEndpointURL = "ICP_AWS_CLI_ENDPOINT_URL"
+	EnvOutput      = "ICP_AWS_CLI_OUTPUT"
+	EnvDryRun      = "ICP_AWS_CLI_DRY_RUN"
+	EnvAssumeYes   = "ICP_AWS_CLI_ASSUME_YES"
+)
+
+// Plugin is an executable found on PATH.
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// Discover returns the plugins on PATH sorted by name. When several directories
+// provide the same plugin, the first one on PATH wins, as for any command.
+func Discover() []Plugin {
+	found := map[string]Plugin{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), Prefix)
+			if !ok || name == "" || found[name].Path != "" {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+				continue
+			}
+			found[name] = Plugin{Name: name, Path: filepath.Join(dir, entry.Name())}
+		}
+	}
+
+	plugins := make([]Plugin, 0, len(found))
+	for _, plugin := range found {
+		plugins = append(plugins, plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// Env is the resolved configuration handed to a plugin.
+type Env struct {
+	Profile     string
+	Region      string
+	EndpointURL string
+	Output      output.Format
+	DryRun      bool
+	AssumeYes   bool
+	// Credentials are passed when the CLI resolved them itself, e.g. for an
+	// assumed role, so plugins need not prompt for MFA again.
+	Credentials *aws.Credentials
+}
+
+func (e Env) environ() []string {
+	env := os.Environ()
+	set := func(name, value string) {
+		if value != "" {
+			env = append(env, name+"="+value)
+		}
+	}
+	set(EnvProfile, e.Profile)
+	set(EnvRegion, e.Region)
+	set(EnvEndpointURL, e.EndpointURL)
+	set(EnvOutput, string(e.Output))
+	set(EnvDryRun, strconv.FormatBool(e.DryRun))
+	set(EnvAssumeYes, strconv.FormatBool(e.AssumeYes))
+	set("AWS_PROFILE", e.Profile)
+	set("AWS_REGION", e.Region)
+	set("AWS_ENDPOINT_URL", e.EndpointURL)
+	if e.Credentials != nil {
+		set("AWS_ACCESS_KEY_ID", e.Credentials.AccessKeyID)
+		set("AWS_SECRET_ACCESS_KEY", e.Credentials.SecretAccessKey)
+		set("AWS_SESSION_TOKEN", e.Credentials.SessionToken)
+	}
+	return env
+}
+
+// ExitError reports a plugin that exited with a non-zero status. The CLI exits
+// with the same status, so plugins can use the documented exit codes.
+type ExitError struct {
+	Name   string
+	Status int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("plugin %s exited with status %d", e.Name, e.Status)
+}
+
+// ExitCode returns the exit status of the plugin.
+func (e *ExitError) ExitCode() int {
+	return e.Status
+}
+
+// Run executes the plugin with args. Stdin and stderr are passed through. Stdout
+// is rendered with the output formatter when it holds a JSON array of objects
+// (or a result written with --output result); any other output is streamed
+// unchanged as the plugin writes it. Output starting with [ or { is held until
+// the plugin exits, as JSON can only be rendered once complete; output that
+// turns out not to be a result, such as JSON lines, is then copied unchanged.
+func (p Plugin) Run(ctx context.Context, args []string, env Env) error {
+	stdout := &sniffer{out: os.Stdout}
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	cmd.Env = env.environ()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	runErr := cmd.Run()
+
+	if err := stdout.flush(); err != nil {
+		return err
+	}
+
+	if runErr != nil && ctx.Err() != nil {
+		// Killed on Ctrl-C or --timeout
+		return fmt.Errorf("plugin %s: %w", p.Name, ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		return &ExitError{Name: p.Name, Status: exitErr.ExitCode()}
+	}
+	if runErr != nil {
+		return fmt.Errorf("could not run plugin %s: %w", p.Name, runErr)
+	}
+	return nil
+}
+
+// sniffer holds the output of a plugin until its first non-blank byte tells
+// whether it is JSON to render, and passes any other output straight through.
+// JSON is buffered in full until flush.
+type sniffer struct {
+	out       io.Writer
+	buffered  bytes.Buffer
+	decided   bool
+	streaming bool
+}
+
+func (s *sniffer) Write(p []byte) (int, error) {
+	if s.streaming {
+		return s.out.Write(p)
+	}
+	s.buffered.Write(p)
+	if s.decided {
+		return len(p), nil
+	}
+	trimmed := bytes.TrimLeft(s.buffered.Bytes(), " \t\r\n")
+	if len(trimmed) == 0 {
+		return len(p), nil
+	}
+	s.decided = true
+	if trimmed[0] == '[' || trimmed[0] == '{' {
+		return len(p), nil
+	}
+	s.streaming = true
+	if _, err := s.out.Write(s.buffered.Bytes()); err != nil {
+		return 0, err
+	}
+	s.buffered.Reset()
+	return len(p), nil
+}
+
+// flush renders the buffered JSON output, or copies it unchanged when it is not
+// a result after all.
+func (s *sniffer) flush() error {
+	if s.buffered.Len() == 0 {
+		return nil
+	}
+	if result, err := output.ParseJSON(s.buffered.Bytes()); err == nil {
+		return output.Print(result)
+	}
+	_, err := s.out.Write(s.buffered.Bytes())
+	return err
+}
@@ -0,0 +1,178 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"icp-aws-cli/pkg/output"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// script writes an executable shell script named name into dir.
+func script(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts in these tests")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	report := script(t, first, Prefix+"report", "true")
+	script(t, second, Prefix+"report", "true")
+	cleanup := script(t, second, Prefix+"cleanup", "true")
+	// Not plugins: not executable, a directory, no name, another prefix
+	if err := os.WriteFile(filepath.Join(first, Prefix+"notes"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(first, Prefix+"dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script(t, first, Prefix, "true")
+	script(t, first, "aws-report", "true")
+	t.Setenv("PATH", strings.Join([]string{first, filepath.Join(first, "missing"), second}, string(os.PathListSeparator)))
+
+	want := []Plugin{{Name: "cleanup", Path: cleanup}, {Name: "report", Path: report}}
+	if got := Discover(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Discover() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "env")
+	p := Plugin{Name: "env", Path: script(t, dir, Prefix+"env", `env > "$1"`)}
+	err := p.Run(context.Background(), []string{envFile}, Env{
+		Profile:     "prod",
+		Region:      "eu-west-1",
+		EndpointURL: "http://localhost:4566",
+		Output:      output.FormatJSON,
+		DryRun:      true,
+		Credentials: &aws.Credentials{AccessKeyID: "ASIAEXAMPLE", SecretAccessKey: "secret", SessionToken: "token"},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	data, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			env[key] = value
+		}
+	}
+
+	for key, want := range map[string]string{
+		EnvProfile:              "prod",
+		EnvRegion:               "eu-west-1",
+		EnvEndpointURL:          "http://localhost:4566",
+		EnvOutput:               "json",
+		EnvDryRun:               "true",
+		EnvAssumeYes:            "false",
+		"AWS_PROFILE":           "prod",
+		"AWS_REGION":            "eu-west-1",
+		"AWS_ENDPOINT_URL":      "http://localhost:4566",
+		"AWS_ACCESS_KEY_ID":     "ASIAEXAMPLE",
+		"AWS_SECRET_ACCESS_KEY": "secret",
+		"AWS_SESSION_TOKEN":     "token",
+	} {
+		if got := env[key]; got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestRunExitStatus(t *testing.T) {
+	p := Plugin{Name: "fail", Path: script(t, t.TempDir(), Prefix+"fail", "exit 3")}
+	err := p.Run(context.Background(), nil, Env{})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Name != "fail" || exitErr.ExitCode() != 3 {
+		t.Errorf("Run() error = %v, want exit status 3", err)
+	}
+}
+
+func TestRunRendersJSON(t *testing.T) {
+	var buf bytes.Buffer
+	output.SetWriter(&buf)
+	output.SetFormat(output.FormatCSV)
+	t.Cleanup(func() {
+		output.SetWriter(os.Stdout)
+		output.SetFormat(output.FormatTable)
+	})
+
+	p := Plugin{Name: "report", Path: script(t, t.TempDir(), Prefix+"report", `echo '[{"Name": "web-1", "State": "running"}]'`)}
+	if err := p.Run(context.Background(), nil, Env{}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := "Name,State\nweb-1,running\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestSniffer(t *testing.T) {
+	tests := []struct {
+		name       string
+		writes     []string
+		wantStream string
+		wantFlush  string
+	}{
+		{
+			name:       "text is streamed",
+			writes:     []string{"\n", "  Checking buckets\n", "done\n"},
+			wantStream: "\n  Checking buckets\ndone\n",
+			wantFlush:  "\n  Checking buckets\ndone\n",
+		},
+		{
+			name:      "JSON is held until flush",
+			writes:    []string{`[{"Name":`, ` "web-1"}]`},
+			wantFlush: "web-1\n",
+		},
+		{
+			name:      "JSON lines are copied unchanged",
+			writes:    []string{"{\"Name\": \"web-1\"}\n", "{\"Name\": \"web-2\"}\n"},
+			wantFlush: "{\"Name\": \"web-1\"}\n{\"Name\": \"web-2\"}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rendered bytes.Buffer
+			output.SetWriter(&rendered)
+			output.SetFormat(output.FormatText)
+			t.Cleanup(func() {
+				output.SetWriter(os.Stdout)
+				output.SetFormat(output.FormatTable)
+			})
+
+			var out bytes.Buffer
+			s := &sniffer{out: &out}
+			for _, write := range tt.writes {
+				if _, err := s.Write([]byte(write)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if out.String() != tt.wantStream {
+				t.Errorf("output before exit = %q, want %q", out.String(), tt.wantStream)
+			}
+			if err := s.flush(); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String() + rendered.String(); got != tt.wantFlush {
+				t.Errorf("output after exit = %q, want %q", got, tt.wantFlush)
+			}
+		})
+	}
+}
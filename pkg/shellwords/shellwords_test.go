@@ -0,0 +1,36 @@
+package shellwords
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{line: "", want: []string{}},
+		{line: "  ec2\tlist  --all ", want: []string{"ec2", "list", "--all"}},
+		{line: `--filter "tag:Name=my app"`, want: []string{"--filter", "tag:Name=my app"}},
+		{line: `--filter 'name~^web "a"'`, want: []string{"--filter", `name~^web "a"`}},
+		{line: `tag:Name=my\ app`, want: []string{"tag:Name=my app"}},
+		{line: `""`, want: []string{""}},
+		{line: `a"b c"d`, want: []string{"ab cd"}},
+		{line: `'it\'s`, want: []string{`it\s`}},
+		{line: `"open`, wantErr: true},
+		{line: `trailing\`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := Split(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Split() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+type liveAlarm struct {
+	alarm Alarm
+	tags  map[string]string
+}
+
+func planAlarms(ctx context.Context, clients *awsclient.AWSClientCollection, m *Manifest, skip skipFunc) ([]Change, []Change, error) {
+	wanted := map[string]bool{}
+	for _, alarm := range m.Alarms {
+		wanted[alarm.Name] = true
+	}
+
+	names := []string{}
+	live := map[string]liveAlarm{}
+	paginator := cloudwatch.NewDescribeAlarmsPaginator(clients.CloudWatch, &cloudwatch.DescribeAlarmsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not list alarms: %w", err)
+		}
+		for _, metricAlarm := range page.MetricAlarms {
+			name := aws.ToString(metricAlarm.AlarmName)
+			out, err := clients.CloudWatch.ListTagsForResource(ctx, &cloudwatch.ListTagsForResourceInput{ResourceARN: metricAlarm.AlarmArn})
+			if err != nil {
+				err = fmt.Errorf("could not get tags of alarm %s: %w", name, err)
+				if wanted[name] {
+					return nil, nil, err
+				}
+				skip(KindAlarm, name, err)
+				continue
+			}
+			tags := map[string]string{}
+			for _, tag := range out.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			names = append(names, name)
+			live[name] = liveAlarm{alarm: alarmSettings(metricAlarm), tags: tags}
+		}
+	}
+	tagger := tagging.Alarms(clients.CloudWatch)
+
+	changes, deletes := []Change{}, []Change{}
+	for _, alarm := range m.Alarms {
+		desired := desiredTags(m, alarm.Tags)
+		current, ok := live[alarm.Name]
+		if !ok {
+			changes = append(changes, createChange(KindAlarm, alarm.Name, alarm.Tags, []string{condition(alarm)}, func(ctx context.Context) error {
+				return putAlarm(ctx, clients.CloudWatch, alarm, desired)
+			}))
+			continue
+		}
+		if err := checkOwner(m, KindAlarm, alarm.Name, current.tags); err != nil {
+			return nil, nil, err
+		}
+		var update func(ctx context.Context) error
+		details := alarmDifferences(current.alarm, alarm)
+		if len(details) > 0 {
+			// PutMetricAlarm replaces the whole definition but leaves the tags alone
+			update = func(ctx context.Context) error {
+				return putAlarm(ctx, clients.CloudWatch, alarm, nil)
+			}
+		}
+		if change, ok := updateChange(KindAlarm, alarm.Name, tagger, current.tags, desired, details, update); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	for _, name := range names {
+		if wanted[name] || !ownedBy(m, live[name].tags) {
+			continue
+		}
+		deletes = append(deletes, deleteChange(KindAlarm, name, func(ctx context.Context) error {
+			_, err := clients.CloudWatch.DeleteAlarms(ctx, &cloudwatch.DeleteAlarmsInput{AlarmNames: []string{name}})
+			return err
+		}))
+	}
+	return changes, deletes, nil
+}
+
+// alarmSettings converts a live alarm to its manifest form for comparison.
+func alarmSettings(alarm types.MetricAlarm) Alarm {
+	settings := Alarm{
+		Name:              aws.ToString(alarm.AlarmName),
+		Namespace:         aws.ToString(alarm.Namespace),
+		Metric:            aws.ToString(alarm.MetricName),
+		Dimensions:        map[string]string{},
+		Statistic:         string(alarm.Statistic),
+		Period:            aws.ToInt32(alarm.Period),
+		Comparison:        string(alarm.ComparisonOperator),
+		Threshold:         aws.ToFloat64(alarm.Threshold),
+		EvaluationPeriods: aws.ToInt32(alarm.EvaluationPeriods),
+		AlarmActions:      alarm.AlarmActions,
+	}
+	for _, dimension := range alarm.Dimensions {
+		settings.Dimensions[aws.ToString(dimension.Name)] = aws.ToString(dimension.Value)
+	}
+	return settings
+}
+
+func condition(alarm Alarm) string {
+	return fmt.Sprintf("%s %s/%s %s %g for %d x %ds", alarm.Statistic, alarm.Namespace, alarm.Metric, alarm.Comparison, alarm.Threshold, alarm.EvaluationPeriods, alarm.Period)
+}
+
+func alarmDifferences(current, desired Alarm) []string {
+	details := []string{}
+	if condition(current) != condition(desired) {
+		details = append(details, fmt.Sprintf("condition: %s -> %s", condition(current), condition(desired)))
+	}
+	if dimensions(current.Dimensions) != dimensions(desired.Dimensions) {
+		details = append(details, fmt.Sprintf("dimensions: %s -> %s", dimensions(current.Dimensions), dimensions(desired.Dimensions)))
+	}
+	if actions(current.AlarmActions) != actions(desired.AlarmActions) {
+		details = append(details, fmt.Sprintf("actions: %s -> %s", actions(current.AlarmActions), actions(desired.AlarmActions)))
+	}
+	return details
+}
+
+func dimensions(values map[string]string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(tagging.Format(values), " ")
+}
+
+func actions(arns []string) string {
+	if len(arns) == 0 {
+		return "none"
+	}
+	sorted := append([]string{}, arns...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
+
+// putAlarm creates or replaces the alarm. tags are only applied on creation.
+func putAlarm(ctx context.Context, cwClient awsclient.CloudWatchAPI, alarm Alarm, tags map[string]string) error {
+	input := &cloudwatch.PutMetricAlarmInput{
+		AlarmName:          aws.String(alarm.Name),
+		Namespace:          aws.String(alarm.Namespace),
+		MetricName:         aws.String(alarm.Metric),
+		Statistic:          types.Statistic(alarm.Statistic),
+		Period:             aws.Int32(alarm.Period),
+		ComparisonOperator: types.ComparisonOperator(alarm.Comparison),
+		Threshold:          aws.Float64(alarm.Threshold),
+		EvaluationPeriods:  aws.Int32(alarm.EvaluationPeriods),
+		AlarmActions:       alarm.AlarmActions,
+	}
+	for _, name := range tagging.Keys(alarm.Dimensions) {
+		input.Dimensions = append(input.Dimensions, types.Dimension{Name: aws.String(name), Value: aws.String(alarm.Dimensions[name])})
+	}
+	for _, key := range tagging.Keys(tags) {
+		input.Tags = append(input.Tags, types.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+	_, err := cwClient.PutMetricAlarm(ctx, input)
+	return err
+}
@@ -0,0 +1,84 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// planBuckets only considers the buckets of the current region.
+func planBuckets(ctx context.Context, clients *awsclient.AWSClientCollection, m *Manifest, skip skipFunc) ([]Change, []Change, error) {
+	wanted := map[string]bool{}
+	for _, bucket := range m.Buckets {
+		wanted[bucket.Name] = true
+	}
+
+	live := []string{}
+	paginator := s3.NewListBucketsPaginator(clients.S3, &s3.ListBucketsInput{BucketRegion: aws.String(clients.Region)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not list buckets: %w", err)
+		}
+		for _, bucket := range page.Buckets {
+			live = append(live, aws.ToString(bucket.Name))
+		}
+	}
+	tagger := tagging.Buckets(clients.S3)
+	liveTags := map[string]map[string]string{}
+	for _, name := range live {
+		tags, err := tagger.Get(ctx, name)
+		if err != nil {
+			if wanted[name] {
+				return nil, nil, err
+			}
+			skip(KindBucket, name, err)
+			continue
+		}
+		liveTags[name] = tags
+	}
+
+	changes, deletes := []Change{}, []Change{}
+	for _, bucket := range m.Buckets {
+		name, desired := bucket.Name, desiredTags(m, bucket.Tags)
+		current, ok := liveTags[name]
+		if !ok {
+			changes = append(changes, createChange(KindBucket, name, bucket.Tags, nil, func(ctx context.Context) error {
+				input := &s3.CreateBucketInput{Bucket: aws.String(name)}
+				// us-east-1 is the default location and cannot be given explicitly
+				if clients.Region != "" && clients.Region != "us-east-1" {
+					input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+						LocationConstraint: types.BucketLocationConstraint(clients.Region),
+					}
+				}
+				if _, err := clients.S3.CreateBucket(ctx, input); err != nil {
+					return err
+				}
+				return tagger.Add(ctx, name, desired)
+			}))
+			continue
+		}
+		if err := checkOwner(m, KindBucket, name, current); err != nil {
+			return nil, nil, err
+		}
+		if change, ok := updateChange(KindBucket, name, tagger, current, desired, nil, nil); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	for _, name := range live {
+		if wanted[name] || !ownedBy(m, liveTags[name]) {
+			continue
+		}
+		deletes = append(deletes, deleteChange(KindBucket, name, func(ctx context.Context) error {
+			_, err := clients.S3.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: aws.String(name)})
+			return err
+		}))
+	}
+	return changes, deletes, nil
+}
@@ -0,0 +1,151 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+func planAutoScalingGroups(ctx context.Context, clients *awsclient.AWSClientCollection, m *Manifest, _ skipFunc) ([]Change, []Change, error) {
+	names := []string{}
+	live := map[string]types.AutoScalingGroup{}
+	liveTags := map[string]map[string]string{}
+	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(clients.AutoScaling, &autoscaling.DescribeAutoScalingGroupsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not list AutoScaling groups: %w", err)
+		}
+		for _, group := range page.AutoScalingGroups {
+			name := aws.ToString(group.AutoScalingGroupName)
+			tags := map[string]string{}
+			for _, tag := range group.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			names = append(names, name)
+			live[name] = group
+			liveTags[name] = tags
+		}
+	}
+	tagger := tagging.Groups(clients.AutoScaling)
+
+	changes, deletes := []Change{}, []Change{}
+	for _, group := range m.AutoScalingGroups {
+		desired := desiredTags(m, group.Tags)
+		current, ok := live[group.Name]
+		if !ok {
+			changes = append(changes, createChange(KindAutoScalingGroup, group.Name, group.Tags, groupSettings(group), func(ctx context.Context) error {
+				_, err := clients.AutoScaling.CreateAutoScalingGroup(ctx, &autoscaling.CreateAutoScalingGroupInput{
+					AutoScalingGroupName:    aws.String(group.Name),
+					LaunchConfigurationName: aws.String(group.LaunchConfiguration),
+					MinSize:                 aws.Int32(group.MinSize),
+					MaxSize:                 aws.Int32(group.MaxSize),
+					DesiredCapacity:         group.DesiredCapacity,
+					AvailabilityZones:       group.AvailabilityZones,
+					VPCZoneIdentifier:       subnets(group.Subnets),
+					Tags:                    tagging.GroupTags(group.Name, desired),
+				})
+				return err
+			}))
+			continue
+		}
+		if err := checkOwner(m, KindAutoScalingGroup, group.Name, liveTags[group.Name]); err != nil {
+			return nil, nil, err
+		}
+		details, input := groupUpdate(current, group)
+		var update func(ctx context.Context) error
+		if input != nil {
+			update = func(ctx context.Context) error {
+				_, err := clients.AutoScaling.UpdateAutoScalingGroup(ctx, input)
+				return err
+			}
+		}
+		if change, ok := updateChange(KindAutoScalingGroup, group.Name, tagger, liveTags[group.Name], desired, details, update); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	wanted := map[string]bool{}
+	for _, group := range m.AutoScalingGroups {
+		wanted[group.Name] = true
+	}
+	for _, name := range names {
+		if wanted[name] || !ownedBy(m, liveTags[name]) {
+			continue
+		}
+		deletes = append(deletes, deleteChange(KindAutoScalingGroup, name, func(ctx context.Context) error {
+			_, err := clients.AutoScaling.DeleteAutoScalingGroup(ctx, &autoscaling.DeleteAutoScalingGroupInput{
+				AutoScalingGroupName: aws.String(name),
+				ForceDelete:          aws.Bool(true),
+			})
+			return err
+		}))
+	}
+	return changes, deletes, nil
+}
+
+func groupSettings(group AutoScalingGroup) []string {
+	settings := []string{
+		"launch configuration " + group.LaunchConfiguration,
+		fmt.Sprintf("size %d-%d", group.MinSize, group.MaxSize),
+	}
+	if group.DesiredCapacity != nil {
+		settings = append(settings, fmt.Sprintf("desired %d", *group.DesiredCapacity))
+	}
+	return settings
+}
+
+func subnets(ids []string) *string {
+	if len(ids) == 0 {
+		return nil
+	}
+	return aws.String(strings.Join(ids, ","))
+}
+
+func sameSet(a, b []string) bool {
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
+
+// groupUpdate compares the group with the manifest. The desired capacity, zones
+// and subnets are only compared when the manifest sets them.
+func groupUpdate(current types.AutoScalingGroup, group AutoScalingGroup) ([]string, *autoscaling.UpdateAutoScalingGroupInput) {
+	details := []string{}
+	input := &autoscaling.UpdateAutoScalingGroupInput{AutoScalingGroupName: aws.String(group.Name)}
+
+	if launchConfiguration := aws.ToString(current.LaunchConfigurationName); launchConfiguration != group.LaunchConfiguration {
+		details = append(details, fmt.Sprintf("launch configuration: %s -> %s", launchConfiguration, group.LaunchConfiguration))
+		input.LaunchConfigurationName = aws.String(group.LaunchConfiguration)
+	}
+	if min, max := aws.ToInt32(current.MinSize), aws.ToInt32(current.MaxSize); min != group.MinSize || max != group.MaxSize {
+		details = append(details, fmt.Sprintf("size: %d-%d -> %d-%d", min, max, group.MinSize, group.MaxSize))
+		input.MinSize = aws.Int32(group.MinSize)
+		input.MaxSize = aws.Int32(group.MaxSize)
+	}
+	if desired := aws.ToInt32(current.DesiredCapacity); group.DesiredCapacity != nil && desired != *group.DesiredCapacity {
+		details = append(details, fmt.Sprintf("desired: %d -> %d", desired, *group.DesiredCapacity))
+		input.DesiredCapacity = group.DesiredCapacity
+	}
+	if len(group.AvailabilityZones) > 0 && !sameSet(current.AvailabilityZones, group.AvailabilityZones) {
+		details = append(details, fmt.Sprintf("zones: %s -> %s", strings.Join(current.AvailabilityZones, ","), strings.Join(group.AvailabilityZones, ",")))
+		input.AvailabilityZones = group.AvailabilityZones
+	}
+	if current := strings.Split(aws.ToString(current.VPCZoneIdentifier), ","); len(group.Subnets) > 0 && !sameSet(current, group.Subnets) {
+		details = append(details, fmt.Sprintf("subnets: %s -> %s", strings.Join(current, ","), strings.Join(group.Subnets, ",")))
+		input.VPCZoneIdentifier = subnets(group.Subnets)
+	}
+
+	if len(details) == 0 {
+		return nil, nil
+	}
+	return details, input
+}
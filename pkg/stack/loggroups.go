@@ -0,0 +1,113 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+type liveLogGroup struct {
+	retention int32
+	tags      map[string]string
+}
+
+func planLogGroups(ctx context.Context, clients *awsclient.AWSClientCollection, m *Manifest, skip skipFunc) ([]Change, []Change, error) {
+	wanted := map[string]bool{}
+	for _, group := range m.LogGroups {
+		wanted[group.Name] = true
+	}
+
+	names := []string{}
+	live := map[string]liveLogGroup{}
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(clients.CloudWatchLogs, &cloudwatchlogs.DescribeLogGroupsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not list log groups: %w", err)
+		}
+		for _, group := range page.LogGroups {
+			name := aws.ToString(group.LogGroupName)
+			out, err := clients.CloudWatchLogs.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{ResourceArn: tagging.LogGroupARN(group)})
+			if err != nil {
+				err = fmt.Errorf("could not get tags of log group %s: %w", name, err)
+				if wanted[name] {
+					return nil, nil, err
+				}
+				skip(KindLogGroup, name, err)
+				continue
+			}
+			names = append(names, name)
+			live[name] = liveLogGroup{retention: aws.ToInt32(group.RetentionInDays), tags: out.Tags}
+		}
+	}
+	tagger := tagging.LogGroups(clients.CloudWatchLogs)
+
+	changes, deletes := []Change{}, []Change{}
+	for _, group := range m.LogGroups {
+		desired := desiredTags(m, group.Tags)
+		current, ok := live[group.Name]
+		if !ok {
+			changes = append(changes, createChange(KindLogGroup, group.Name, group.Tags, []string{"retention " + retention(group.RetentionDays)}, func(ctx context.Context) error {
+				if _, err := clients.CloudWatchLogs.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
+					LogGroupName: aws.String(group.Name),
+					Tags:         desired,
+				}); err != nil {
+					return err
+				}
+				if group.RetentionDays == 0 {
+					return nil
+				}
+				return setRetention(ctx, clients.CloudWatchLogs, group)
+			}))
+			continue
+		}
+		if err := checkOwner(m, KindLogGroup, group.Name, current.tags); err != nil {
+			return nil, nil, err
+		}
+		var details []string
+		var update func(ctx context.Context) error
+		if current.retention != group.RetentionDays {
+			details = []string{fmt.Sprintf("retention: %s -> %s", retention(current.retention), retention(group.RetentionDays))}
+			update = func(ctx context.Context) error {
+				return setRetention(ctx, clients.CloudWatchLogs, group)
+			}
+		}
+		if change, ok := updateChange(KindLogGroup, group.Name, tagger, current.tags, desired, details, update); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	for _, name := range names {
+		if wanted[name] || !ownedBy(m, live[name].tags) {
+			continue
+		}
+		deletes = append(deletes, deleteChange(KindLogGroup, name, func(ctx context.Context) error {
+			_, err := clients.CloudWatchLogs.DeleteLogGroup(ctx, &cloudwatchlogs.DeleteLogGroupInput{LogGroupName: aws.String(name)})
+			return err
+		}))
+	}
+	return changes, deletes, nil
+}
+
+func retention(days int32) string {
+	if days == 0 {
+		return "never expire"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+func setRetention(ctx context.Context, logsClient awsclient.CloudWatchLogsAPI, group LogGroup) error {
+	if group.RetentionDays == 0 {
+		_, err := logsClient.DeleteRetentionPolicy(ctx, &cloudwatchlogs.DeleteRetentionPolicyInput{LogGroupName: aws.String(group.Name)})
+		return err
+	}
+	_, err := logsClient.PutRetentionPolicy(ctx, &cloudwatchlogs.PutRetentionPolicyInput{
+		LogGroupName:    aws.String(group.Name),
+		RetentionInDays: aws.Int32(group.RetentionDays),
+	})
+	return err
+}
@@ -0,0 +1,222 @@
+// Package stack converges a set of resources described in a manifest: it diffs
+// the manifest against the live resources, plans the changes and applies them.
+//
+//	stack: web
+//	buckets:
+//	  - name: web-assets
+//	    tags: {team: web}
+//	tables:
+//	  - name: web-sessions
+//	    partition_key: {name: id, type: S}
+//	    sort_key: {name: created, type: N}   # optional
+//	    read_capacity: 5                     # on-demand when both are omitted
+//	    write_capacity: 5
+//	log_groups:
+//	  - name: /web/app
+//	    retention_days: 14
+//	alarms:
+//	  - name: web-cpu
+//	    namespace: AWS/EC2
+//	    metric: CPUUtilization
+//	    dimensions: {AutoScalingGroupName: web}
+//	    statistic: Average                   # default
+//	    period: 300                          # default, in seconds
+//	    comparison: GreaterThanThreshold
+//	    threshold: 80
+//	    evaluation_periods: 2
+//	    alarm_actions: [arn:aws:sns:us-east-1:123456789012:web-alerts]
+//	auto_scaling_groups:
+//	  - name: web
+//	    launch_configuration: web-lc
+//	    min_size: 1
+//	    max_size: 4
+//	    desired_capacity: 2                  # left alone when omitted
+//	    availability_zones: [us-east-1a, us-east-1b]
+//
+// Resources are owned by the stack through the OwnerTag tag. Resources of the
+// stack that are no longer in the manifest are deleted; existing resources
+// without the tag are adopted, and resources owned by another stack are refused.
+package stack
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OwnerTag is the tag key holding the name of the stack owning a resource.
+const OwnerTag = "icp-aws-cli:stack"
+
+// Defaults of the optional alarm settings.
+const (
+	DefaultStatistic = "Average"
+	DefaultPeriod    = 300
+)
+
+// Manifest is the desired state of a stack.
+type Manifest struct {
+	Stack             string             `yaml:"stack"`
+	Buckets           []Bucket           `yaml:"buckets"`
+	Tables            []Table            `yaml:"tables"`
+	LogGroups         []LogGroup         `yaml:"log_groups"`
+	Alarms            []Alarm            `yaml:"alarms"`
+	AutoScalingGroups []AutoScalingGroup `yaml:"auto_scaling_groups"`
+}
+
+// Bucket is an S3 bucket, created in the current region.
+type Bucket struct {
+	Name string            `yaml:"name"`
+	Tags map[string]string `yaml:"tags"`
+}
+
+// Key is an attribute of the primary key of a table.
+type Key struct {
+	Name string `yaml:"name"`
+	// Type is S, N or B.
+	Type string `yaml:"type"`
+}
+
+// Table is a DynamoDB table.
+type Table struct {
+	Name          string            `yaml:"name"`
+	PartitionKey  Key               `yaml:"partition_key"`
+	SortKey       *Key              `yaml:"sort_key"`
+	ReadCapacity  int64             `yaml:"read_capacity"`
+	WriteCapacity int64             `yaml:"write_capacity"`
+	Tags          map[string]string `yaml:"tags"`
+}
+
+// OnDemand reports whether the table uses the pay-per-request billing mode.
+func (t Table) OnDemand() bool {
+	return t.ReadCapacity == 0 && t.WriteCapacity == 0
+}
+
+// LogGroup is a CloudWatch log group. A zero retention keeps events forever.
+type LogGroup struct {
+	Name          string            `yaml:"name"`
+	RetentionDays int32             `yaml:"retention_days"`
+	Tags          map[string]string `yaml:"tags"`
+}
+
+// Alarm is a CloudWatch metric alarm.
+type Alarm struct {
+	Name              string            `yaml:"name"`
+	Namespace         string            `yaml:"namespace"`
+	Metric            string            `yaml:"metric"`
+	Dimensions        map[string]string `yaml:"dimensions"`
+	Statistic         string            `yaml:"statistic"`
+	Period            int32             `yaml:"period"`
+	Comparison        string            `yaml:"comparison"`
+	Threshold         float64           `yaml:"threshold"`
+	EvaluationPeriods int32             `yaml:"evaluation_periods"`
+	AlarmActions      []string          `yaml:"alarm_actions"`
+	Tags              map[string]string `yaml:"tags"`
+}
+
+// AutoScalingGroup is an AutoScaling group using a launch configuration.
+type AutoScalingGroup struct {
+	Name                string            `yaml:"name"`
+	LaunchConfiguration string            `yaml:"launch_configuration"`
+	MinSize             int32             `yaml:"min_size"`
+	MaxSize             int32             `yaml:"max_size"`
+	DesiredCapacity     *int32            `yaml:"desired_capacity"`
+	AvailabilityZones   []string          `yaml:"availability_zones"`
+	Subnets             []string          `yaml:"subnets"`
+	Tags                map[string]string `yaml:"tags"`
+}
+
+// Load reads and validates the manifest at path.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest: %w", err)
+	}
+
+	manifest := &Manifest{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	if err := manifest.validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// validate checks the required settings and fills in the defaults.
+func (m *Manifest) validate() error {
+	if m.Stack == "" {
+		return errors.New("missing stack name")
+	}
+
+	names := map[string]bool{}
+	unique := func(kind, name string) error {
+		if name == "" {
+			return fmt.Errorf("%s without a name", kind)
+		}
+		if names[kind+"/"+name] {
+			return fmt.Errorf("duplicate %s %s", kind, name)
+		}
+		names[kind+"/"+name] = true
+		return nil
+	}
+
+	for _, bucket := range m.Buckets {
+		if err := unique(KindBucket, bucket.Name); err != nil {
+			return err
+		}
+	}
+	for _, table := range m.Tables {
+		if err := unique(KindTable, table.Name); err != nil {
+			return err
+		}
+		keys := []Key{table.PartitionKey}
+		if table.SortKey != nil {
+			keys = append(keys, *table.SortKey)
+		}
+		for _, key := range keys {
+			if key.Name == "" || (key.Type != "S" && key.Type != "N" && key.Type != "B") {
+				return fmt.Errorf("table %s: keys need a name and a type among S, N and B", table.Name)
+			}
+		}
+		if (table.ReadCapacity == 0) != (table.WriteCapacity == 0) {
+			return fmt.Errorf("table %s: set both read_capacity and write_capacity, or neither for on-demand billing", table.Name)
+		}
+	}
+	for _, group := range m.LogGroups {
+		if err := unique(KindLogGroup, group.Name); err != nil {
+			return err
+		}
+	}
+	for i := range m.Alarms {
+		alarm := &m.Alarms[i]
+		if err := unique(KindAlarm, alarm.Name); err != nil {
+			return err
+		}
+		if alarm.Namespace == "" || alarm.Metric == "" || alarm.Comparison == "" || alarm.EvaluationPeriods == 0 {
+			return fmt.Errorf("alarm %s: namespace, metric, comparison and evaluation_periods are required", alarm.Name)
+		}
+		if alarm.Statistic == "" {
+			alarm.Statistic = DefaultStatistic
+		}
+		if alarm.Period == 0 {
+			alarm.Period = DefaultPeriod
+		}
+	}
+	for _, group := range m.AutoScalingGroups {
+		if err := unique(KindAutoScalingGroup, group.Name); err != nil {
+			return err
+		}
+		if group.LaunchConfiguration == "" {
+			return fmt.Errorf("AutoScaling group %s: launch_configuration is required", group.Name)
+		}
+		if group.MinSize > group.MaxSize {
+			return fmt.Errorf("AutoScaling group %s: min_size is greater than max_size", group.Name)
+		}
+	}
+	return nil
+}
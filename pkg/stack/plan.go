@@ -0,0 +1,221 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/audit"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/bulk"
+	"icp-aws-cli/pkg/dryrun"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/tagging"
+	"strings"
+)
+
+// Kinds of resources, in the order they are created. Deletions go in reverse
+// order so that e.g. alarms disappear before the groups they watch.
+const (
+	KindBucket           = "bucket"
+	KindTable            = "table"
+	KindLogGroup         = "log group"
+	KindAlarm            = "alarm"
+	KindAutoScalingGroup = "AutoScaling group"
+)
+
+// Action is the operation planned on a resource.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+func (a Action) done() string {
+	switch a {
+	case ActionCreate:
+		return "Created"
+	case ActionUpdate:
+		return "Updated"
+	default:
+		return "Deleted"
+	}
+}
+
+// Change is a planned operation on a resource.
+type Change struct {
+	Action Action
+	Kind   string
+	Name   string
+	// Details describe the settings of a new resource or the differences of an
+	// existing one, e.g. "retention: 7 -> 14 days".
+	Details []string
+
+	apply func(ctx context.Context) error
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s %s", c.Action, c.Kind, c.Name)
+}
+
+// Plan is the list of changes converging the live resources to a manifest.
+type Plan struct {
+	Stack   string
+	Changes []Change
+	// Skipped lists the live resources left out because they could not be read,
+	// e.g. those of another team that did not grant access to them.
+	Skipped []string
+}
+
+// skipFunc records a live resource that could not be read. Resources not named
+// in the manifest are skipped rather than failing the plan: without their tags
+// they cannot be told to belong to the stack.
+type skipFunc func(kind, name string, err error)
+
+type planner func(ctx context.Context, clients *awsclient.AWSClientCollection, m *Manifest, skip skipFunc) (changes, deletes []Change, err error)
+
+var planners = []planner{planBuckets, planTables, planLogGroups, planAlarms, planAutoScalingGroups}
+
+// Build compares m with the live resources and plans the changes.
+func Build(ctx context.Context, clients *awsclient.AWSClientCollection, m *Manifest) (*Plan, error) {
+	plan := &Plan{Stack: m.Stack}
+	skip := func(kind, name string, err error) {
+		plan.Skipped = append(plan.Skipped, fmt.Sprintf("%s %s: %v", kind, name, err))
+	}
+	var deletes []Change
+	for _, planner := range planners {
+		changes, kindDeletes, err := planner(ctx, clients, m, skip)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+		deletes = append(kindDeletes, deletes...)
+	}
+	plan.Changes = append(plan.Changes, deletes...)
+	return plan, nil
+}
+
+// Destroy plans the deletion of every resource owned by the stack.
+func Destroy(ctx context.Context, clients *awsclient.AWSClientCollection, stack string) (*Plan, error) {
+	return Build(ctx, clients, &Manifest{Stack: stack})
+}
+
+// Deletes returns the planned deletions.
+func (p *Plan) Deletes() []Change {
+	deletes := []Change{}
+	for _, change := range p.Changes {
+		if change.Action == ActionDelete {
+			deletes = append(deletes, change)
+		}
+	}
+	return deletes
+}
+
+// Result returns the changes for output.Print.
+func (p *Plan) Result() *output.Result {
+	result := output.NewResult("Action", "Type", "Name", "Changes")
+	for _, change := range p.Changes {
+		result.Add(string(change.Action), change.Kind, change.Name, strings.Join(change.Details, ", "))
+	}
+	return result
+}
+
+// Summary counts the changes by action.
+func (p *Plan) Summary() string {
+	counts := map[Action]int{}
+	for _, change := range p.Changes {
+		counts[change.Action]++
+	}
+	return fmt.Sprintf("Plan for stack %s: %d to create, %d to update, %d to delete",
+		p.Stack, counts[ActionCreate], counts[ActionUpdate], counts[ActionDelete])
+}
+
+// Apply performs the changes in order and stops at the first failure.
+func (p *Plan) Apply(ctx context.Context) error {
+	if dryrun.Enabled() {
+		for _, change := range p.Changes {
+			dryrun.Plan("Would %s", change)
+		}
+		return nil
+	}
+
+	labels := make([]string, len(p.Changes))
+	changes := map[string]Change{}
+	for i, change := range p.Changes {
+		labels[i] = change.String()
+		changes[labels[i]] = change
+	}
+	return bulk.Run(ctx, "applied", "change", labels, func(ctx context.Context, label string) error {
+		change := changes[label]
+		err := change.apply(ctx)
+		audit.Record(label, err)
+		if err != nil {
+			return fmt.Errorf("could not %s: %w", label, err)
+		}
+		fmt.Printf("%s %s %s\n", change.Action.done(), change.Kind, change.Name)
+		return nil
+	})
+}
+
+// checkOwner refuses to manage an existing resource owned by another stack.
+func checkOwner(m *Manifest, kind, name string, tags map[string]string) error {
+	if owner := tags[OwnerTag]; owner != "" && owner != m.Stack {
+		return fmt.Errorf("%s %s belongs to stack %s", kind, name, owner)
+	}
+	return nil
+}
+
+// ownedBy reports whether the resource with tags belongs to the stack of m.
+func ownedBy(m *Manifest, tags map[string]string) bool {
+	return tags[OwnerTag] == m.Stack
+}
+
+// desiredTags returns the tags of a resource of the stack, including OwnerTag.
+func desiredTags(m *Manifest, tags map[string]string) map[string]string {
+	desired := map[string]string{OwnerTag: m.Stack}
+	for key, value := range tags {
+		desired[key] = value
+	}
+	return desired
+}
+
+func createChange(kind, name string, tags map[string]string, details []string, apply func(ctx context.Context) error) Change {
+	if len(tags) > 0 {
+		details = append(details, "tags "+strings.Join(tagging.Format(tags), " "))
+	}
+	return Change{Action: ActionCreate, Kind: kind, Name: name, Details: details, apply: apply}
+}
+
+func deleteChange(kind, name string, apply func(ctx context.Context) error) Change {
+	return Change{Action: ActionDelete, Kind: kind, Name: name, apply: apply}
+}
+
+// updateChange plans the update of an existing resource. details and update
+// cover its settings while the tags are compared here: only the tags of the
+// manifest and OwnerTag are reconciled, the tags set by other teams and tools
+// are left alone. ok is false when the resource is up to date.
+func updateChange(kind, name string, tagger tagging.Tagger, current, desired map[string]string, details []string, update func(ctx context.Context) error) (change Change, ok bool) {
+	add := tagging.Diff(current, desired)
+	for _, key := range tagging.Keys(add) {
+		if old, ok := current[key]; ok {
+			details = append(details, fmt.Sprintf("tag %s: %s -> %s", key, old, add[key]))
+		} else {
+			details = append(details, fmt.Sprintf("tag %s=%s", key, add[key]))
+		}
+	}
+	if len(details) == 0 {
+		return Change{}, false
+	}
+
+	return Change{Action: ActionUpdate, Kind: kind, Name: name, Details: details, apply: func(ctx context.Context) error {
+		if update != nil {
+			if err := update(ctx); err != nil {
+				return err
+			}
+		}
+		if len(add) > 0 {
+			return tagger.Add(ctx, name, add)
+		}
+		return nil
+	}}, true
+}
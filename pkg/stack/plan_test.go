@@ -0,0 +1,301 @@
+package stack
+
+import (
+	"context"
+	"icp-aws-cli/pkg/awsclient/fake"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	astypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	logstypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+var accessDenied = &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"}
+
+// liveBuckets configures the fake with buckets and their tags; a nil tag set
+// cannot be read.
+func liveBuckets(clients *fake.Clients, buckets map[string]map[string]string) {
+	clients.S3.ListBucketsFunc = func(context.Context, *s3.ListBucketsInput) (*s3.ListBucketsOutput, error) {
+		out := &s3.ListBucketsOutput{}
+		for _, name := range sortedKeys(buckets) {
+			out.Buckets = append(out.Buckets, s3types.Bucket{Name: aws.String(name)})
+		}
+		return out, nil
+	}
+	clients.S3.GetBucketTaggingFunc = func(_ context.Context, in *s3.GetBucketTaggingInput) (*s3.GetBucketTaggingOutput, error) {
+		tags := buckets[aws.ToString(in.Bucket)]
+		if tags == nil {
+			return nil, accessDenied
+		}
+		out := &s3.GetBucketTaggingOutput{}
+		for _, key := range sortedKeys(tags) {
+			out.TagSet = append(out.TagSet, s3types.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+		}
+		return out, nil
+	}
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// describe summarises the changes of a plan as "action kind name: details".
+func describe(plan *Plan) []string {
+	lines := []string{}
+	for _, change := range plan.Changes {
+		line := change.String()
+		if len(change.Details) > 0 {
+			line += ": " + strings.Join(change.Details, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestBuildBuckets(t *testing.T) {
+	tests := []struct {
+		name        string
+		live        map[string]map[string]string
+		manifest    []Bucket
+		want        []string
+		wantSkipped []string
+		wantErr     string
+	}{
+		{
+			name:     "create",
+			live:     map[string]map[string]string{},
+			manifest: []Bucket{{Name: "web-assets", Tags: map[string]string{"Team": "web"}}},
+			want:     []string{"create bucket web-assets: tags Team=web"},
+		},
+		{
+			name:     "adopt keeps foreign tags",
+			live:     map[string]map[string]string{"web-assets": {"CostCenter": "42", "Team": "old", "aws:backup": "daily"}},
+			manifest: []Bucket{{Name: "web-assets", Tags: map[string]string{"Team": "web"}}},
+			want:     []string{"update bucket web-assets: tag Team: old -> web, tag icp-aws-cli:stack=demo"},
+		},
+		{
+			name:     "up to date with foreign tags",
+			live:     map[string]map[string]string{"web-assets": {OwnerTag: "demo", "Team": "web", "Backup": "weekly"}},
+			manifest: []Bucket{{Name: "web-assets", Tags: map[string]string{"Team": "web"}}},
+			want:     []string{},
+		},
+		{
+			name:     "tag removed from the manifest is kept",
+			live:     map[string]map[string]string{"web-assets": {OwnerTag: "demo", "Team": "web"}},
+			manifest: []Bucket{{Name: "web-assets"}},
+			want:     []string{},
+		},
+		{
+			name:     "owned by another stack",
+			live:     map[string]map[string]string{"web-assets": {OwnerTag: "other"}},
+			manifest: []Bucket{{Name: "web-assets"}},
+			wantErr:  "bucket web-assets belongs to stack other",
+		},
+		{
+			name: "delete removed from the manifest",
+			live: map[string]map[string]string{"old-assets": {OwnerTag: "demo"}, "team-logs": {OwnerTag: "other"}, "untagged": {}},
+			want: []string{"delete bucket old-assets"},
+		},
+		{
+			name:        "unreadable bucket of another team is skipped",
+			live:        map[string]map[string]string{"locked": nil, "old-assets": {OwnerTag: "demo"}},
+			want:        []string{"delete bucket old-assets"},
+			wantSkipped: []string{"bucket locked: could not get tags of bucket locked: api error AccessDenied: Access Denied"},
+		},
+		{
+			name:     "unreadable bucket of the manifest fails",
+			live:     map[string]map[string]string{"web-assets": nil},
+			manifest: []Bucket{{Name: "web-assets"}},
+			wantErr:  "could not get tags of bucket web-assets",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := fake.New()
+			liveBuckets(clients, tt.live)
+			collection := clients.Collection()
+			collection.Region = "us-east-1"
+
+			plan, err := Build(context.Background(), collection, &Manifest{Stack: "demo", Buckets: tt.manifest})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Build() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got := describe(plan); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(plan.Skipped, tt.wantSkipped) {
+				t.Errorf("Skipped = %q, want %q", plan.Skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestApplyKeepsForeignTags(t *testing.T) {
+	clients := fake.New()
+	liveBuckets(clients, map[string]map[string]string{"web-assets": {"CostCenter": "42", "Team": "old"}})
+	var put []s3types.Tag
+	clients.S3.PutBucketTaggingFunc = func(_ context.Context, in *s3.PutBucketTaggingInput) (*s3.PutBucketTaggingOutput, error) {
+		put = in.Tagging.TagSet
+		return &s3.PutBucketTaggingOutput{}, nil
+	}
+	collection := clients.Collection()
+	collection.Region = "us-east-1"
+
+	plan, err := Build(context.Background(), collection, &Manifest{Stack: "demo", Buckets: []Bucket{{Name: "web-assets", Tags: map[string]string{"Team": "web"}}}})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if err := plan.Apply(context.Background()); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	got := map[string]string{}
+	for _, tag := range put {
+		got[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	want := map[string]string{"CostCenter": "42", "Team": "web", OwnerTag: "demo"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+	if operations := clients.S3.Operations(); strings.Contains(strings.Join(operations, " "), "DeleteBucketTagging") {
+		t.Errorf("operations = %v, want no tag removal", operations)
+	}
+}
+
+func TestBuildLogGroupsAndAlarms(t *testing.T) {
+	clients := fake.New()
+	clients.CloudWatchLogs.DescribeLogGroupsFunc = func(context.Context, *cloudwatchlogs.DescribeLogGroupsInput) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+		return &cloudwatchlogs.DescribeLogGroupsOutput{LogGroups: []logstypes.LogGroup{
+			{LogGroupName: aws.String("/web/app"), Arn: aws.String("arn:aws:logs:us-east-1:1:log-group:/web/app:*"), RetentionInDays: aws.Int32(7)},
+			{LogGroupName: aws.String("/locked"), Arn: aws.String("arn:aws:logs:us-east-1:1:log-group:/locked:*")},
+		}}, nil
+	}
+	clients.CloudWatchLogs.ListTagsForResourceFunc = func(_ context.Context, in *cloudwatchlogs.ListTagsForResourceInput) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+		if strings.Contains(aws.ToString(in.ResourceArn), "locked") {
+			return nil, accessDenied
+		}
+		return &cloudwatchlogs.ListTagsForResourceOutput{Tags: map[string]string{OwnerTag: "demo"}}, nil
+	}
+	clients.CloudWatch.DescribeAlarmsFunc = func(context.Context, *cloudwatch.DescribeAlarmsInput) (*cloudwatch.DescribeAlarmsOutput, error) {
+		return &cloudwatch.DescribeAlarmsOutput{MetricAlarms: []cwtypes.MetricAlarm{{
+			AlarmName: aws.String("web-5xx"), AlarmArn: aws.String("arn:alarm:web-5xx"),
+			Namespace: aws.String("AWS/ApplicationELB"), MetricName: aws.String("HTTPCode_Target_5XX_Count"),
+			Statistic: cwtypes.StatisticSum, Period: aws.Int32(60), ComparisonOperator: cwtypes.ComparisonOperatorGreaterThanThreshold,
+			Threshold: aws.Float64(10), EvaluationPeriods: aws.Int32(1),
+		}}}, nil
+	}
+	clients.CloudWatch.ListTagsForResourceFunc = func(context.Context, *cloudwatch.ListTagsForResourceInput) (*cloudwatch.ListTagsForResourceOutput, error) {
+		return &cloudwatch.ListTagsForResourceOutput{Tags: []cwtypes.Tag{{Key: aws.String(OwnerTag), Value: aws.String("demo")}}}, nil
+	}
+
+	plan, err := Build(context.Background(), clients.Collection(), &Manifest{
+		Stack:     "demo",
+		LogGroups: []LogGroup{{Name: "/web/app", RetentionDays: 14}},
+		Alarms: []Alarm{{
+			Name: "web-5xx", Namespace: "AWS/ApplicationELB", Metric: "HTTPCode_Target_5XX_Count",
+			Statistic: "Sum", Period: 60, Comparison: "GreaterThanThreshold", Threshold: 20, EvaluationPeriods: 1,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	want := []string{
+		"update log group /web/app: retention: 7 days -> 14 days",
+		"update alarm web-5xx: condition: Sum AWS/ApplicationELB/HTTPCode_Target_5XX_Count GreaterThanThreshold 10 for 1 x 60s -> Sum AWS/ApplicationELB/HTTPCode_Target_5XX_Count GreaterThanThreshold 20 for 1 x 60s",
+	}
+	if got := describe(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+	if len(plan.Skipped) != 1 || !strings.HasPrefix(plan.Skipped[0], "log group /locked: ") {
+		t.Errorf("Skipped = %q, want the locked log group", plan.Skipped)
+	}
+}
+
+func TestDestroyOrder(t *testing.T) {
+	clients := fake.New()
+	liveBuckets(clients, map[string]map[string]string{"demo-assets": {OwnerTag: "demo"}})
+	clients.AutoScaling.DescribeAutoScalingGroupsFunc = func(context.Context, *autoscaling.DescribeAutoScalingGroupsInput) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+		return &autoscaling.DescribeAutoScalingGroupsOutput{AutoScalingGroups: []astypes.AutoScalingGroup{
+			{AutoScalingGroupName: aws.String("demo-web"), Tags: []astypes.TagDescription{{Key: aws.String(OwnerTag), Value: aws.String("demo")}}},
+			{AutoScalingGroupName: aws.String("other-web")},
+		}}, nil
+	}
+	collection := clients.Collection()
+	collection.Region = "us-east-1"
+
+	plan, err := Destroy(context.Background(), collection, "demo")
+	if err != nil {
+		t.Fatalf("Destroy() error = %v", err)
+	}
+	// Deletions go in reverse order of creation
+	want := []string{"delete AutoScaling group demo-web", "delete bucket demo-assets"}
+	if got := describe(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+	if got := plan.Summary(); got != "Plan for stack demo: 0 to create, 0 to update, 2 to delete" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestGroupUpdate(t *testing.T) {
+	current := astypes.AutoScalingGroup{
+		LaunchConfigurationName: aws.String("web-v1"),
+		MinSize:                 aws.Int32(1),
+		MaxSize:                 aws.Int32(3),
+		DesiredCapacity:         aws.Int32(2),
+		AvailabilityZones:       []string{"us-east-1a", "us-east-1b"},
+		VPCZoneIdentifier:       aws.String("subnet-1,subnet-2"),
+	}
+	tests := []struct {
+		name  string
+		group AutoScalingGroup
+		want  []string
+	}{
+		{
+			name:  "up to date, zones in another order",
+			group: AutoScalingGroup{LaunchConfiguration: "web-v1", MinSize: 1, MaxSize: 3, AvailabilityZones: []string{"us-east-1b", "us-east-1a"}},
+		},
+		{
+			name:  "desired capacity only compared when set",
+			group: AutoScalingGroup{LaunchConfiguration: "web-v1", MinSize: 1, MaxSize: 3, DesiredCapacity: aws.Int32(3)},
+			want:  []string{"desired: 2 -> 3"},
+		},
+		{
+			name:  "launch configuration, size and subnets",
+			group: AutoScalingGroup{LaunchConfiguration: "web-v2", MinSize: 2, MaxSize: 4, Subnets: []string{"subnet-3"}},
+			want:  []string{"launch configuration: web-v1 -> web-v2", "size: 1-3 -> 2-4", "subnets: subnet-1,subnet-2 -> subnet-3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, input := groupUpdate(current, tt.group)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupUpdate() = %q, want %q", got, tt.want)
+			}
+			if (input == nil) != (tt.want == nil) {
+				t.Errorf("groupUpdate() input = %v, want an input only with differences", input)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"icp-aws-cli/pkg/tagging"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type liveTable struct {
+	description *types.TableDescription
+	tags        map[string]string
+}
+
+func planTables(ctx context.Context, clients *awsclient.AWSClientCollection, m *Manifest, skip skipFunc) ([]Change, []Change, error) {
+	wanted := map[string]bool{}
+	for _, table := range m.Tables {
+		wanted[table.Name] = true
+	}
+
+	names := []string{}
+	paginator := dynamodb.NewListTablesPaginator(clients.DynamoDB, &dynamodb.ListTablesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not list tables: %w", err)
+		}
+		names = append(names, page.TableNames...)
+	}
+	tagger := tagging.Tables(clients.DynamoDB)
+	live := map[string]liveTable{}
+	for _, name := range names {
+		out, err := clients.DynamoDB.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
+		if err != nil {
+			err = fmt.Errorf("could not describe table %s: %w", name, err)
+		}
+		var tags map[string]string
+		if err == nil {
+			tags, err = tagger.Get(ctx, name)
+		}
+		if err != nil {
+			if wanted[name] {
+				return nil, nil, err
+			}
+			skip(KindTable, name, err)
+			continue
+		}
+		live[name] = liveTable{description: out.Table, tags: tags}
+	}
+
+	changes, deletes := []Change{}, []Change{}
+	for _, table := range m.Tables {
+		desired := desiredTags(m, table.Tags)
+		current, ok := live[table.Name]
+		if !ok {
+			changes = append(changes, createChange(KindTable, table.Name, table.Tags, tableSettings(table), func(ctx context.Context) error {
+				return createTable(ctx, clients.DynamoDB, table, desired)
+			}))
+			continue
+		}
+		if err := checkOwner(m, KindTable, table.Name, current.tags); err != nil {
+			return nil, nil, err
+		}
+		if err := checkKeys(table, current.description); err != nil {
+			return nil, nil, err
+		}
+		details, update := billingUpdate(clients.DynamoDB, table, current.description)
+		if change, ok := updateChange(KindTable, table.Name, tagger, current.tags, desired, details, update); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	for _, name := range names {
+		if wanted[name] || !ownedBy(m, live[name].tags) {
+			continue
+		}
+		deletes = append(deletes, deleteChange(KindTable, name, func(ctx context.Context) error {
+			_, err := clients.DynamoDB.DeleteTable(ctx, &dynamodb.DeleteTableInput{TableName: aws.String(name)})
+			return err
+		}))
+	}
+	return changes, deletes, nil
+}
+
+func tableKeys(table Table) ([]types.AttributeDefinition, []types.KeySchemaElement) {
+	attributes := []types.AttributeDefinition{{
+		AttributeName: aws.String(table.PartitionKey.Name),
+		AttributeType: types.ScalarAttributeType(table.PartitionKey.Type),
+	}}
+	schema := []types.KeySchemaElement{{AttributeName: aws.String(table.PartitionKey.Name), KeyType: types.KeyTypeHash}}
+	if table.SortKey != nil {
+		attributes = append(attributes, types.AttributeDefinition{
+			AttributeName: aws.String(table.SortKey.Name),
+			AttributeType: types.ScalarAttributeType(table.SortKey.Type),
+		})
+		schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(table.SortKey.Name), KeyType: types.KeyTypeRange})
+	}
+	return attributes, schema
+}
+
+func tableSettings(table Table) []string {
+	settings := []string{fmt.Sprintf("key %s (%s)", table.PartitionKey.Name, table.PartitionKey.Type)}
+	if table.SortKey != nil {
+		settings = append(settings, fmt.Sprintf("sort key %s (%s)", table.SortKey.Name, table.SortKey.Type))
+	}
+	return append(settings, "billing "+billing(table.OnDemand(), table.ReadCapacity, table.WriteCapacity))
+}
+
+func billing(onDemand bool, read, write int64) string {
+	if onDemand {
+		return "on-demand"
+	}
+	return fmt.Sprintf("provisioned %d/%d", read, write)
+}
+
+func createTable(ctx context.Context, dbClient awsclient.DynamoDBAPI, table Table, tags map[string]string) error {
+	attributes, schema := tableKeys(table)
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(table.Name),
+		AttributeDefinitions: attributes,
+		KeySchema:            schema,
+		BillingMode:          types.BillingModePayPerRequest,
+	}
+	if !table.OnDemand() {
+		input.BillingMode = types.BillingModeProvisioned
+		input.ProvisionedThroughput = &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(table.ReadCapacity),
+			WriteCapacityUnits: aws.Int64(table.WriteCapacity),
+		}
+	}
+	for _, key := range tagging.Keys(tags) {
+		input.Tags = append(input.Tags, types.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+	_, err := dbClient.CreateTable(ctx, input)
+	return err
+}
+
+// checkKeys fails when the key schema of the table differs from the manifest, as
+// it cannot be changed without recreating the table and losing its items.
+func checkKeys(table Table, description *types.TableDescription) error {
+	attributes, schema := tableKeys(table)
+	desired := describeKeys(attributes, schema)
+	current := describeKeys(description.AttributeDefinitions, description.KeySchema)
+	if current != desired {
+		return fmt.Errorf("table %s has the key schema %s instead of %s; keys cannot be changed, delete the table first", table.Name, current, desired)
+	}
+	return nil
+}
+
+// describeKeys formats a key schema, e.g. "HASH id (S), RANGE created (N)".
+func describeKeys(attributes []types.AttributeDefinition, schema []types.KeySchemaElement) string {
+	attributeTypes := map[string]types.ScalarAttributeType{}
+	for _, attribute := range attributes {
+		attributeTypes[aws.ToString(attribute.AttributeName)] = attribute.AttributeType
+	}
+	keys := []string{}
+	for _, key := range schema {
+		name := aws.ToString(key.AttributeName)
+		keys = append(keys, fmt.Sprintf("%s %s (%s)", key.KeyType, name, attributeTypes[name]))
+	}
+	return strings.Join(keys, ", ")
+}
+
+// billingUpdate compares the billing mode and capacity of the table with the manifest.
+func billingUpdate(dbClient awsclient.DynamoDBAPI, table Table, description *types.TableDescription) ([]string, func(ctx context.Context) error) {
+	// Tables created before on-demand billing existed have no billing summary
+	onDemand := description.BillingModeSummary != nil && description.BillingModeSummary.BillingMode == types.BillingModePayPerRequest
+	var read, write int64
+	if description.ProvisionedThroughput != nil {
+		read = aws.ToInt64(description.ProvisionedThroughput.ReadCapacityUnits)
+		write = aws.ToInt64(description.ProvisionedThroughput.WriteCapacityUnits)
+	}
+	if onDemand == table.OnDemand() && (onDemand || (read == table.ReadCapacity && write == table.WriteCapacity)) {
+		return nil, nil
+	}
+
+	details := []string{fmt.Sprintf("billing: %s -> %s", billing(onDemand, read, write), billing(table.OnDemand(), table.ReadCapacity, table.WriteCapacity))}
+	return details, func(ctx context.Context) error {
+		input := &dynamodb.UpdateTableInput{
+			TableName:   aws.String(table.Name),
+			BillingMode: types.BillingModePayPerRequest,
+		}
+		if !table.OnDemand() {
+			input.BillingMode = types.BillingModeProvisioned
+			input.ProvisionedThroughput = &types.ProvisionedThroughput{
+				ReadCapacityUnits:  aws.Int64(table.ReadCapacity),
+				WriteCapacityUnits: aws.Int64(table.WriteCapacity),
+			}
+		}
+		_, err := dbClient.UpdateTable(ctx, input)
+		return err
+	}
+}
@@ -0,0 +1,109 @@
+// Package stdin shares standard input between the prompts and the shell. At
+// most one read of os.Stdin is in progress and what it returns is kept for the
+// next reader, so a prompt abandoned on cancellation does not leave a reader
+// behind that swallows the next command line.
+package stdin
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	mu       sync.Mutex
+	buffered []byte
+	// readErr is returned once buffered is consumed, e.g. io.EOF after Ctrl-D.
+	readErr error
+	// reading is closed when the read in progress, if any, completes.
+	reading chan struct{}
+)
+
+// Reader reads standard input through the shared buffer.
+var Reader io.Reader = reader{}
+
+type reader struct{}
+
+func (reader) Read(p []byte) (int, error) {
+	mu.Lock()
+	for len(buffered) == 0 && readErr == nil {
+		done := fill()
+		mu.Unlock()
+		<-done
+		mu.Lock()
+	}
+	defer mu.Unlock()
+	if len(buffered) == 0 {
+		return 0, takeErr()
+	}
+	n := copy(p, buffered)
+	buffered = buffered[n:]
+	return n, nil
+}
+
+// ReadLine returns the next line of standard input without its line ending.
+// Cancelling ctx abandons the line; the input already typed is kept for the
+// next reader.
+func ReadLine(ctx context.Context) (string, error) {
+	mu.Lock()
+	for {
+		if i := bytes.IndexByte(buffered, '\n'); i >= 0 {
+			line := string(buffered[:i])
+			buffered = buffered[i+1:]
+			mu.Unlock()
+			return strings.TrimSuffix(line, "\r"), nil
+		}
+		if readErr != nil {
+			// A final line without a line ending comes before the error
+			line := string(buffered)
+			buffered = nil
+			var err error
+			if line == "" {
+				err = takeErr()
+			}
+			mu.Unlock()
+			return line, err
+		}
+
+		done := fill()
+		mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-done:
+		}
+		mu.Lock()
+	}
+}
+
+// fill starts reading os.Stdin unless a read is already in progress, and
+// returns a channel closed when it completes. mu must be held.
+func fill() chan struct{} {
+	if reading == nil {
+		reading = make(chan struct{})
+		go func(done chan struct{}) {
+			chunk := make([]byte, 4096)
+			n, err := os.Stdin.Read(chunk)
+			mu.Lock()
+			buffered = append(buffered, chunk[:n]...)
+			if err != nil {
+				readErr = err
+			}
+			reading = nil
+			mu.Unlock()
+			close(done)
+		}(reading)
+	}
+	return reading
+}
+
+// takeErr returns the pending read error and clears it, as a terminal can
+// still be read after Ctrl-D. mu must be held.
+func takeErr() error {
+	err := readErr
+	readErr = nil
+	return err
+}
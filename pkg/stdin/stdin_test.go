@@ -0,0 +1,75 @@
+package stdin
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// pipeStdin replaces os.Stdin with a pipe and returns its write end.
+func pipeStdin(t *testing.T) *os.File {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = original
+		w.Close()
+		r.Close()
+	})
+	return w
+}
+
+func TestReadLine(t *testing.T) {
+	w := pipeStdin(t)
+	go func() {
+		io.WriteString(w, "yes\r\nweb-db\nlast")
+		w.Close()
+	}()
+
+	tests := []struct {
+		want    string
+		wantErr error
+	}{
+		{want: "yes"},
+		{want: "web-db"},
+		{want: "last"},
+		{wantErr: io.EOF},
+	}
+	for _, tt := range tests {
+		got, err := ReadLine(context.Background())
+		if got != tt.want || err != tt.wantErr {
+			t.Errorf("ReadLine() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCancelledReadLineKeepsInput(t *testing.T) {
+	w := pipeStdin(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := ReadLine(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("ReadLine() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The read left in progress by the abandoned prompt must hand its input
+	// over to the next reader, e.g. the shell
+	io.WriteString(w, "ec2 list --all\n")
+	got, err := ReadLine(context.Background())
+	if err != nil || got != "ec2 list --all" {
+		t.Errorf("ReadLine() = %q, %v, want %q", got, err, "ec2 list --all")
+	}
+
+	io.WriteString(w, "exit\n")
+	buf := make([]byte, 64)
+	n, err := Reader.Read(buf)
+	if err != nil || string(buf[:n]) != "exit\n" {
+		t.Errorf("Read() = %q, %v, want %q", buf[:n], err, "exit\n")
+	}
+}
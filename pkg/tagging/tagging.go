@@ -0,0 +1,578 @@
+// Package tagging reads and changes the tags of resources identified by name,
+// hiding the differences between the tagging APIs of each service.
+package tagging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"icp-aws-cli/pkg/awsclient"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	astypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	logstypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// ReservedPrefix starts the keys of tags set by AWS, which cannot be changed.
+const ReservedPrefix = "aws:"
+
+// ErrNotFound is returned by taggers when the named resource does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Tagger manages the tags of one kind of resource.
+type Tagger interface {
+	Get(ctx context.Context, name string) (map[string]string, error)
+	// Add creates the tags or updates their values.
+	Add(ctx context.Context, name string, tags map[string]string) error
+	Remove(ctx context.Context, name string, keys []string) error
+}
+
+// Diff returns the tags of desired that current lacks or holds with another
+// value. Keys missing from desired are not removed: other teams and tools, e.g.
+// cost allocation or backups, tag the same resources.
+func Diff(current, desired map[string]string) map[string]string {
+	add := map[string]string{}
+	for key, value := range desired {
+		if old, ok := current[key]; !ok || old != value {
+			add[key] = value
+		}
+	}
+	return add
+}
+
+// Keys returns the sorted keys of tags.
+func Keys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Format returns the tags as sorted key=value pairs.
+func Format(tags map[string]string) []string {
+	pairs := make([]string, 0, len(tags))
+	for _, key := range Keys(tags) {
+		pairs = append(pairs, key+"="+tags[key])
+	}
+	return pairs
+}
+
+func isErrorCode(err error, code string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
+
+// Buckets manages the tags of S3 buckets. S3 replaces the whole tag set at once.
+func Buckets(client awsclient.S3API) Tagger {
+	return buckets{client}
+}
+
+type buckets struct {
+	client awsclient.S3API
+}
+
+func (b buckets) Get(ctx context.Context, name string) (map[string]string, error) {
+	out, err := b.client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: aws.String(name)})
+	if isErrorCode(err, "NoSuchTagSet") {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get tags of bucket %s: %w", name, err)
+	}
+	tags := map[string]string{}
+	for _, tag := range out.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+func (b buckets) Add(ctx context.Context, name string, tags map[string]string) error {
+	current, err := b.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	for key, value := range tags {
+		current[key] = value
+	}
+	return b.put(ctx, name, current)
+}
+
+func (b buckets) Remove(ctx context.Context, name string, keys []string) error {
+	current, err := b.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		delete(current, key)
+	}
+	return b.put(ctx, name, current)
+}
+
+func (b buckets) put(ctx context.Context, name string, tags map[string]string) error {
+	if len(tags) == 0 {
+		if _, err := b.client.DeleteBucketTagging(ctx, &s3.DeleteBucketTaggingInput{Bucket: aws.String(name)}); err != nil {
+			return fmt.Errorf("could not delete tags of bucket %s: %w", name, err)
+		}
+		return nil
+	}
+	tagSet := []s3types.Tag{}
+	for key, value := range tags {
+		tagSet = append(tagSet, s3types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	if _, err := b.client.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+		Bucket:  aws.String(name),
+		Tagging: &s3types.Tagging{TagSet: tagSet},
+	}); err != nil {
+		return fmt.Errorf("could not tag bucket %s: %w", name, err)
+	}
+	return nil
+}
+
+// Tables manages the tags of DynamoDB tables.
+func Tables(client awsclient.DynamoDBAPI) Tagger {
+	return tables{client}
+}
+
+type tables struct {
+	client awsclient.DynamoDBAPI
+}
+
+func (t tables) arn(ctx context.Context, name string) (*string, error) {
+	out, err := t.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
+	if err != nil {
+		return nil, fmt.Errorf("could not describe table %s: %w", name, err)
+	}
+	return out.Table.TableArn, nil
+}
+
+func (t tables) Get(ctx context.Context, name string) (map[string]string, error) {
+	arn, err := t.arn(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	input := &dynamodb.ListTagsOfResourceInput{ResourceArn: arn}
+	for {
+		out, err := t.client.ListTagsOfResource(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("could not get tags of table %s: %w", name, err)
+		}
+		for _, tag := range out.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		if out.NextToken == nil {
+			return tags, nil
+		}
+		input.NextToken = out.NextToken
+	}
+}
+
+func (t tables) Add(ctx context.Context, name string, tags map[string]string) error {
+	arn, err := t.arn(ctx, name)
+	if err != nil {
+		return err
+	}
+	tagList := []dbtypes.Tag{}
+	for key, value := range tags {
+		tagList = append(tagList, dbtypes.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	if _, err := t.client.TagResource(ctx, &dynamodb.TagResourceInput{ResourceArn: arn, Tags: tagList}); err != nil {
+		return fmt.Errorf("could not tag table %s: %w", name, err)
+	}
+	return nil
+}
+
+func (t tables) Remove(ctx context.Context, name string, keys []string) error {
+	arn, err := t.arn(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err := t.client.UntagResource(ctx, &dynamodb.UntagResourceInput{ResourceArn: arn, TagKeys: keys}); err != nil {
+		return fmt.Errorf("could not untag table %s: %w", name, err)
+	}
+	return nil
+}
+
+// LogGroups manages the tags of CloudWatch log groups.
+func LogGroups(client awsclient.CloudWatchLogsAPI) Tagger {
+	return logGroups{client}
+}
+
+type logGroups struct {
+	client awsclient.CloudWatchLogsAPI
+}
+
+// LogGroupARN returns the ARN of group in the form expected by the tagging
+// operations, i.e. without the trailing ":*".
+func LogGroupARN(group logstypes.LogGroup) *string {
+	if group.LogGroupArn != nil {
+		return group.LogGroupArn
+	}
+	return aws.String(strings.TrimSuffix(aws.ToString(group.Arn), ":*"))
+}
+
+func (l logGroups) arn(ctx context.Context, name string) (*string, error) {
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(l.client, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(name),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not describe log group %s: %w", name, err)
+		}
+		for _, group := range page.LogGroups {
+			if aws.ToString(group.LogGroupName) == name {
+				return LogGroupARN(group), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("log group %s %w", name, ErrNotFound)
+}
+
+func (l logGroups) Get(ctx context.Context, name string) (map[string]string, error) {
+	arn, err := l.arn(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	out, err := l.client.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{ResourceArn: arn})
+	if err != nil {
+		return nil, fmt.Errorf("could not get tags of log group %s: %w", name, err)
+	}
+	tags := map[string]string{}
+	for key, value := range out.Tags {
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+func (l logGroups) Add(ctx context.Context, name string, tags map[string]string) error {
+	arn, err := l.arn(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err := l.client.TagResource(ctx, &cloudwatchlogs.TagResourceInput{ResourceArn: arn, Tags: tags}); err != nil {
+		return fmt.Errorf("could not tag log group %s: %w", name, err)
+	}
+	return nil
+}
+
+func (l logGroups) Remove(ctx context.Context, name string, keys []string) error {
+	arn, err := l.arn(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err := l.client.UntagResource(ctx, &cloudwatchlogs.UntagResourceInput{ResourceArn: arn, TagKeys: keys}); err != nil {
+		return fmt.Errorf("could not untag log group %s: %w", name, err)
+	}
+	return nil
+}
+
+// Alarms manages the tags of CloudWatch alarms.
+func Alarms(client awsclient.CloudWatchAPI) Tagger {
+	return alarms{client}
+}
+
+type alarms struct {
+	client awsclient.CloudWatchAPI
+}
+
+func (a alarms) arn(ctx context.Context, name string) (*string, error) {
+	out, err := a.client.DescribeAlarms(ctx, &cloudwatch.DescribeAlarmsInput{
+		AlarmNames: []string{name},
+		AlarmTypes: []cwtypes.AlarmType{cwtypes.AlarmTypeMetricAlarm, cwtypes.AlarmTypeCompositeAlarm},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not describe alarm %s: %w", name, err)
+	}
+	for _, alarm := range out.MetricAlarms {
+		return alarm.AlarmArn, nil
+	}
+	for _, alarm := range out.CompositeAlarms {
+		return alarm.AlarmArn, nil
+	}
+	return nil, fmt.Errorf("alarm %s %w", name, ErrNotFound)
+}
+
+func (a alarms) Get(ctx context.Context, name string) (map[string]string, error) {
+	arn, err := a.arn(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	out, err := a.client.ListTagsForResource(ctx, &cloudwatch.ListTagsForResourceInput{ResourceARN: arn})
+	if err != nil {
+		return nil, fmt.Errorf("could not get tags of alarm %s: %w", name, err)
+	}
+	tags := map[string]string{}
+	for _, tag := range out.Tags {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+func (a alarms) Add(ctx context.Context, name string, tags map[string]string) error {
+	arn, err := a.arn(ctx, name)
+	if err != nil {
+		return err
+	}
+	tagList := []cwtypes.Tag{}
+	for key, value := range tags {
+		tagList = append(tagList, cwtypes.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	if _, err := a.client.TagResource(ctx, &cloudwatch.TagResourceInput{ResourceARN: arn, Tags: tagList}); err != nil {
+		return fmt.Errorf("could not tag alarm %s: %w", name, err)
+	}
+	return nil
+}
+
+func (a alarms) Remove(ctx context.Context, name string, keys []string) error {
+	arn, err := a.arn(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err := a.client.UntagResource(ctx, &cloudwatch.UntagResourceInput{ResourceARN: arn, TagKeys: keys}); err != nil {
+		return fmt.Errorf("could not untag alarm %s: %w", name, err)
+	}
+	return nil
+}
+
+// Groups manages the tags of AutoScaling groups. Updated tags keep their
+// PropagateAtLaunch setting; new tags are not propagated to instances.
+func Groups(client awsclient.AutoScalingAPI) Tagger {
+	return groups{client}
+}
+
+type groups struct {
+	client awsclient.AutoScalingAPI
+}
+
+func (g groups) describe(ctx context.Context, name string) ([]astypes.TagDescription, error) {
+	out, err := g.client.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []string{name},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not describe AutoScaling group %s: %w", name, err)
+	}
+	if len(out.AutoScalingGroups) == 0 {
+		return nil, fmt.Errorf("AutoScaling group %s %w", name, ErrNotFound)
+	}
+	return out.AutoScalingGroups[0].Tags, nil
+}
+
+func (g groups) Get(ctx context.Context, name string) (map[string]string, error) {
+	descriptions, err := g.describe(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	for _, tag := range descriptions {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+func (g groups) Add(ctx context.Context, name string, tags map[string]string) error {
+	descriptions, err := g.describe(ctx, name)
+	if err != nil {
+		return err
+	}
+	propagate := map[string]bool{}
+	for _, tag := range descriptions {
+		propagate[aws.ToString(tag.Key)] = aws.ToBool(tag.PropagateAtLaunch)
+	}
+	tagList := []astypes.Tag{}
+	for key, value := range tags {
+		tagList = append(tagList, groupTag(name, key, value, propagate[key]))
+	}
+	if _, err := g.client.CreateOrUpdateTags(ctx, &autoscaling.CreateOrUpdateTagsInput{Tags: tagList}); err != nil {
+		return fmt.Errorf("could not tag AutoScaling group %s: %w", name, err)
+	}
+	return nil
+}
+
+func (g groups) Remove(ctx context.Context, name string, keys []string) error {
+	tagList := []astypes.Tag{}
+	for _, key := range keys {
+		tagList = append(tagList, astypes.Tag{
+			ResourceId:   aws.String(name),
+			ResourceType: aws.String("auto-scaling-group"),
+			Key:          aws.String(key),
+		})
+	}
+	if _, err := g.client.DeleteTags(ctx, &autoscaling.DeleteTagsInput{Tags: tagList}); err != nil {
+		return fmt.Errorf("could not untag AutoScaling group %s: %w", name, err)
+	}
+	return nil
+}
+
+// GroupTags converts tags to the AutoScaling representation, e.g. to create a group.
+func GroupTags(name string, tags map[string]string) []astypes.Tag {
+	tagList := []astypes.Tag{}
+	for _, key := range Keys(tags) {
+		tagList = append(tagList, groupTag(name, key, tags[key], false))
+	}
+	return tagList
+}
+
+func groupTag(name, key, value string, propagate bool) astypes.Tag {
+	return astypes.Tag{
+		ResourceId:        aws.String(name),
+		ResourceType:      aws.String("auto-scaling-group"),
+		Key:               aws.String(key),
+		Value:             aws.String(value),
+		PropagateAtLaunch: aws.Bool(propagate),
+	}
+}
+
+// Instances manages the tags of EC2 instances, identified by instance ID.
+func Instances(client awsclient.EC2API) Tagger {
+	return instances{client}
+}
+
+type instances struct {
+	client awsclient.EC2API
+}
+
+func (i instances) Get(ctx context.Context, id string) (map[string]string, error) {
+	out, err := i.client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{id}})
+	if err != nil {
+		return nil, fmt.Errorf("could not describe instance %s: %w", id, err)
+	}
+	for _, reservation := range out.Reservations {
+		for _, instance := range reservation.Instances {
+			tags := map[string]string{}
+			for _, tag := range instance.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			return tags, nil
+		}
+	}
+	return nil, fmt.Errorf("instance %s %w", id, ErrNotFound)
+}
+
+func (i instances) Add(ctx context.Context, id string, tags map[string]string) error {
+	tagList := []ec2types.Tag{}
+	for key, value := range tags {
+		tagList = append(tagList, ec2types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	if _, err := i.client.CreateTags(ctx, &ec2.CreateTagsInput{Resources: []string{id}, Tags: tagList}); err != nil {
+		return fmt.Errorf("could not tag instance %s: %w", id, err)
+	}
+	return nil
+}
+
+func (i instances) Remove(ctx context.Context, id string, keys []string) error {
+	tagList := []ec2types.Tag{}
+	for _, key := range keys {
+		tagList = append(tagList, ec2types.Tag{Key: aws.String(key)})
+	}
+	if _, err := i.client.DeleteTags(ctx, &ec2.DeleteTagsInput{Resources: []string{id}, Tags: tagList}); err != nil {
+		return fmt.Errorf("could not untag instance %s: %w", id, err)
+	}
+	return nil
+}
+
+// DBInstances manages the tags of RDS instances.
+func DBInstances(client awsclient.RDSAPI) Tagger {
+	return rdsResources{client: client, kind: "DB instance", describe: describeDBInstance}
+}
+
+// DBSnapshots manages the tags of RDS snapshots.
+func DBSnapshots(client awsclient.RDSAPI) Tagger {
+	return rdsResources{client: client, kind: "DB snapshot", describe: describeDBSnapshot}
+}
+
+// rdsResources tags RDS resources by ARN, which describe looks up along with
+// the current tags.
+type rdsResources struct {
+	client   awsclient.RDSAPI
+	kind     string
+	describe func(ctx context.Context, client awsclient.RDSAPI, name string) (*string, []rdstypes.Tag, error)
+}
+
+func describeDBInstance(ctx context.Context, client awsclient.RDSAPI, name string) (*string, []rdstypes.Tag, error) {
+	out, err := client.DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{DBInstanceIdentifier: aws.String(name)})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, instance := range out.DBInstances {
+		return instance.DBInstanceArn, instance.TagList, nil
+	}
+	return nil, nil, ErrNotFound
+}
+
+func describeDBSnapshot(ctx context.Context, client awsclient.RDSAPI, name string) (*string, []rdstypes.Tag, error) {
+	out, err := client.DescribeDBSnapshots(ctx, &rds.DescribeDBSnapshotsInput{DBSnapshotIdentifier: aws.String(name)})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, snapshot := range out.DBSnapshots {
+		return snapshot.DBSnapshotArn, snapshot.TagList, nil
+	}
+	return nil, nil, ErrNotFound
+}
+
+func (r rdsResources) arn(ctx context.Context, name string) (*string, []rdstypes.Tag, error) {
+	arn, tagList, err := r.describe(ctx, r.client, name)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil, fmt.Errorf("%s %s %w", r.kind, name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not describe %s %s: %w", r.kind, name, err)
+	}
+	return arn, tagList, nil
+}
+
+func (r rdsResources) Get(ctx context.Context, name string) (map[string]string, error) {
+	_, tagList, err := r.arn(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	for _, tag := range tagList {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+func (r rdsResources) Add(ctx context.Context, name string, tags map[string]string) error {
+	arn, _, err := r.arn(ctx, name)
+	if err != nil {
+		return err
+	}
+	tagList := []rdstypes.Tag{}
+	for key, value := range tags {
+		tagList = append(tagList, rdstypes.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	if _, err := r.client.AddTagsToResource(ctx, &rds.AddTagsToResourceInput{ResourceName: arn, Tags: tagList}); err != nil {
+		return fmt.Errorf("could not tag %s %s: %w", r.kind, name, err)
+	}
+	return nil
+}
+
+func (r rdsResources) Remove(ctx context.Context, name string, keys []string) error {
+	arn, _, err := r.arn(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err := r.client.RemoveTagsFromResource(ctx, &rds.RemoveTagsFromResourceInput{ResourceName: arn, TagKeys: keys}); err != nil {
+		return fmt.Errorf("could not untag %s %s: %w", r.kind, name, err)
+	}
+	return nil
+}
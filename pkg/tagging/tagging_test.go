@@ -0,0 +1,46 @@
+package tagging
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		current map[string]string
+		desired map[string]string
+		want    map[string]string
+	}{
+		{
+			name:    "add and update",
+			current: map[string]string{"Team": "old"},
+			desired: map[string]string{"Team": "web", "Env": "prod"},
+			want:    map[string]string{"Team": "web", "Env": "prod"},
+		},
+		{
+			name:    "foreign tags are kept",
+			current: map[string]string{"Team": "web", "CostCenter": "42", "aws:backup": "daily"},
+			desired: map[string]string{"Team": "web"},
+			want:    map[string]string{},
+		},
+		{
+			name:    "no current tags",
+			desired: map[string]string{"Team": "web"},
+			want:    map[string]string{"Team": "web"},
+		},
+		{
+			name:    "empty value differs from a missing key",
+			current: map[string]string{},
+			desired: map[string]string{"Owner": ""},
+			want:    map[string]string{"Owner": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.current, tt.desired); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// Package ui is a full-screen terminal interface to browse resources: a tab per
+// service, the resources of the selected view and the details of the selected
+// resource. Actions run CLI commands, with their usual confirmation prompts,
+// while the interface is suspended.
+package ui
+
+import (
+	"context"
+	"fmt"
+	"icp-aws-cli/pkg/output"
+	"icp-aws-cli/pkg/stdin"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// LoadTimeout bounds each list and detail call.
+const LoadTimeout = 30 * time.Second
+
+// Service is a tab of the interface.
+type Service struct {
+	Name  string
+	Views []View
+}
+
+// View is a kind of resource of a service, e.g. EC2 instances.
+type View struct {
+	Name string
+	// ID is the column identifying a resource for Detail and the actions.
+	ID   string
+	List func(ctx context.Context) (*output.Result, error)
+	// Detail optionally adds information to the listed columns.
+	Detail  func(ctx context.Context, id string) (*output.Result, error)
+	Actions []Action
+}
+
+// Action runs a command on the selected resource when Key is pressed.
+type Action struct {
+	Key  rune
+	Name string
+	// Args returns the command line, without the program name, acting on id.
+	Args func(id string) []string
+}
+
+// Runner executes a command line of the CLI.
+type Runner func(args []string) error
+
+type app struct {
+	tview    *tview.Application
+	tabs     *tview.TextView
+	table    *tview.Table
+	detail   *tview.TextView
+	footer   *tview.TextView
+	services []Service
+	run      Runner
+	header   string
+	service  int
+	view     int
+	result   *output.Result
+	// loads and details count requests so that a slow answer cannot replace a newer one.
+	loads   int
+	details int
+}
+
+// Run shows the interface until the user quits. header describes the account,
+// e.g. the profile and region.
+func Run(services []Service, header string, run Runner) error {
+	a := &app{
+		tview:    tview.NewApplication(),
+		tabs:     tview.NewTextView().SetDynamicColors(true),
+		table:    tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		detail:   tview.NewTextView().SetDynamicColors(true).SetWrap(true),
+		footer:   tview.NewTextView().SetDynamicColors(true),
+		services: services,
+		run:      run,
+		header:   header,
+	}
+	a.table.SetBorder(true)
+	a.detail.SetBorder(true).SetTitle(" Details ")
+	a.table.SetSelectionChangedFunc(func(row, _ int) {
+		a.showDetail(row)
+	})
+
+	body := tview.NewFlex().
+		AddItem(a.table, 0, 2, true).
+		AddItem(a.detail, 0, 1, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.tabs, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(a.footer, 2, 0, false)
+
+	a.tview.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			a.switchTo((a.service+1)%len(services), 0)
+			return nil
+		case tcell.KeyBacktab:
+			a.switchTo((a.service+len(services)-1)%len(services), 0)
+			return nil
+		case tcell.KeyRune:
+		default:
+			return event
+		}
+
+		key := event.Rune()
+		switch {
+		case key == 'q':
+			a.tview.Stop()
+		case key == 'r':
+			a.load()
+		case key == 'v':
+			a.switchTo(a.service, (a.view+1)%len(services[a.service].Views))
+		case key >= '1' && key <= '9' && int(key-'1') < len(services):
+			a.switchTo(int(key-'1'), 0)
+		default:
+			for _, action := range a.currentView().Actions {
+				if action.Key == key {
+					a.runAction(action)
+					return nil
+				}
+			}
+			return event
+		}
+		return nil
+	})
+
+	a.switchTo(0, 0)
+	return a.tview.SetRoot(layout, true).Run()
+}
+
+func (a *app) currentView() View {
+	return a.services[a.service].Views[a.view]
+}
+
+func (a *app) switchTo(service, view int) {
+	a.service, a.view = service, view
+
+	tabs := []string{}
+	for i, s := range a.services {
+		if i == service {
+			tabs = append(tabs, fmt.Sprintf("[black:white] %d %s [-:-]", i+1, s.Name))
+		} else {
+			tabs = append(tabs, fmt.Sprintf(" %d %s ", i+1, s.Name))
+		}
+	}
+	a.tabs.SetText(strings.Join(tabs, " ") + "  " + tview.Escape(a.header))
+	a.load()
+}
+
+// load lists the resources of the current view in the background.
+func (a *app) load() {
+	a.loads++
+	load := a.loads
+	view := a.currentView()
+	a.table.Clear().SetTitle(fmt.Sprintf(" %s %s ", a.services[a.service].Name, view.Name))
+	a.details++
+	a.detail.Clear()
+	a.setStatus("Loading...")
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), LoadTimeout)
+		defer cancel()
+		result, err := view.List(ctx)
+
+		a.tview.QueueUpdateDraw(func() {
+			if load != a.loads {
+				return
+			}
+			if err != nil {
+				a.result = nil
+				a.setStatus("[red]" + tview.Escape(err.Error()))
+				return
+			}
+			a.result = result
+			a.fillTable(result)
+			a.setStatus(fmt.Sprintf("%d %s", len(result.Records), strings.ToLower(view.Name)))
+			a.showDetail(1)
+		})
+	}()
+}
+
+func (a *app) fillTable(result *output.Result) {
+	a.table.Clear()
+	for col, column := range result.Columns {
+		a.table.SetCell(0, col, tview.NewTableCell(column).SetSelectable(false).SetAttributes(tcell.AttrBold))
+	}
+	for row, record := range result.Records {
+		for col, column := range result.Columns {
+			a.table.SetCell(row+1, col, tview.NewTableCell(tview.Escape(output.FormatValue(record[column]))))
+		}
+	}
+	a.table.Select(1, 0).ScrollToBeginning()
+}
+
+// selected returns the record on row of the table.
+func (a *app) selected(row int) (output.Record, bool) {
+	if a.result == nil || row < 1 || row > len(a.result.Records) {
+		return nil, false
+	}
+	return a.result.Records[row-1], true
+}
+
+func (a *app) showDetail(row int) {
+	a.details++
+	a.detail.Clear()
+	record, ok := a.selected(row)
+	if !ok {
+		return
+	}
+	view := a.currentView()
+	a.detail.SetText(formatRecord(a.result.Columns, record))
+
+	if view.Detail == nil {
+		return
+	}
+	id := output.FormatValue(record[view.ID])
+	details := a.details
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), LoadTimeout)
+		defer cancel()
+		detail, err := view.Detail(ctx, id)
+
+		a.tview.QueueUpdateDraw(func() {
+			if details != a.details {
+				return
+			}
+			if err != nil {
+				fmt.Fprintf(a.detail, "\n[red]%s[-]\n", tview.Escape(err.Error()))
+				return
+			}
+			for _, record := range detail.Records {
+				fmt.Fprintf(a.detail, "\n%s", formatRecord(detail.Columns, record))
+			}
+		})
+	}()
+}
+
+func (a *app) selectedRow() int {
+	row, _ := a.table.GetSelection()
+	return row
+}
+
+// runAction suspends the interface to run the command of action, so that its
+// output and confirmation prompt use the terminal, then reloads the view.
+func (a *app) runAction(action Action) {
+	record, ok := a.selected(a.selectedRow())
+	if !ok {
+		return
+	}
+	args := action.Args(output.FormatValue(record[a.currentView().ID]))
+
+	a.tview.Suspend(func() {
+		fmt.Printf("\n$ icp-aws-cli %s\n", strings.Join(args, " "))
+		// The command prints its own errors
+		_ = a.run(args)
+		fmt.Print("\nPress Enter to return to the interface...")
+		_, _ = stdin.ReadLine(context.Background())
+	})
+	a.load()
+}
+
+// setStatus shows status above the keys of the current view.
+func (a *app) setStatus(status string) {
+	keys := []string{"Tab/1-9 service", "v next view", "r refresh", "q quit"}
+	for _, action := range a.currentView().Actions {
+		keys = append(keys, fmt.Sprintf("%c %s", action.Key, action.Name))
+	}
+	a.footer.SetText(status + "\n[::d]" + strings.Join(keys, "  ") + "[::-]")
+}
+
+func formatRecord(columns []string, record output.Record) string {
+	var text strings.Builder
+	for _, column := range columns {
+		fmt.Fprintf(&text, "[yellow]%s[-]: %s\n", tview.Escape(column), tview.Escape(output.FormatValue(record[column])))
+	}
+	return text.String()
+}